@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/ashishb/brux/src/brux/internal/brucontract"
+)
+
+var _coverageSpecPath *string
+
+var _coverageCmd = &cobra.Command{
+	Use:   "coverage --openapi <spec> <collection dir>",
+	Short: "Report which operations of an OpenAPI spec a collection covers",
+	Long: `Map the Bru files of a collection to the operations of an OpenAPI 3 or Swagger 2 spec by method and path, and
+print which operations are covered, by which files, and the files matching no operation.
+URLs may start with a variable like {{baseUrl}}, and path params like :id match any path param of the spec.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		spec, err := brucontract.LoadSpec(*_coverageSpecPath)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("filePath", *_coverageSpecPath).
+				Msg("Error loading the spec")
+			if errors.Is(err, brucontract.ErrInvalidSpec) {
+				os.Exit(ExitCodeParseError)
+			}
+			os.Exit(ExitCodeError)
+		}
+		coverage, err := brucontract.GetCoverage(spec, args[0])
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error mapping the collection to the spec")
+			os.Exit(ExitCodeError)
+		}
+		if err := brucontract.WriteCoverageReport(os.Stdout, coverage); err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error writing the coverage report")
+			os.Exit(ExitCodeError)
+		}
+	},
+}
+
+func init() {
+	_coverageSpecPath = _coverageCmd.Flags().String("openapi", "", "OpenAPI 3 or Swagger 2 spec in YAML or JSON")
+	_ = _coverageCmd.MarkFlagRequired("openapi")
+	RootCmd.AddCommand(_coverageCmd)
+}
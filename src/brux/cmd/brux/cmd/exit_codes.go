@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+// Exit codes of the "run" command, documented in the README
+const (
+	ExitCodeSuccess           = 0
+	ExitCodeError             = 1
+	ExitCodeParseError        = 2
+	ExitCodeTransportError    = 3
+	ExitCodeUnexpectedStatus  = 4
+	ExitCodeAssertionsFailure = 5
+	// Conventional exit code for SIGINT
+	ExitCodeCancelled = 130
+)
+
+// getExitCode maps the error of a run to the exit code of the process
+func getExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitCodeSuccess
+	case errors.Is(err, context.Canceled):
+		return ExitCodeCancelled
+	case errors.Is(err, brurunner.ErrParse):
+		return ExitCodeParseError
+	case errors.Is(err, brurunner.ErrTransport):
+		return ExitCodeTransportError
+	case errors.Is(err, brurunner.ErrUnexpectedStatus):
+		return ExitCodeUnexpectedStatus
+	case errors.Is(err, brurunner.ErrAssertionFailed):
+		return ExitCodeAssertionsFailure
+	default:
+		return ExitCodeError
+	}
+}
+
+// getResultsExitCode returns the exit code of the first failed result, in the order of the requests
+func getResultsExitCode(results []*brurunner.Result) int {
+	for _, result := range results {
+		if result.Err != nil {
+			return getExitCode(result.Err)
+		}
+	}
+	return ExitCodeSuccess
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+func TestGetExitCode(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		err      error
+		expected int
+	}{
+		{nil, ExitCodeSuccess},
+		{errors.New("file not found"), ExitCodeError},
+		{fmt.Errorf("%w: invalid section", brurunner.ErrParse), ExitCodeParseError},
+		{fmt.Errorf("%w: connection refused", brurunner.ErrTransport), ExitCodeTransportError},
+		{fmt.Errorf("%w: 404 Not Found", brurunner.ErrUnexpectedStatus), ExitCodeUnexpectedStatus},
+		{fmt.Errorf("%w: 1 of 2 assertions failed", brurunner.ErrAssertionFailed), ExitCodeAssertionsFailure},
+		{fmt.Errorf("%w: %w", brurunner.ErrTransport, context.Canceled), ExitCodeCancelled},
+		{brurunner.ErrSnapshotMismatch, ExitCodeError},
+	}
+	for _, tt := range testCases {
+		require.Equal(t, tt.expected, getExitCode(tt.err), fmt.Sprint(tt.err))
+	}
+}
+
+func TestGetResultsExitCode(t *testing.T) {
+	t.Parallel()
+	passed := &brurunner.Result{FilePath: "a.bru"}
+	transportErr := &brurunner.Result{FilePath: "b.bru", Err: fmt.Errorf("%w: timeout", brurunner.ErrTransport)}
+	assertionErr := &brurunner.Result{FilePath: "c.bru", Err: fmt.Errorf("%w: 1 of 1 assertions failed", brurunner.ErrAssertionFailed)}
+	cancelled := &brurunner.Result{FilePath: "d.bru", Err: context.Canceled}
+
+	testCases := map[string]struct {
+		results  []*brurunner.Result
+		expected int
+	}{
+		"no results":                 {nil, ExitCodeSuccess},
+		"all passed":                 {[]*brurunner.Result{passed, passed}, ExitCodeSuccess},
+		"one failed":                 {[]*brurunner.Result{passed, assertionErr}, ExitCodeAssertionsFailure},
+		"first failure wins":         {[]*brurunner.Result{passed, transportErr, assertionErr}, ExitCodeTransportError},
+		"first failure wins reverse": {[]*brurunner.Result{assertionErr, transportErr}, ExitCodeAssertionsFailure},
+		"cancelled":                  {[]*brurunner.Result{passed, cancelled, transportErr}, ExitCodeCancelled},
+	}
+	for name, tt := range testCases {
+		require.Equal(t, tt.expected, getResultsExitCode(tt.results), name)
+	}
+}
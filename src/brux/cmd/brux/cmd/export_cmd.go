@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/ashishb/brux/src/brux/internal/bruexporter"
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+var (
+	_exportEnvName       = new(string)
+	_exportShowSecrets   = new(bool)
+	_exportResponsesPath *string
+)
+
+var _exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export requests to other formats",
+	Long:  `Export requests, with the variables replaced, as commands or code of other tools, or whole collections as specs and collections of other tools`,
+}
+
+var _exportOpenAPICmd = &cobra.Command{
+	Use:   "openapi <collection dir>",
+	Short: "Export a collection as an OpenAPI spec skeleton",
+	Long: `Export a collection as an OpenAPI 3 spec skeleton in YAML, inferring the paths, methods, parameters and example
+bodies from the Bru files and the servers from the environments.
+The response schemas are inferred from the saved responses of a JSON report of 'brux run --report' if passed via --responses.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var results []*brurunner.Result
+		if *_exportResponsesPath != "" {
+			f, err := os.Open(*_exportResponsesPath)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Msg("Error opening the report")
+				os.Exit(ExitCodeError)
+			}
+
+			defer f.Close()
+			if results, err = brurunner.ReadReport(f); err != nil {
+				log.Error().
+					Err(err).
+					Str("filePath", *_exportResponsesPath).
+					Msg("Error reading the report")
+				os.Exit(ExitCodeParseError)
+			}
+		}
+		if err := bruexporter.ExportOpenAPI(os.Stdout, args[0], results); err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error exporting the collection")
+			os.Exit(ExitCodeError)
+		}
+	},
+}
+
+var _exportPostmanCmd = &cobra.Command{
+	Use:   "postman <collection dir>",
+	Short: "Export a collection as a Postman collection",
+	Long: `Export a collection as a Postman v2.1 collection in JSON, keeping the variables as they are.
+The scripts and tests are exported with the Bruno APIs like bru.setVar rewritten to their Postman equivalents.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := bruexporter.ExportPostman(os.Stdout, args[0]); err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error exporting the collection")
+			os.Exit(ExitCodeError)
+		}
+	},
+}
+
+func newExportSnippetCmd(format bruexporter.Format) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   string(format) + " <bruFilePath>",
+		Short: "Export a request as " + string(format),
+		Long: `Export a request as ` + string(format) + `, with the variables replaced.
+Secret variables, i.e. the ones in 'vars:secret' of the environment, the '.env' file and the process environment,
+and headers like Authorization are redacted unless --show-secrets is passed.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := brurunner.NewConfig(args[0], false, "", *_exportEnvName, false, nil,
+				brurunner.WithRedactedSecrets(!*_exportShowSecrets))
+			if err != nil {
+				log.Error().
+					Err(err).
+					Msg("Error creating config")
+				os.Exit(ExitCodeError)
+			}
+
+			request, err := brurunner.Resolve(*cfg)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("filePath", args[0]).
+					Msg("Error resolving the request")
+				os.Exit(getExitCode(err))
+			}
+			if err := bruexporter.ExportSnippet(os.Stdout, format, *request, *_exportShowSecrets); err != nil {
+				log.Error().
+					Err(err).
+					Msg("Error exporting the request")
+				os.Exit(ExitCodeError)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(_exportEnvName, "env", "e", "", "Environment name (name of the sub-dir under the 'environments' directory)")
+	cmd.Flags().BoolVar(_exportShowSecrets, "show-secrets", false, "Don't redact secret variables and headers like Authorization")
+	return cmd
+}
+
+func init() {
+	for _, format := range bruexporter.SnippetFormats {
+		_exportCmd.AddCommand(newExportSnippetCmd(format))
+	}
+	_exportResponsesPath = _exportOpenAPICmd.Flags().String("responses", "", "JSON report of 'brux run --report' to infer the responses from")
+	_exportCmd.AddCommand(_exportOpenAPICmd)
+	_exportCmd.AddCommand(_exportPostmanCmd)
+	RootCmd.AddCommand(_exportCmd)
+}
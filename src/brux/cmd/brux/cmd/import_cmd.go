@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/ashishb/brux/src/brux/internal/bruimporter"
+)
+
+var (
+	_importDir                 *string
+	_importName                *string
+	_importOutDir              = new(string)
+	_importPostmanEnvironments *[]string
+)
+
+var _importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import requests into Bru files",
+	Long:  `Import requests from other formats into Bru files`,
+}
+
+var _importCurlCmd = &cobra.Command{
+	Use:   "curl [curl command]",
+	Short: "Import a cURL command into a Bru file",
+	Long: `Import a cURL command, e.g. from "Copy as cURL" of the browser, into a new Bru file.
+The command is passed as a single quoted argument, or read from stdin if it is not passed or is '-'.`,
+	Example: `  brux import curl 'curl -X POST https://example.com/users -d "{\"name\": \"John\"}"' --dir users
+  pbpaste | brux import curl --dir users`,
+	// The command is split as a shell would, joining the words split by the shell already would lose their quotes
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		command, err := getCurlCommand(args)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error reading the cURL command")
+			os.Exit(ExitCodeError)
+		}
+
+		document, err := bruimporter.ImportCurl(command, *_importName)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error parsing the cURL command")
+			os.Exit(ExitCodeParseError)
+		}
+		filePath, err := bruimporter.WriteRequest(*_importDir, document)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error writing the Bru file")
+			os.Exit(ExitCodeError)
+		}
+		fmt.Println(filePath)
+	},
+}
+
+var _importOpenAPICmd = &cobra.Command{
+	Use:   "openapi <spec>",
+	Short: "Import an OpenAPI 3 or Swagger 2 spec as a collection",
+	Long: `Import an OpenAPI 3 or Swagger 2 spec, in YAML or JSON, as a collection with a folder per tag, a request per
+operation and an environment per server.
+Importing the spec again adds the new operations and parameters, the changes made to the existing requests,
+e.g. to their scripts and assertions, are kept.`,
+	Example: `  brux import openapi spec.yaml --out collection`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		result, err := bruimporter.ImportOpenAPI(readImportFile(args[0]), *_importOutDir)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error importing the spec")
+			os.Exit(getImportExitCode(err))
+		}
+		printImportResult(result)
+	},
+}
+
+var _importPostmanCmd = &cobra.Command{
+	Use:   "postman <collection.json>",
+	Short: "Import a Postman v2.0 or v2.1 collection",
+	Long: `Import a Postman v2.0 or v2.1 collection, and the environments exported from Postman, as a collection.
+Folders, requests, auth, variables and the pre-request and test scripts are imported, with the pm.* API of the
+scripts rewritten to the bru.* one. The parts that could not be converted are listed in the conversion report.
+Importing the collection again adds the new requests, the changes made to the existing requests are kept.`,
+	Example: `  brux import postman users.postman_collection.json --environment dev.postman_environment.json --out collection`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data := readImportFile(args[0])
+		environments := make([][]byte, 0, len(*_importPostmanEnvironments))
+		for _, filePath := range *_importPostmanEnvironments {
+			environments = append(environments, readImportFile(filePath))
+		}
+
+		result, err := bruimporter.ImportPostman(data, environments, *_importOutDir)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error importing the collection")
+			os.Exit(getImportExitCode(err))
+		}
+		printImportResult(result)
+	},
+}
+
+var _importInsomniaCmd = &cobra.Command{
+	Use:   "insomnia <export.json>",
+	Short: "Import an Insomnia v4 export",
+	Long: `Import an Insomnia v4 export, in JSON, as a collection.
+Folders, requests, auth, environments and scripts are imported, with the variables and the insomnia.* API of the
+scripts rewritten to the Bruno ones. The parts that could not be converted, e.g. template tags, are listed in the
+conversion report.
+Importing the export again adds the new requests, the changes made to the existing requests are kept.`,
+	Example: `  brux import insomnia Insomnia_2024-01-01.json --out collection`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		result, err := bruimporter.ImportInsomnia(readImportFile(args[0]), *_importOutDir)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error importing the export")
+			os.Exit(getImportExitCode(err))
+		}
+		printImportResult(result)
+	},
+}
+
+func readImportFile(filePath string) []byte {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("filePath", filePath).
+			Msg("Error reading the file")
+		os.Exit(ExitCodeError)
+	}
+	return data
+}
+
+func getImportExitCode(err error) int {
+	if errors.Is(err, bruimporter.ErrInvalidSpec) || errors.Is(err, bruimporter.ErrInvalidCollection) {
+		return ExitCodeParseError
+	}
+	return ExitCodeError
+}
+
+func printImportResult(result *bruimporter.ImportResult) {
+	for _, filePath := range result.Created {
+		fmt.Println("created " + filePath)
+	}
+	for _, filePath := range result.Updated {
+		fmt.Println("updated " + filePath)
+	}
+	if len(result.Report) > 0 {
+		fmt.Println("\nConversion report:")
+		for _, line := range result.Report {
+			fmt.Println("  " + line)
+		}
+	}
+	log.Info().
+		Int("created", len(result.Created)).
+		Int("updated", len(result.Updated)).
+		Int("notConverted", len(result.Report)).
+		Msg("Import finished")
+}
+
+func getCurlCommand(args []string) (string, error) {
+	if len(args) > 0 && args[0] != "-" {
+		return args[0], nil
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("could not read stdin: %w", err)
+	}
+	return string(data), nil
+}
+
+func init() {
+	_importDir = _importCurlCmd.Flags().StringP("dir", "d", ".", "Folder of the collection to write the Bru file to")
+	_importName = _importCurlCmd.Flags().StringP("name", "n", "", "Name of the request (defaults to the last segment of the URL path)")
+	for _, cmd := range []*cobra.Command{_importOpenAPICmd, _importPostmanCmd, _importInsomniaCmd} {
+		cmd.Flags().StringVarP(_importOutDir, "out", "o", "", "Directory of the collection to create or update")
+		_ = cmd.MarkFlagRequired("out")
+	}
+	_importPostmanEnvironments = _importPostmanCmd.Flags().StringArray("environment", nil,
+		"Postman environment to import, can be repeated")
+	_importCmd.AddCommand(_importCurlCmd)
+	_importCmd.AddCommand(_importOpenAPICmd)
+	_importCmd.AddCommand(_importPostmanCmd)
+	_importCmd.AddCommand(_importInsomniaCmd)
+	RootCmd.AddCommand(_importCmd)
+}
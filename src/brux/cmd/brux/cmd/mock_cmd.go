@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/ashishb/brux/src/brux/internal/brumock"
+)
+
+var (
+	_mockHost    *string
+	_mockPort    *int
+	_mockOptions brumock.Options
+)
+
+var _mockCmd = &cobra.Command{
+	Use:   "mock <collection dir>",
+	Short: "Serve the responses of a collection as a mock server",
+	Long: `Serve a fake backend matching a collection, routing on the method and URL path of every Bru file.
+Path params like :id and variables like {{userId}} match any path segment.
+The response of a request comes from its 'mock', 'mock:headers' and 'mock:body' sections, or else from its snapshot
+saved by 'brux run --snapshot-dir' if --snapshot-dir is passed.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		handler, err := brumock.NewHandler(args[0], _mockOptions)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error reading the collection")
+			os.Exit(ExitCodeParseError)
+		}
+		printRoutes(handler.Routes())
+
+		server := &http.Server{
+			Addr:              net.JoinHostPort(*_mockHost, strconv.Itoa(*_mockPort)),
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		// Ctrl-C stops the server
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(shutdownCtx)
+		}()
+
+		log.Info().
+			Str("address", "http://"+server.Addr).
+			Msg("Mock server listening")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error().
+				Err(err).
+				Msg("Error serving")
+			os.Exit(ExitCodeError)
+		}
+	},
+}
+
+func printRoutes(routes []brumock.Route) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, route := range routes {
+		source := route.FilePath
+		if !route.HasResponse {
+			source += " (no response, 501)"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", route.Method, route.Path, source)
+	}
+	_ = tw.Flush()
+}
+
+func init() {
+	_mockHost = _mockCmd.Flags().String("host", "localhost", "Host to listen on")
+	_mockPort = _mockCmd.Flags().Int("port", 8080, "Port to listen on")
+	_mockCmd.Flags().StringVar(&_mockOptions.SnapshotDir, "snapshot-dir", "",
+		"Serve the snapshots saved by 'brux run --snapshot-dir' for the requests without a 'mock' section")
+	_mockCmd.Flags().DurationVar(&_mockOptions.Latency, "latency", 0,
+		"Delay of every response, overridden by 'delay' in the 'mock' section")
+	_mockCmd.Flags().Float64Var(&_mockOptions.ErrorRate, "error-rate", 0,
+		"Share of the requests between 0 and 1 that fail with --error-status")
+	_mockCmd.Flags().IntVar(&_mockOptions.ErrorStatus, "error-status", http.StatusInternalServerError,
+		"Status of the injected errors")
+	RootCmd.AddCommand(_mockCmd)
+}
@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ashishb/brux/src/brux/internal/bruprinter"
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+	"github.com/ashishb/brux/src/brux/internal/bruwatcher"
+)
+
+// watchFile runs the file, and runs it again on every change of the files it depends on until interrupted.
+// It returns the exit code of the last run.
+func watchFile(printer *bruprinter.Printer) int {
+	// Ctrl-C cancels the in-flight requests and stops watching
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var previous []*brurunner.Result
+	// Used until the config can be created, e.g. if the schema file is invalid
+	watchPaths := []string{_filePath}
+	for {
+		// The config is created on every run, so that the changes of the environment, schema, etc. are picked up
+		cfg, err := newRunConfig(printer)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error creating config")
+		} else if watchPaths, err = cfg.WatchPaths(); err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error getting watched files")
+			return ExitCodeError
+		}
+
+		// Created before the run to not miss the changes during the run
+		watcher, err := newWatcher(watchPaths, *_watchPaths)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error watching files")
+			return ExitCodeError
+		}
+		if cfg != nil {
+			previous = runAndDiff(ctx, printer, *cfg, previous)
+		}
+
+		log.Info().
+			Strs("paths", slices.Concat(watchPaths, *_watchPaths)).
+			Msg("Watching for changes, press Ctrl-C to stop")
+		changedPaths, err := watcher.Wait(ctx)
+		_ = watcher.Close()
+		if err != nil {
+			return getResultsExitCode(previous)
+		}
+		log.Info().
+			Strs("changedPaths", changedPaths).
+			Msg("Running again")
+	}
+}
+
+// runAndDiff runs the file and prints how the responses changed since the previous results
+func runAndDiff(ctx context.Context, printer *bruprinter.Printer, cfg brurunner.Config, previous []*brurunner.Result) []*brurunner.Result {
+	results := brurunner.RunIterations(ctx, cfg)
+	if err := reportResults(results); err != nil {
+		log.Error().
+			Err(err).
+			Msg("Error writing results")
+	}
+
+	diffs, err := cfg.DiffResponses(previous, results)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Msg("Error comparing responses")
+		return results
+	}
+	for _, diff := range diffs {
+		title := filepath.Base(diff.FilePath)
+		if diff.Iteration > 0 {
+			title += fmt.Sprintf(" (iteration %d)", diff.Iteration)
+		}
+		if err := printer.PrintChange(title+" changed since the previous run", diff.Diff); err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error printing diff")
+		}
+	}
+	if previous != nil && len(diffs) == 0 {
+		log.Info().
+			Msg("No response changed since the previous run")
+	}
+	return results
+}
+
+// newWatcher watches the Bru files, and all the files of the additional paths
+func newWatcher(paths []string, additionalPaths []string) (*bruwatcher.Watcher, error) {
+	watcher, err := bruwatcher.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if err := watcher.Add(path, bruwatcher.BruFiles); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+	for _, path := range additionalPaths {
+		if err := watcher.Add(path, bruwatcher.AllFiles); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+	return watcher, nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+	"github.com/ashishb/brux/src/brux/internal/brutui"
+)
+
+var (
+	_tuiEnvName *string
+	_tuiTimeout *time.Duration
+	_tuiLogFile *string
+)
+
+var _tuiCmd = &cobra.Command{
+	Use:   "tui [collection dir]",
+	Short: "Browse and run the requests of a collection in a terminal UI",
+	Long: `Browse the folders and requests of a collection, sorted by seq, pick an environment, see the resolved URL and
+headers of a request, run it and explore the response with foldable JSON, search and the history of past runs.
+The UI is driven by the keyboard only, so it works over SSH. The collection dir defaults to the current directory.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		// The logs would corrupt the UI
+		logOutput := io.Discard
+		if *_tuiLogFile != "" {
+			f, err := os.OpenFile(*_tuiLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+			if err != nil {
+				log.Error().
+					Err(err).
+					Str("logFile", *_tuiLogFile).
+					Msg("Error opening log file")
+				os.Exit(ExitCodeError)
+			}
+			defer f.Close()
+			logOutput = f
+		}
+		defaultLogger := log.Logger
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: logOutput, NoColor: true})
+
+		// Ctrl-C is handled by the UI, this only stops it on SIGINT sent by another process
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		err := brutui.Run(ctx, dir, *_tuiEnvName, brurunner.WithTimeout(*_tuiTimeout))
+		log.Logger = defaultLogger
+		if err != nil {
+			log.Error().
+				Err(err).
+				Msg("Error running terminal UI")
+			os.Exit(ExitCodeError)
+		}
+	},
+}
+
+func init() {
+	_tuiEnvName = _tuiCmd.Flags().StringP("env", "e", "", "Environment selected on start, another one can be picked in the UI")
+	_tuiTimeout = _tuiCmd.Flags().Duration("timeout", 0, "Timeout of each request, overridden by 'timeout' in the 'settings' section (default 5m0s)")
+	_tuiLogFile = _tuiCmd.Flags().String("log-file", "", "File to write the logs to, they are discarded by default")
+	RootCmd.AddCommand(_tuiCmd)
+}
@@ -0,0 +1,180 @@
+package brucontract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
+	"github.com/ashishb/brux/src/brux/internal/openapi"
+)
+
+// Operator of the assertion results of the contract checks, e.g. "res.status: conformsTo GET /users/{id}"
+const _contractOperator = "conformsTo"
+
+// Check checks that a response conforms to the operation of the request: its status is documented, its headers
+// include the required ones and its JSON body matches the schema of the response.
+// It returns an assertion result per violation, or a single passed result if the response conforms.
+func (s *Spec) Check(method string, rawURL string, statusCode int, headers http.Header, body []byte) []bruexpr.AssertionResult {
+	urlPath := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		urlPath = u.Path
+	}
+	operation := s.FindOperation(method, urlPath)
+	if operation == nil {
+		return []bruexpr.AssertionResult{{
+			Expression: "req",
+			Operator:   _contractOperator,
+			Expected:   filepath.Base(s.filePath),
+			Err:        fmt.Errorf("no operation matches %s %s", strings.ToUpper(method), urlPath),
+		}}
+	}
+
+	newResult := func(expression string, err error) bruexpr.AssertionResult {
+		return bruexpr.AssertionResult{
+			Expression: expression,
+			Operator:   _contractOperator,
+			Expected:   operation.String(),
+			Passed:     err == nil,
+			Err:        err,
+		}
+	}
+	response, pointer := s.response(operation, statusCode)
+	if response == nil {
+		return []bruexpr.AssertionResult{newResult("res.status", fmt.Errorf("status %d is not documented", statusCode))}
+	}
+
+	results := make([]bruexpr.AssertionResult, 0)
+	for _, name := range s.requiredHeaders(response) {
+		if headers.Get(name) == "" {
+			results = append(results, newResult("res.headers."+strings.ToLower(name), errors.New("required header is missing")))
+		}
+	}
+
+	schemaPointer, err := s.bodySchema(response, pointer, headers.Get("Content-Type"))
+	if err != nil {
+		results = append(results, newResult("res.headers.content-type", err))
+	} else if schemaPointer != "" {
+		for _, violation := range s.validate(schemaPointer, body) {
+			results = append(results, newResult("res.body", violation))
+		}
+	}
+
+	if len(results) == 0 {
+		results = append(results, newResult("res", nil))
+	}
+	return results
+}
+
+// response returns the response of the operation documented for the status code, falling back to a range like
+// "2XX" and then to "default", and its JSON pointer
+func (s *Spec) response(operation *Operation, statusCode int) (map[string]any, string) {
+	responses, _ := s.spec.Lookup(operation.pointer + "/responses").(map[string]any)
+	status := strconv.Itoa(statusCode)
+	for _, key := range []string{status, status[:1] + "XX", status[:1] + "xx", "default"} {
+		if value, ok := responses[key]; ok {
+			response, refPointer := s.spec.Resolve(value)
+			if refPointer == "" {
+				refPointer = operation.pointer + "/responses/" + key
+			}
+			return response, refPointer
+		}
+	}
+	return nil, ""
+}
+
+// requiredHeaders returns the names of the required headers of the response, which are optional in Swagger 2.
+// Content-Type is ignored as per the spec, it is described by the content of the response.
+func (s *Spec) requiredHeaders(response map[string]any) []string {
+	names := make([]string, 0)
+	headers, _ := response["headers"].(map[string]any)
+	for name, value := range headers {
+		header, _ := s.spec.Resolve(value)
+		if required, _ := header["required"].(bool); required && !strings.EqualFold(name, "Content-Type") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// bodySchema returns the JSON pointer of the schema of the body for the media type, empty if the body is not
+// described or is not JSON, or an error if the media type is not documented
+func (s *Spec) bodySchema(response map[string]any, pointer string, contentType string) (string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	isJSON := mediaType == "" || strings.HasSuffix(mediaType, "json")
+	if s.spec.IsSwagger2() {
+		if _, ok := response["schema"]; ok && isJSON {
+			return pointer + "/schema", nil
+		}
+		return "", nil
+	}
+
+	content, ok := response["content"].(map[string]any)
+	if !ok || len(content) == 0 {
+		return "", nil
+	}
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	for _, key := range []string{mediaType, mainType + "/*", "*/*"} {
+		mediaTypeObject, ok := content[key].(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, ok := mediaTypeObject["schema"]; !ok || !isJSON {
+			return "", nil
+		}
+		return pointer + "/content/" + openapi.EscapePointer(key) + "/schema", nil
+	}
+	return "", fmt.Errorf("media type '%s' is not documented", mediaType)
+}
+
+// validate returns a violation per error of the JSON body, reported by its JSON pointer
+func (s *Spec) validate(schemaPointer string, body []byte) []error {
+	schema, err := s.schema(schemaPointer)
+	if err != nil {
+		return []error{err}
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []error{fmt.Errorf("body is not JSON: %w", err)}
+	}
+	err = schema.Validate(value)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		if err != nil {
+			return []error{err}
+		}
+		return nil
+	}
+
+	violations := make([]error, 0)
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		switch unit.Error.Kind.(type) {
+		case *kind.Group, *kind.Reference, *kind.Schema:
+			// Only say that their causes failed, e.g. the ones of "$ref"
+			continue
+		}
+		pointer := unit.InstanceLocation
+		if pointer == "" {
+			pointer = "/"
+		}
+		violations = append(violations, fmt.Errorf("at '%s': %s", pointer, unit.Error))
+	}
+	if len(violations) == 0 {
+		violations = append(violations, validationErr)
+	}
+	return violations
+}
@@ -0,0 +1,81 @@
+package brucontract
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_Check(t *testing.T) {
+	t.Parallel()
+	spec, err := LoadSpec(writeTestSpec(t))
+	require.NoError(t, err)
+	jsonHeaders := http.Header{"Content-Type": {"application/json"}, "X-Rate-Limit": {"100"}}
+	testCases := []struct {
+		name       string
+		method     string
+		url        string
+		statusCode int
+		headers    http.Header
+		body       string
+		expected   []string
+	}{
+		{
+			name: "conforms", method: "GET", url: "https://api.example.com/v1/users/1", statusCode: http.StatusOK,
+			headers: jsonHeaders, body: `{"id": 1, "name": "Ada", "manager": null}`,
+			expected: []string{"res: conformsTo GET /users/{id}"},
+		},
+		{
+			name: "invalid body", method: "GET", url: "https://api.example.com/v1/users", statusCode: http.StatusOK,
+			headers: jsonHeaders, body: `[{"id": "1"}]`,
+			expected: []string{
+				"res.body: conformsTo GET /users (at '/0': missing property 'name')",
+				"res.body: conformsTo GET /users (at '/0/id': got string, want integer)",
+			},
+		},
+		{
+			name: "missing header", method: "GET", url: "https://api.example.com/v1/users/1", statusCode: http.StatusOK,
+			headers: http.Header{"Content-Type": {"application/json"}}, body: `{"id": 1, "name": "Ada"}`,
+			expected: []string{"res.headers.x-rate-limit: conformsTo GET /users/{id} (required header is missing)"},
+		},
+		{
+			name: "referenced response", method: "GET", url: "https://api.example.com/v1/users/2", statusCode: http.StatusNotFound,
+			headers: http.Header{"Content-Type": {"application/problem+json"}}, body: `{}`,
+			expected: []string{"res.body: conformsTo GET /users/{id} (at '/': missing property 'title')"},
+		},
+		{
+			name: "undocumented status", method: "GET", url: "https://api.example.com/v1/users/1", statusCode: http.StatusInternalServerError,
+			expected: []string{"res.status: conformsTo GET /users/{id} (status 500 is not documented)"},
+		},
+		{
+			name: "undocumented media type", method: "GET", url: "https://api.example.com/v1/users/me", statusCode: http.StatusOK,
+			headers: http.Header{"Content-Type": {"text/html"}}, body: `<html></html>`,
+			expected: []string{"res.headers.content-type: conformsTo GET /users/me (media type 'text/html' is not documented)"},
+		},
+		{
+			name: "status range", method: "DELETE", url: "https://api.example.com/v1/users/1", statusCode: http.StatusNoContent,
+			expected: []string{"res: conformsTo DELETE /users/{id}"},
+		},
+		{
+			name: "unknown operation", method: "POST", url: "https://api.example.com/v1/users", statusCode: http.StatusCreated,
+			expected: []string{"req: conformsTo openapi.yaml (no operation matches POST /v1/users)"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			headers := testCase.headers
+			if headers == nil {
+				headers = http.Header{}
+			}
+			results := spec.Check(testCase.method, testCase.url, testCase.statusCode, headers, []byte(testCase.body))
+			actual := make([]string, 0, len(results))
+			for _, result := range results {
+				actual = append(actual, result.String())
+				require.Equal(t, result.Err == nil, result.Passed)
+			}
+			require.ElementsMatch(t, testCase.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package brucontract
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+// Coverage maps the requests of a collection to the operations of a spec
+type Coverage struct {
+	// Operations of the spec in order, with the requests mapped to them
+	Operations []OperationCoverage
+	// Requests that match no operation of the spec
+	UnmatchedRequests []Request
+}
+
+type OperationCoverage struct {
+	Operation *Operation
+	// Requests mapped to the operation, empty if it is not covered
+	Requests []Request
+}
+
+// Request is a request of a collection
+type Request struct {
+	// Path relative to the collection, e.g. "users/get-user.bru"
+	FilePath string
+	Method   string
+	// URL as in the Bru file, e.g. "{{baseUrl}}/users/:id"
+	URL string
+}
+
+// Covered returns the number of operations with at least one request
+func (c Coverage) Covered() int {
+	covered := 0
+	for _, operation := range c.Operations {
+		if len(operation.Requests) > 0 {
+			covered++
+		}
+	}
+	return covered
+}
+
+// GetCoverage maps the requests in the collection directory to the operations of the spec by method and path.
+// The URLs of the requests may start with a variable like "{{baseUrl}}", and path params like ":id" and variables
+// like "{{id}}" match any path param.
+func GetCoverage(spec *Spec, dir string) (*Coverage, error) {
+	requests, err := readRequests(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	coverage := &Coverage{}
+	indexes := make(map[*Operation]int, len(spec.operations))
+	for i, operation := range spec.operations {
+		coverage.Operations = append(coverage.Operations, OperationCoverage{Operation: operation})
+		indexes[operation] = i
+	}
+	for _, request := range requests {
+		operation := spec.FindOperation(request.Method, bruparser.RequestPath(request.URL))
+		if operation == nil {
+			coverage.UnmatchedRequests = append(coverage.UnmatchedRequests, request)
+			continue
+		}
+		operationCoverage := &coverage.Operations[indexes[operation]]
+		operationCoverage.Requests = append(operationCoverage.Requests, request)
+	}
+	return coverage, nil
+}
+
+// WriteCoverageReport writes the operations with the requests covering them, the requests matching no operation and
+// the share of covered operations
+func WriteCoverageReport(w io.Writer, coverage *Coverage) error {
+	var table bytes.Buffer
+	tw := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	for _, operation := range coverage.Operations {
+		filePaths := make([]string, 0, len(operation.Requests))
+		for _, request := range operation.Requests {
+			filePaths = append(filePaths, request.FilePath)
+		}
+		mark := "✗"
+		if len(filePaths) > 0 {
+			mark = "✓"
+		}
+		_, _ = fmt.Fprintf(tw, "%s %s\t%s\t%s\n", mark, operation.Operation.Method, operation.Operation.Path,
+			strings.Join(filePaths, ", "))
+	}
+	_ = tw.Flush()
+	var buf bytes.Buffer
+	for _, line := range strings.SplitAfter(table.String(), "\n") {
+		// Padding of the operations without requests
+		buf.WriteString(strings.TrimRight(line, " \n"))
+		if strings.HasSuffix(line, "\n") {
+			buf.WriteString("\n")
+		}
+	}
+
+	if len(coverage.UnmatchedRequests) > 0 {
+		buf.WriteString("\nRequests matching no operation:\n")
+		for _, request := range coverage.UnmatchedRequests {
+			_, _ = fmt.Fprintf(&buf, "  %s %s (%s)\n", request.Method, request.URL, request.FilePath)
+		}
+	}
+	percentage := 100.0
+	if len(coverage.Operations) > 0 {
+		percentage = float64(coverage.Covered()) * 100 / float64(len(coverage.Operations))
+	}
+	_, _ = fmt.Fprintf(&buf, "\nCovered %d of %d operations (%.0f%%)\n", coverage.Covered(), len(coverage.Operations), percentage)
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("could not write coverage report: %w", err)
+	}
+	return nil
+}
+
+// readRequests returns the requests of the Bru files in the collection directory, sorted by path
+func readRequests(dir string) ([]Request, error) {
+	requests := make([]Request, 0)
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if filePath != dir && bruparser.IsIgnoredDir(name) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(name) != ".bru" || name == "folder.bru" || name == "collection.bru" {
+			return nil
+		}
+
+		f, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("could not open file: %w", err)
+		}
+		defer f.Close()
+		document, err := bruparser.ParseDocument(f)
+		if err != nil {
+			return fmt.Errorf("could not parse '%s': %w", filePath, err)
+		}
+		if section, ok := document.Method(); ok {
+			relativePath, _ := filepath.Rel(dir, filePath)
+			requests = append(requests, Request{
+				FilePath: filepath.ToSlash(relativePath),
+				Method:   strings.ToUpper(section.Name),
+				URL:      section.Value("url"),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read collection '%s': %w", dir, err)
+	}
+	return requests, nil
+}
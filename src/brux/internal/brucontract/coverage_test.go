@@ -0,0 +1,48 @@
+package brucontract
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestRequest(t *testing.T, dir string, name string, method string, url string) {
+	t.Helper()
+	filePath := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o750))
+	content := "meta {\n  name: " + name + "\n  type: http\n}\n\n" + method + " {\n  url: " + url + "\n  body: none\n}\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+}
+
+func TestGetCoverage(t *testing.T) {
+	t.Parallel()
+	spec, err := LoadSpec(writeTestSpec(t))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	writeTestRequest(t, dir, "users/list.bru", "get", "{{baseUrl}}/users?limit=10")
+	writeTestRequest(t, dir, "users/get.bru", "get", "{{baseUrl}}/v1/users/:id")
+	writeTestRequest(t, dir, "users/get-by-var.bru", "get", "https://api.example.com/v1/users/{{userId}}")
+	writeTestRequest(t, dir, "users/create.bru", "post", "{{baseUrl}}/users")
+	writeTestRequest(t, dir, "users/folder.bru", "get", "{{baseUrl}}/ignored")
+	writeTestRequest(t, dir, "environments/local.bru", "get", "{{baseUrl}}/ignored")
+
+	coverage, err := GetCoverage(spec, dir)
+	require.NoError(t, err)
+	require.Equal(t, 2, coverage.Covered())
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCoverageReport(&buf, coverage))
+	require.Equal(t, `✓ GET     /users       users/list.bru
+✗ GET     /users/me
+✓ GET     /users/{id}  users/get-by-var.bru, users/get.bru
+✗ DELETE  /users/{id}
+
+Requests matching no operation:
+  POST {{baseUrl}}/users (users/create.bru)
+
+Covered 2 of 4 operations (50%)
+`, buf.String())
+}
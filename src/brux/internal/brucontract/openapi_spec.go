@@ -0,0 +1,178 @@
+package brucontract
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/ashishb/brux/src/brux/internal/openapi"
+)
+
+var ErrInvalidSpec = openapi.ErrInvalidSpec
+
+// Path params like "{id}"
+var _pathParamRegex = regexp.MustCompile(`{[^{}/]+}`)
+
+// Spec is an OpenAPI 3 or Swagger 2 spec that requests are mapped to and responses are checked against
+type Spec struct {
+	filePath string
+	// The document of the spec has "nullable" of OpenAPI 3.0 converted to JSON Schema
+	spec       *openapi.Spec
+	operations []*Operation
+
+	// Compiles the schemas of the responses on first use, guarded by mu as responses may be checked concurrently
+	mu       sync.Mutex
+	compiler *jsonschema.Compiler
+	schemas  map[string]*jsonschema.Schema
+}
+
+// Operation is an operation of the spec like "GET /users/{id}"
+type Operation struct {
+	// Upper case, e.g. "GET"
+	Method string
+	// Path as in the spec, e.g. "/users/{id}"
+	Path        string
+	OperationID string
+	// JSON pointer of the operation in the spec, e.g. "/paths/~1users~1{id}/get"
+	pointer string
+	// Matches the paths of requests, including the paths of the servers
+	pathRegexes []*regexp.Regexp
+	// Number of path params, to prefer "/users/me" over "/users/{id}"
+	params int
+}
+
+func (o Operation) String() string {
+	return o.Method + " " + o.Path
+}
+
+// LoadSpec loads an OpenAPI 3 or Swagger 2 spec in YAML or JSON
+func LoadSpec(filePath string) (*Spec, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read spec: %w", err)
+	}
+	parsed, err := openapi.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{filePath: filePath, spec: parsed, schemas: make(map[string]*jsonschema.Schema)}
+	spec.compiler = jsonschema.NewCompiler()
+	switch {
+	case strings.HasPrefix(parsed.OpenAPI, "3.0"):
+		// OpenAPI 3.0 schemas are an extended subset of draft 4
+		spec.compiler.DefaultDraft(jsonschema.Draft4)
+		convertNullable(parsed.Doc())
+	case parsed.IsSwagger2():
+		spec.compiler.DefaultDraft(jsonschema.Draft4)
+	default:
+		spec.compiler.DefaultDraft(jsonschema.Draft2020)
+	}
+	if err := spec.compiler.AddResource(spec.resourceURL(), parsed.Doc()); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	spec.operations = spec.getOperations()
+	return spec, nil
+}
+
+// Operations returns the operations of the spec, sorted by path and method
+func (s *Spec) Operations() []*Operation {
+	return s.operations
+}
+
+// FindOperation returns the operation matching the method and path of a request, preferring paths with fewer
+// params, nil if there is none
+func (s *Spec) FindOperation(method string, urlPath string) *Operation {
+	var found *Operation
+	for _, operation := range s.operations {
+		if !strings.EqualFold(operation.Method, method) || (found != nil && found.params <= operation.params) {
+			continue
+		}
+		if slices.ContainsFunc(operation.pathRegexes, func(regex *regexp.Regexp) bool { return regex.MatchString(urlPath) }) {
+			found = operation
+		}
+	}
+	return found
+}
+
+func (s *Spec) getOperations() []*Operation {
+	basePaths := s.spec.BasePaths()
+	operations := make([]*Operation, 0)
+	for _, pathOperation := range s.spec.PathOperations() {
+		op := &Operation{
+			Method:      strings.ToUpper(pathOperation.Method),
+			Path:        pathOperation.Path,
+			OperationID: pathOperation.Operation.OperationID,
+			pointer:     pathOperation.Pointer,
+			params:      len(_pathParamRegex.FindAllString(pathOperation.Path, -1)),
+		}
+		for _, basePath := range basePaths {
+			op.pathRegexes = append(op.pathRegexes, pathRegex(strings.TrimSuffix(basePath, "/")+pathOperation.Path))
+		}
+		if !slices.Contains(basePaths, "") {
+			// e.g. requests of "{{baseUrl}}/users" with the base path in the variable
+			op.pathRegexes = append(op.pathRegexes, pathRegex(pathOperation.Path))
+		}
+		operations = append(operations, op)
+	}
+	return operations
+}
+
+// pathRegex returns a regex matching the path template, with a param matching any segment, e.g. "/users/{id}"
+// matches "/users/1" and "/users/:id"
+func pathRegex(template string) *regexp.Regexp {
+	parts := _pathParamRegex.Split(template, -1)
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, "[^/]+") + "/?$")
+}
+
+// schema returns the compiled schema at the JSON pointer
+func (s *Spec) schema(pointer string) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.schemas[pointer]; ok {
+		return schema, nil
+	}
+	schema, err := s.compiler.Compile(s.resourceURL() + "#" + url.PathEscape(pointer))
+	if err != nil {
+		return nil, fmt.Errorf("could not compile schema '%s': %w", pointer, err)
+	}
+	s.schemas[pointer] = schema
+	return schema, nil
+}
+
+func (s *Spec) resourceURL() string {
+	absFilePath, err := filepath.Abs(s.filePath)
+	if err != nil {
+		return s.filePath
+	}
+	return absFilePath
+}
+
+// convertNullable converts "nullable: true" of OpenAPI 3.0 schemas to a type including "null"
+func convertNullable(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if nullable, _ := v["nullable"].(bool); nullable {
+			if schemaType, ok := v["type"].(string); ok {
+				v["type"] = []any{schemaType, "null"}
+			}
+		}
+		for _, child := range v {
+			convertNullable(child)
+		}
+	case []any:
+		for _, child := range v {
+			convertNullable(child)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package brucontract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testSpec = `openapi: 3.0.3
+info:
+  title: Users API
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/User"
+  /users/{id}:
+    get:
+      operationId: getUser
+      responses:
+        "200":
+          description: OK
+          headers:
+            X-Rate-Limit:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+        "404":
+          $ref: "#/components/responses/NotFound"
+    delete:
+      operationId: deleteUser
+      responses:
+        2XX:
+          description: Deleted
+  /users/me:
+    get:
+      operationId: getMe
+      responses:
+        default:
+          description: The current user
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+components:
+  responses:
+    NotFound:
+      description: Not found
+      content:
+        application/problem+json:
+          schema:
+            type: object
+            required: [title]
+            properties:
+              title:
+                type: string
+  schemas:
+    User:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+        manager:
+          type: string
+          nullable: true
+`
+
+func writeTestSpec(t *testing.T) string {
+	t.Helper()
+	filePath := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte(_testSpec), 0o600))
+	return filePath
+}
+
+func TestSpec_FindOperation(t *testing.T) {
+	t.Parallel()
+	spec, err := LoadSpec(writeTestSpec(t))
+	require.NoError(t, err)
+	require.Len(t, spec.Operations(), 4)
+
+	testCases := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{method: "GET", path: "/v1/users", expected: "listUsers"},
+		{method: "GET", path: "/v1/users/", expected: "listUsers"},
+		{method: "get", path: "/v1/users/42", expected: "getUser"},
+		{method: "GET", path: "/v1/users/me", expected: "getMe"},
+		{method: "DELETE", path: "/users/:id", expected: "deleteUser"},
+		{method: "POST", path: "/v1/users"},
+		{method: "GET", path: "/v1/users/42/posts"},
+	}
+	for _, testCase := range testCases {
+		operation := spec.FindOperation(testCase.method, testCase.path)
+		if testCase.expected == "" {
+			require.Nil(t, operation, testCase.path)
+		} else {
+			require.NotNil(t, operation, testCase.path)
+			require.Equal(t, testCase.expected, operation.OperationID, testCase.path)
+		}
+	}
+}
+
+func TestLoadSpec_Invalid(t *testing.T) {
+	t.Parallel()
+	filePath := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(filePath, []byte("info:\n  title: x\n"), 0o600))
+	_, err := LoadSpec(filePath)
+	require.ErrorIs(t, err, ErrInvalidSpec)
+}
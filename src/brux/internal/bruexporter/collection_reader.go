@@ -0,0 +1,147 @@
+package bruexporter
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+const (
+	_environmentsDir    = "environments"
+	_folderFileName     = "folder.bru"
+	_collectionFileName = "collection.bru"
+	_collectionConfig   = "bruno.json"
+)
+
+// _Collection is a collection directory with its Bru files parsed, keeping the variables, disabled entries and
+// scripts, unlike brurunner
+type _Collection struct {
+	_Folder
+	// Parsed collection.bru, nil if there is none
+	document *bruparser.Document
+	// Environments by name
+	environments map[string]*bruparser.Document
+}
+
+type _Folder struct {
+	name string
+	// Path relative to the collection, e.g. "users/admin"
+	path string
+	// Parsed folder.bru, nil if there is none
+	document *bruparser.Document
+	seq      int
+	requests []_Request
+	folders  []_Folder
+}
+
+type _Request struct {
+	name string
+	// Path relative to the collection, e.g. "users/list.bru"
+	path     string
+	seq      int
+	document *bruparser.Document
+}
+
+// readCollection reads the requests, folders and environments of the collection directory
+func readCollection(dir string) (*_Collection, error) {
+	folder, err := readFolder(dir, "")
+	if err != nil {
+		return nil, err
+	}
+	collection := &_Collection{_Folder: *folder, environments: make(map[string]*bruparser.Document)}
+	if data, err := os.ReadFile(filepath.Join(dir, _collectionConfig)); err == nil {
+		var config struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(data, &config); err == nil && config.Name != "" {
+			collection.name = config.Name
+		}
+	}
+	if collection.document, err = bruparser.ReadDocumentFile(filepath.Join(dir, _collectionFileName)); err != nil {
+		return nil, err
+	}
+
+	envFilePaths, err := filepath.Glob(filepath.Join(dir, _environmentsDir, "*.bru"))
+	if err != nil {
+		return nil, fmt.Errorf("could not list environments: %w", err)
+	}
+	for _, filePath := range envFilePaths {
+		document, err := bruparser.ReadDocumentFile(filePath)
+		if err != nil {
+			return nil, err
+		}
+		collection.environments[strings.TrimSuffix(filepath.Base(filePath), ".bru")] = document
+	}
+	return collection, nil
+}
+
+func readFolder(dir string, folderPath string) (*_Folder, error) {
+	folder := &_Folder{name: filepath.Base(dir), path: folderPath}
+	var err error
+	if folder.document, err = bruparser.ReadDocumentFile(filepath.Join(dir, _folderFileName)); err != nil {
+		return nil, err
+	}
+	if folder.document != nil {
+		meta, _ := folder.document.Section("meta")
+		folder.name = cmp.Or(meta.Value("name"), folder.name)
+		folder.seq, _ = strconv.Atoi(meta.Value("seq"))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory '%s': %w", dir, err)
+	}
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		relativePath := filepath.ToSlash(filepath.Join(folderPath, entry.Name()))
+		switch {
+		case entry.IsDir() && !bruparser.IsIgnoredDir(entry.Name()):
+			subFolder, err := readFolder(entryPath, relativePath)
+			if err != nil {
+				return nil, err
+			}
+			folder.folders = append(folder.folders, *subFolder)
+		case !entry.IsDir() && filepath.Ext(entry.Name()) == ".bru" &&
+			entry.Name() != _folderFileName && entry.Name() != _collectionFileName:
+			document, err := bruparser.ReadDocumentFile(entryPath)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := document.Method(); !ok {
+				continue
+			}
+			meta, _ := document.Section("meta")
+			seq, _ := strconv.Atoi(meta.Value("seq"))
+			folder.requests = append(folder.requests, _Request{
+				name:     cmp.Or(meta.Value("name"), strings.TrimSuffix(entry.Name(), ".bru")),
+				path:     relativePath,
+				seq:      seq,
+				document: document,
+			})
+		}
+	}
+
+	slices.SortStableFunc(folder.requests, func(a, b _Request) int {
+		return cmp.Or(cmp.Compare(a.seq, b.seq), strings.Compare(a.path, b.path))
+	})
+	slices.SortStableFunc(folder.folders, func(a, b _Folder) int {
+		return cmp.Or(cmp.Compare(a.seq, b.seq), strings.Compare(a.path, b.path))
+	})
+	return folder, nil
+}
+
+// allRequests returns the requests of the folder and its sub-folders, in order
+func (f _Folder) allRequests() []_Request {
+	requests := slices.Clone(f.requests)
+	for _, folder := range f.folders {
+		requests = append(requests, folder.allRequests()...)
+	}
+	return requests
+}
@@ -0,0 +1,130 @@
+package bruexporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var _testCollectionFiles = map[string]string{
+	"bruno.json": `{"version": "1", "name": "Users API", "type": "collection"}`,
+	"environments/local.bru": `vars {
+  baseUrl: http://localhost:8080
+}
+`,
+	"environments/prod.bru": `vars {
+  baseUrl: https://api.example.com
+}
+`,
+	"health.bru": `meta {
+  name: Health
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/health
+  body: none
+  auth: none
+}
+`,
+	"users/folder.bru": `meta {
+  name: Users
+  seq: 1
+}
+`,
+	"users/create_user.bru": `meta {
+  name: Create user
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+body:json {
+  {
+    "name": "Ada",
+    "age": 36
+  }
+}
+
+script:pre-request {
+  bru.setVar("requestId", "1");
+}
+
+docs {
+  Creates a user
+}
+`,
+	"users/get_user.bru": `meta {
+  name: Get user
+  type: http
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/users/:id?verbose=true
+  body: none
+  auth: bearer
+}
+
+params:query {
+  verbose: true
+  ~page: 1
+}
+
+params:path {
+  id: 42
+}
+
+headers {
+  Accept: application/json
+  X-Request-Id: {{requestId}}
+}
+
+auth:bearer {
+  token: {{token}}
+}
+
+tests {
+  test("is ok", function() {
+    expect(res.getStatus()).to.equal(200);
+  });
+}
+`,
+}
+
+// writeTestCollection writes the test collection to a temporary directory and returns it
+func writeTestCollection(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range _testCollectionFiles {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestReadCollection(t *testing.T) {
+	t.Parallel()
+	collection, err := readCollection(writeTestCollection(t))
+	require.NoError(t, err)
+	require.Equal(t, "Users API", collection.name)
+	require.Len(t, collection.environments, 2)
+	require.Len(t, collection.requests, 1)
+	require.Equal(t, "health.bru", collection.requests[0].path)
+	require.Len(t, collection.folders, 1)
+	require.Equal(t, "Users", collection.folders[0].name)
+
+	requests := collection.allRequests()
+	require.Len(t, requests, 3)
+	require.Equal(t, "users/create_user.bru", requests[1].path)
+	require.Equal(t, "Get user", requests[2].name)
+}
@@ -0,0 +1,217 @@
+package bruexporter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+// _HAR is an HTTP Archive 1.2.
+// Ref: http://www.softwareishard.com/blog/har-12-spec/
+type _HAR struct {
+	Log _HARLog `json:"log"`
+}
+
+type _HARLog struct {
+	Version string      `json:"version"`
+	Creator _HARCreator `json:"creator"`
+	Entries []_HAREntry `json:"entries"`
+}
+
+type _HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type _HAREntry struct {
+	StartedDateTime string       `json:"startedDateTime"`
+	Time            float64      `json:"time"`
+	Request         _HARRequest  `json:"request"`
+	Response        _HARResponse `json:"response"`
+	Cache           struct{}     `json:"cache"`
+	Timings         _HARTimings  `json:"timings"`
+	Comment         string       `json:"comment,omitempty"`
+}
+
+type _HARRequest struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []_HARNameValue `json:"cookies"`
+	Headers     []_HARNameValue `json:"headers"`
+	QueryString []_HARNameValue `json:"queryString"`
+	PostData    *_HARPostData   `json:"postData,omitempty"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+type _HARResponse struct {
+	Status      int             `json:"status"`
+	StatusText  string          `json:"statusText"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []_HARNameValue `json:"cookies"`
+	Headers     []_HARNameValue `json:"headers"`
+	Content     _HARContent     `json:"content"`
+	RedirectURL string          `json:"redirectURL"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+type _HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type _HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type _HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	// "base64" for binary bodies
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// _HARTimings are in milliseconds, -1 for phases that did not happen
+type _HARTimings struct {
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// ExportHAR writes the requests and responses of a run as an HTTP Archive 1.2, e.g. to inspect them in the network
+// panel of a browser. Results without a response, e.g. after a transport error, are exported with a zero status.
+func ExportHAR(w io.Writer, results []*brurunner.Result) error {
+	har := _HAR{Log: _HARLog{
+		Version: "1.2",
+		Creator: _HARCreator{Name: "brux", Version: buildVersion()},
+		Entries: make([]_HAREntry, 0, len(results)),
+	}}
+	for _, result := range results {
+		har.Log.Entries = append(har.Log.Entries, harEntry(result))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(har); err != nil {
+		return fmt.Errorf("could not write HAR: %w", err)
+	}
+	return nil
+}
+
+func harEntry(result *brurunner.Result) _HAREntry {
+	entry := _HAREntry{
+		StartedDateTime: result.Timings.Start.Format(time.RFC3339Nano),
+		Time:            toMilliseconds(result.Timings.Total),
+		Request: _HARRequest{
+			Method:      result.Request.Method,
+			URL:         result.Request.URL,
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     make([]_HARNameValue, 0),
+			Headers:     harHeaders(result.Request.Headers),
+			QueryString: make([]_HARNameValue, 0),
+			HeadersSize: -1,
+			BodySize:    len(result.Request.Body),
+		},
+		Response: _HARResponse{
+			Cookies:     make([]_HARNameValue, 0),
+			Headers:     make([]_HARNameValue, 0),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: _HARTimings{
+			DNS:     harTiming(result.Timings.DNSLookup),
+			Connect: harTiming(result.Timings.TCPConnection),
+			SSL:     harTiming(result.Timings.TLSHandshake),
+			Wait:    toMilliseconds(result.Timings.TimeToFirstByte),
+			Receive: toMilliseconds(result.Timings.ContentTransfer),
+		},
+		Comment: result.FilePath,
+	}
+	if u, err := url.Parse(result.Request.URL); err == nil {
+		for key, values := range u.Query() {
+			for _, value := range values {
+				entry.Request.QueryString = append(entry.Request.QueryString, _HARNameValue{Name: key, Value: value})
+			}
+		}
+		slices.SortStableFunc(entry.Request.QueryString, compareNameValues)
+	}
+	if result.Request.Body != nil {
+		entry.Request.PostData = &_HARPostData{
+			MimeType: result.Request.Headers.Get("Content-Type"),
+			Text:     string(result.Request.Body),
+		}
+	}
+
+	if resp := result.Response; resp != nil {
+		entry.Response.Status = resp.StatusCode
+		entry.Response.StatusText = http.StatusText(resp.StatusCode)
+		entry.Response.HTTPVersion = resp.Proto
+		entry.Response.Headers = harHeaders(resp.Headers)
+		entry.Response.BodySize = len(resp.Body)
+		entry.Response.Content = _HARContent{Size: len(resp.Body), MimeType: resp.Headers.Get("Content-Type")}
+		if utf8.Valid(resp.Body) {
+			entry.Response.Content.Text = string(resp.Body)
+		} else {
+			entry.Response.Content.Text = base64.StdEncoding.EncodeToString(resp.Body)
+			entry.Response.Content.Encoding = "base64"
+		}
+		if resp.Proto != "" {
+			entry.Request.HTTPVersion = resp.Proto
+		}
+	}
+	return entry
+}
+
+// harHeaders returns the headers sorted by name
+func harHeaders(headers http.Header) []_HARNameValue {
+	nameValues := make([]_HARNameValue, 0, len(headers))
+	for name, values := range headers {
+		for _, value := range values {
+			nameValues = append(nameValues, _HARNameValue{Name: name, Value: value})
+		}
+	}
+	slices.SortStableFunc(nameValues, compareNameValues)
+	return nameValues
+}
+
+func compareNameValues(a, b _HARNameValue) int {
+	return strings.Compare(a.Name, b.Name)
+}
+
+// buildVersion returns the version of the brux module, "(devel)" if built from source
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// harTiming returns -1 for phases that did not happen, e.g. DNS lookup for a reused connection
+func harTiming(d time.Duration) float64 {
+	if d == 0 {
+		return -1
+	}
+	return toMilliseconds(d)
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
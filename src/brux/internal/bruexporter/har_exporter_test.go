@@ -0,0 +1,76 @@
+package bruexporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+func TestExportHAR(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	results := []*brurunner.Result{
+		{
+			FilePath: "users/create_user.bru",
+			Request: brurunner.RequestInfo{
+				Method:  http.MethodPost,
+				URL:     "https://example.com/users?b=2&a=1",
+				Headers: http.Header{"Content-Type": {"application/json"}, "Accept": {"*/*"}},
+				Body:    []byte(`{"name": "Ada"}`),
+			},
+			Response: &brurunner.ResponseInfo{
+				Proto:      "HTTP/2.0",
+				Status:     "201 Created",
+				StatusCode: http.StatusCreated,
+				Headers:    http.Header{"Content-Type": {"application/json"}},
+				Body:       []byte(`{"id": 1}`),
+			},
+			Timings: brurunner.Timings{
+				Start: start, Total: 120 * time.Millisecond, TimeToFirstByte: 100 * time.Millisecond,
+				ContentTransfer: 20 * time.Millisecond,
+			},
+		},
+		{
+			FilePath: "users/get_avatar.bru",
+			Request:  brurunner.RequestInfo{Method: http.MethodGet, URL: "https://example.com/avatar"},
+			Response: &brurunner.ResponseInfo{StatusCode: http.StatusOK, Headers: http.Header{}, Body: []byte{0xff, 0xd8}},
+		},
+		{
+			FilePath: "health.bru",
+			Request:  brurunner.RequestInfo{Method: http.MethodGet, URL: "https://example.com/health"},
+			Err:      errors.New("connection refused"),
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, ExportHAR(&out, results))
+	var har _HAR
+	require.NoError(t, json.Unmarshal(out.Bytes(), &har))
+	require.Equal(t, "1.2", har.Log.Version)
+	require.Len(t, har.Log.Entries, 3)
+
+	entry := har.Log.Entries[0]
+	require.Equal(t, "2024-01-02T03:04:05Z", entry.StartedDateTime)
+	require.InDelta(t, 120, entry.Time, 0.001)
+	require.Equal(t, "users/create_user.bru", entry.Comment)
+	require.Equal(t, "HTTP/2.0", entry.Request.HTTPVersion)
+	require.Equal(t, []_HARNameValue{{Name: "Accept", Value: "*/*"}, {Name: "Content-Type", Value: "application/json"}},
+		entry.Request.Headers)
+	require.Equal(t, []_HARNameValue{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, entry.Request.QueryString)
+	require.Equal(t, &_HARPostData{MimeType: "application/json", Text: `{"name": "Ada"}`}, entry.Request.PostData)
+	require.Equal(t, http.StatusCreated, entry.Response.Status)
+	require.Equal(t, "Created", entry.Response.StatusText)
+	require.Equal(t, _HARContent{Size: 9, MimeType: "application/json", Text: `{"id": 1}`}, entry.Response.Content)
+	require.Equal(t, _HARTimings{DNS: -1, Connect: -1, SSL: -1, Wait: 100, Receive: 20}, entry.Timings)
+
+	require.Equal(t, _HARContent{Size: 2, Text: "/9g=", Encoding: "base64"}, har.Log.Entries[1].Response.Content)
+	require.Nil(t, har.Log.Entries[1].Request.PostData)
+	require.Equal(t, 0, har.Log.Entries[2].Response.Status)
+}
@@ -0,0 +1,391 @@
+package bruexporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+var (
+	// URLs starting with a variable like "{{baseUrl}}", whose values are the servers
+	_serverVariableRegex = regexp.MustCompile(`^{{([^{}]+)}}`)
+	// URLs starting with a scheme and host like "https://example.com"
+	_serverRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://[^/?#]*`)
+	// Path params like ":id", which are "{id}" in OpenAPI
+	_pathParamRegex = regexp.MustCompile(`/:([^/?#]+)`)
+	// OpenAPI path params like "{id}"
+	_openAPIPathParamRegex = regexp.MustCompile(`{([^{}/]+)}`)
+)
+
+// Headers described elsewhere in OpenAPI, by the request body, the responses or the security schemes
+var _implicitHeaders = []string{"Accept", "Authorization", "Content-Type", "Cookie"}
+
+// _OpenAPISpec is an OpenAPI 3.0 spec.
+// Ref: https://spec.openapis.org/oas/v3.0.3
+type _OpenAPISpec struct {
+	OpenAPI    string                                   `yaml:"openapi"`
+	Info       _OpenAPIInfo                             `yaml:"info"`
+	Servers    []_OpenAPIServer                         `yaml:"servers,omitempty"`
+	Tags       []_OpenAPITag                            `yaml:"tags,omitempty"`
+	Paths      map[string]map[string]*_OpenAPIOperation `yaml:"paths"`
+	Components *_OpenAPIComponents                      `yaml:"components,omitempty"`
+}
+
+type _OpenAPIInfo struct {
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+type _OpenAPIServer struct {
+	URL         string                            `yaml:"url"`
+	Description string                            `yaml:"description,omitempty"`
+	Variables   map[string]_OpenAPIServerVariable `yaml:"variables,omitempty"`
+}
+
+type _OpenAPIServerVariable struct {
+	Default string `yaml:"default"`
+}
+
+type _OpenAPITag struct {
+	Name string `yaml:"name"`
+}
+
+type _OpenAPIOperation struct {
+	Tags        []string                    `yaml:"tags,omitempty"`
+	Summary     string                      `yaml:"summary,omitempty"`
+	OperationID string                      `yaml:"operationId"`
+	Description string                      `yaml:"description,omitempty"`
+	Parameters  []_OpenAPIParameter         `yaml:"parameters,omitempty"`
+	RequestBody *_OpenAPIRequestBody        `yaml:"requestBody,omitempty"`
+	Responses   map[string]_OpenAPIResponse `yaml:"responses"`
+	Security    []map[string][]string       `yaml:"security,omitempty"`
+}
+
+type _OpenAPIParameter struct {
+	Name     string          `yaml:"name"`
+	In       string          `yaml:"in"`
+	Required bool            `yaml:"required,omitempty"`
+	Schema   *_OpenAPISchema `yaml:"schema"`
+	Example  string          `yaml:"example,omitempty"`
+}
+
+type _OpenAPIRequestBody struct {
+	Content map[string]_OpenAPIMediaType `yaml:"content"`
+}
+
+type _OpenAPIResponse struct {
+	Description string                       `yaml:"description"`
+	Content     map[string]_OpenAPIMediaType `yaml:"content,omitempty"`
+}
+
+type _OpenAPIMediaType struct {
+	Schema  *_OpenAPISchema `yaml:"schema,omitempty"`
+	Example any             `yaml:"example,omitempty"`
+}
+
+type _OpenAPISchema struct {
+	Type       string                     `yaml:"type,omitempty"`
+	Format     string                     `yaml:"format,omitempty"`
+	Nullable   bool                       `yaml:"nullable,omitempty"`
+	Properties map[string]*_OpenAPISchema `yaml:"properties,omitempty"`
+	Items      *_OpenAPISchema            `yaml:"items,omitempty"`
+}
+
+type _OpenAPIComponents struct {
+	SecuritySchemes map[string]_OpenAPISecurityScheme `yaml:"securitySchemes"`
+}
+
+type _OpenAPISecurityScheme struct {
+	Type   string `yaml:"type"`
+	Scheme string `yaml:"scheme"`
+}
+
+// ExportOpenAPI writes a skeleton OpenAPI 3.0 spec, in YAML, inferred from the requests of the collection in dir.
+// The paths, methods, parameters and example bodies are taken from the requests and the servers from the
+// environments. The responses are inferred from results, e.g. read from the report of an earlier run via
+// brurunner.ReadReport, matched by the file path of the request.
+func ExportOpenAPI(w io.Writer, dir string, results []*brurunner.Result) error {
+	collection, err := readCollection(dir)
+	if err != nil {
+		return err
+	}
+
+	spec := &_OpenAPISpec{
+		OpenAPI: "3.0.3",
+		Info:    _OpenAPIInfo{Title: collection.name, Version: "1.0.0"},
+		Paths:   make(map[string]map[string]*_OpenAPIOperation),
+	}
+	for _, folder := range collection.folders {
+		spec.Tags = append(spec.Tags, _OpenAPITag{Name: folder.name})
+	}
+	serverVars := make([]string, 0)
+	operationIDs := make([]string, 0)
+	for _, folder := range slices.Concat([]_Folder{{requests: collection.requests}}, collection.folders) {
+		for _, request := range folder.allRequests() {
+			method, _ := request.document.Method()
+			serverVar, urlPath := splitServer(method.Value("url"))
+			if serverVar != "" && !slices.Contains(serverVars, serverVar) {
+				serverVars = append(serverVars, serverVar)
+			}
+			if spec.Paths[urlPath] == nil {
+				spec.Paths[urlPath] = make(map[string]*_OpenAPIOperation)
+			}
+			if _, ok := spec.Paths[urlPath][method.Name]; ok {
+				log.Warn().
+					Str("filePath", request.path).
+					Str("operation", method.Name+" "+urlPath).
+					Msg("operation exported already, ignoring the request")
+				continue
+			}
+
+			operation := spec.operation(request, urlPath, method)
+			operation.OperationID = getOperationID(request.name, operationIDs)
+			operationIDs = append(operationIDs, operation.OperationID)
+			if folder.name != "" {
+				operation.Tags = []string{folder.name}
+			}
+			operation.Responses = getResponses(filepath.Join(dir, filepath.FromSlash(request.path)), results)
+			spec.Paths[urlPath][method.Name] = operation
+		}
+	}
+	spec.Servers = getServers(serverVars, collection.environments)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(spec); err != nil {
+		return fmt.Errorf("could not write spec: %w", err)
+	}
+	return encoder.Close() //nolint:wrapcheck // Only flushes the encoder
+}
+
+// splitServer returns the variable of the server, if the URL starts with one like "{{baseUrl}}", and the path of
+// the URL in OpenAPI's syntax, e.g. "/users/{id}"
+func splitServer(rawURL string) (string, string) {
+	rawURL, _, _ = strings.Cut(rawURL, "?")
+	serverVar := ""
+	if match := _serverVariableRegex.FindStringSubmatch(rawURL); match != nil {
+		serverVar = strings.TrimSpace(match[1])
+		rawURL = strings.TrimPrefix(rawURL, match[0])
+	} else {
+		rawURL = _serverRegex.ReplaceAllString(rawURL, "")
+	}
+	urlPath := _pathParamRegex.ReplaceAllString(rawURL, "/{$1}")
+	if !strings.HasPrefix(urlPath, "/") {
+		urlPath = "/" + urlPath
+	}
+	return serverVar, urlPath
+}
+
+// getServers returns the values of the server variables in the environments
+func getServers(serverVars []string, environments map[string]*bruparser.Document) []_OpenAPIServer {
+	servers := make([]_OpenAPIServer, 0)
+	envNames := lo.Keys(environments)
+	slices.Sort(envNames)
+	for _, serverVar := range serverVars {
+		found := false
+		for _, envName := range envNames {
+			vars, _ := environments[envName].Section("vars")
+			if value := vars.Value(serverVar); value != "" {
+				servers = append(servers, _OpenAPIServer{URL: value, Description: envName})
+				found = true
+			}
+		}
+		if !found {
+			servers = append(servers, _OpenAPIServer{
+				URL:       "{" + serverVar + "}",
+				Variables: map[string]_OpenAPIServerVariable{serverVar: {Default: ""}},
+			})
+		}
+	}
+	return servers
+}
+
+func (s *_OpenAPISpec) operation(request _Request, urlPath string, method bruparser.Section) *_OpenAPIOperation {
+	document := request.document
+	operation := &_OpenAPIOperation{Summary: request.name, Description: docs(document)}
+
+	pathParams, _ := document.Section("params:path")
+	for _, match := range _openAPIPathParamRegex.FindAllStringSubmatch(urlPath, -1) {
+		operation.Parameters = append(operation.Parameters, _OpenAPIParameter{
+			Name: match[1], In: "path", Required: true, Schema: &_OpenAPISchema{Type: "string"},
+			Example: exampleValue(pathParams.Value(match[1])),
+		})
+	}
+	queryParams, _ := document.Section("params:query")
+	for _, kv := range queryParams.Entries {
+		operation.Parameters = append(operation.Parameters, _OpenAPIParameter{
+			Name: kv.Key, In: "query", Schema: &_OpenAPISchema{Type: "string"}, Example: exampleValue(kv.Value),
+		})
+	}
+	headers, _ := document.Section("headers")
+	for _, kv := range headers.Entries {
+		if slices.ContainsFunc(_implicitHeaders, func(h string) bool { return strings.EqualFold(h, kv.Key) }) {
+			continue
+		}
+		operation.Parameters = append(operation.Parameters, _OpenAPIParameter{
+			Name: kv.Key, In: "header", Schema: &_OpenAPISchema{Type: "string"}, Example: exampleValue(kv.Value),
+		})
+	}
+
+	operation.RequestBody = requestBody(document, method.Value("body"))
+	switch authMode := method.Value("auth"); authMode {
+	case "basic", "bearer":
+		schemeName := authMode + "Auth"
+		if s.Components == nil {
+			s.Components = &_OpenAPIComponents{SecuritySchemes: make(map[string]_OpenAPISecurityScheme)}
+		}
+		s.Components.SecuritySchemes[schemeName] = _OpenAPISecurityScheme{Type: "http", Scheme: authMode}
+		operation.Security = []map[string][]string{{schemeName: {}}}
+	}
+	return operation
+}
+
+func requestBody(document *bruparser.Document, bodyType string) *_OpenAPIRequestBody {
+	section, ok := document.Section("body:" + bodyType)
+	if !ok {
+		return nil
+	}
+
+	var mediaType string
+	content := _OpenAPIMediaType{}
+	switch bodyType {
+	case "json":
+		mediaType = "application/json"
+		var example any
+		if err := json.Unmarshal([]byte(section.Text), &example); err == nil {
+			content.Schema, content.Example = inferSchema(example), example
+		} else {
+			// e.g. unquoted variables like {"id": {{id}}}
+			content.Schema = &_OpenAPISchema{Type: "object"}
+		}
+	case "xml", "text", "sparql":
+		mediaType = map[string]string{"xml": "application/xml", "text": "text/plain", "sparql": "application/sparql-query"}[bodyType]
+		content.Schema, content.Example = &_OpenAPISchema{Type: "string"}, section.Text
+	case "graphql":
+		mediaType = "application/json"
+		content.Schema = &_OpenAPISchema{Type: "object", Properties: map[string]*_OpenAPISchema{
+			"query":     {Type: "string"},
+			"variables": {Type: "object"},
+		}}
+	case "form-urlencoded", "multipart-form":
+		mediaType = map[string]string{"form-urlencoded": "application/x-www-form-urlencoded", "multipart-form": "multipart/form-data"}[bodyType]
+		content.Schema = &_OpenAPISchema{Type: "object", Properties: make(map[string]*_OpenAPISchema)}
+		for _, kv := range section.Entries {
+			property := &_OpenAPISchema{Type: "string"}
+			if strings.HasPrefix(kv.Value, "@file(") {
+				property.Format = "binary"
+			}
+			content.Schema.Properties[kv.Key] = property
+		}
+	default:
+		return nil
+	}
+	return &_OpenAPIRequestBody{Content: map[string]_OpenAPIMediaType{mediaType: content}}
+}
+
+// getResponses returns a response per status code of the results of the Bru file, with the schema inferred from
+// the JSON bodies
+func getResponses(bruFilePath string, results []*brurunner.Result) map[string]_OpenAPIResponse {
+	responses := make(map[string]_OpenAPIResponse)
+	for _, result := range results {
+		if result.Response == nil || !isSameFile(result.FilePath, bruFilePath) {
+			continue
+		}
+		statusCode := strconv.Itoa(result.Response.StatusCode)
+		if _, ok := responses[statusCode]; ok {
+			continue
+		}
+
+		response := _OpenAPIResponse{Description: http.StatusText(result.Response.StatusCode)}
+		mediaType, _, _ := mime.ParseMediaType(result.Response.Headers.Get("Content-Type"))
+		var body any
+		if strings.Contains(mediaType, "json") && json.Unmarshal(result.Response.Body, &body) == nil {
+			response.Content = map[string]_OpenAPIMediaType{mediaType: {Schema: inferSchema(body)}}
+		}
+		responses[statusCode] = response
+	}
+	if len(responses) == 0 {
+		responses["default"] = _OpenAPIResponse{Description: "Default response"}
+	}
+	return responses
+}
+
+// inferSchema returns the schema of a JSON value, the items of arrays are inferred from the first item
+func inferSchema(value any) *_OpenAPISchema {
+	switch v := value.(type) {
+	case map[string]any:
+		schema := &_OpenAPISchema{Type: "object", Properties: make(map[string]*_OpenAPISchema, len(v))}
+		for key, property := range v {
+			schema.Properties[key] = inferSchema(property)
+		}
+		return schema
+	case []any:
+		schema := &_OpenAPISchema{Type: "array", Items: &_OpenAPISchema{}}
+		if len(v) > 0 {
+			schema.Items = inferSchema(v[0])
+		}
+		return schema
+	case string:
+		return &_OpenAPISchema{Type: "string"}
+	case float64:
+		if v == math.Trunc(v) {
+			return &_OpenAPISchema{Type: "integer"}
+		}
+		return &_OpenAPISchema{Type: "number"}
+	case bool:
+		return &_OpenAPISchema{Type: "boolean"}
+	default:
+		return &_OpenAPISchema{Nullable: true}
+	}
+}
+
+// getOperationID returns the name in camel case, e.g. "getUser" for "Get user", unique among operationIDs
+func getOperationID(name string, operationIDs []string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for i, word := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(word)
+		} else {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	operationID := strings.Join(words, "")
+	if operationID == "" {
+		operationID = "operation"
+	}
+	uniqueID := operationID
+	for i := 2; slices.Contains(operationIDs, uniqueID); i++ {
+		uniqueID = operationID + strconv.Itoa(i)
+	}
+	return uniqueID
+}
+
+// exampleValue returns the value unless it has variables, which are no examples
+func exampleValue(value string) string {
+	if strings.Contains(value, "{{") {
+		return ""
+	}
+	return value
+}
+
+func isSameFile(filePath1 string, filePath2 string) bool {
+	abs1, err1 := filepath.Abs(filePath1)
+	abs2, err2 := filepath.Abs(filePath2)
+	return err1 == nil && err2 == nil && abs1 == abs2
+}
@@ -0,0 +1,164 @@
+package bruexporter
+
+import (
+	"bytes"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+func TestExportOpenAPI(t *testing.T) {
+	t.Parallel()
+	dir := writeTestCollection(t)
+	jsonHeaders := http.Header{"Content-Type": {"application/json; charset=utf-8"}}
+	var report bytes.Buffer
+	require.NoError(t, brurunner.WriteReport(&report, []*brurunner.Result{
+		{
+			FilePath: filepath.Join(dir, "users", "get_user.bru"),
+			Response: &brurunner.ResponseInfo{
+				Status: "200 OK", StatusCode: http.StatusOK, Headers: jsonHeaders,
+				Body: []byte(`{"id": 42, "name": "Ada", "score": 1.5, "tags": ["admin"], "manager": null}`),
+			},
+		},
+		{
+			FilePath: filepath.Join(dir, "users", "get_user.bru"),
+			Response: &brurunner.ResponseInfo{
+				Status: "404 Not Found", StatusCode: http.StatusNotFound, Headers: http.Header{}, Body: []byte("not found"),
+			},
+		},
+	}))
+	results, err := brurunner.ReadReport(&report)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, ExportOpenAPI(&out, dir, results))
+	require.Equal(t, `openapi: 3.0.3
+info:
+  title: Users API
+  version: 1.0.0
+servers:
+  - url: http://localhost:8080
+    description: local
+  - url: https://api.example.com
+    description: prod
+tags:
+  - name: Users
+paths:
+  /health:
+    get:
+      summary: Health
+      operationId: health
+      responses:
+        default:
+          description: Default response
+  /users:
+    post:
+      tags:
+        - Users
+      summary: Create user
+      operationId: createUser
+      description: Creates a user
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                age:
+                  type: integer
+                name:
+                  type: string
+            example:
+              age: 36
+              name: Ada
+      responses:
+        default:
+          description: Default response
+  /users/{id}:
+    get:
+      tags:
+        - Users
+      summary: Get user
+      operationId: getUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+          example: "42"
+        - name: verbose
+          in: query
+          schema:
+            type: string
+          example: "true"
+        - name: page
+          in: query
+          schema:
+            type: string
+          example: "1"
+        - name: X-Request-Id
+          in: header
+          schema:
+            type: string
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id:
+                    type: integer
+                  manager:
+                    nullable: true
+                  name:
+                    type: string
+                  score:
+                    type: number
+                  tags:
+                    type: array
+                    items:
+                      type: string
+        "404":
+          description: Not Found
+      security:
+        - bearerAuth: []
+components:
+  securitySchemes:
+    bearerAuth:
+      type: http
+      scheme: bearer
+`, out.String())
+}
+
+func TestSplitServer(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		url               string
+		expectedServerVar string
+		expectedPath      string
+	}{
+		{url: "{{baseUrl}}/users/:id?verbose=true", expectedServerVar: "baseUrl", expectedPath: "/users/{id}"},
+		{url: "https://example.com/users/:id/posts/:postId", expectedPath: "/users/{id}/posts/{postId}"},
+		{url: "https://example.com", expectedPath: "/"},
+		{url: "users", expectedPath: "/users"},
+	}
+	for _, testCase := range testCases {
+		serverVar, urlPath := splitServer(testCase.url)
+		require.Equal(t, testCase.expectedServerVar, serverVar, testCase.url)
+		require.Equal(t, testCase.expectedPath, urlPath, testCase.url)
+	}
+}
+
+func TestGetOperationID(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "getUserByID", getOperationID("Get user by ID", nil))
+	require.Equal(t, "getUserByID2", getOperationID("GET user-by ID", []string{"getUserByID"}))
+	require.Equal(t, "operation", getOperationID("???", nil))
+}
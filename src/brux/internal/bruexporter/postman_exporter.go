@@ -0,0 +1,277 @@
+package bruexporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+const _postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Bruno APIs and their Postman equivalents, the reverse of the translation of "brux import postman"
+var _brunoScriptReplacer = strings.NewReplacer(
+	"bru.getEnvVar(", "pm.environment.get(",
+	"bru.setEnvVar(", "pm.environment.set(",
+	"bru.getVar(", "pm.variables.get(",
+	"bru.setVar(", "pm.variables.set(",
+	"bru.getGlobalEnvVar(", "pm.globals.get(",
+	"bru.setGlobalEnvVar(", "pm.globals.set(",
+	"bru.setNextRequest(", "pm.setNextRequest(",
+	"res.getBody()", "pm.response.json()",
+	"res.getStatus()", "pm.response.code",
+	"res.getResponseTime()", "pm.response.responseTime",
+	"res.getHeader(", "pm.response.headers.get(",
+	"req.getUrl()", "pm.request.url",
+	"req.getMethod()", "pm.request.method",
+	"req.getName()", "pm.info.requestName",
+)
+
+// Calls of the global test and expect functions of Bruno, but not of methods like "regex.test("
+var _brunoTestRegex = regexp.MustCompile(`(^|[^.\w$])(test|expect)\(`)
+
+// _PostmanCollection is a Postman v2.1 collection.
+// Ref: https://schema.postman.com/collection/json/v2.1.0/draft-07/docs/index.html
+type _PostmanCollection struct {
+	Info  _PostmanInfo    `json:"info"`
+	Item  []_PostmanItem  `json:"item"`
+	Event []_PostmanEvent `json:"event,omitempty"`
+}
+
+type _PostmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+type _PostmanItem struct {
+	Name        string           `json:"name"`
+	Item        []_PostmanItem   `json:"item,omitempty"`
+	Request     *_PostmanRequest `json:"request,omitempty"`
+	Event       []_PostmanEvent  `json:"event,omitempty"`
+	Description string           `json:"description,omitempty"`
+}
+
+type _PostmanRequest struct {
+	Method      string             `json:"method"`
+	Header      []_PostmanKeyValue `json:"header"`
+	URL         _PostmanURL        `json:"url"`
+	Body        *_PostmanBody      `json:"body,omitempty"`
+	Auth        *_PostmanAuth      `json:"auth,omitempty"`
+	Description string             `json:"description,omitempty"`
+}
+
+type _PostmanURL struct {
+	Raw      string             `json:"raw"`
+	Query    []_PostmanKeyValue `json:"query,omitempty"`
+	Variable []_PostmanKeyValue `json:"variable,omitempty"`
+}
+
+type _PostmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+	// "text" or "file" for form data, "string" for auth
+	Type string `json:"type,omitempty"`
+	// Paths of the files of form data
+	Src []string `json:"src,omitempty"`
+}
+
+type _PostmanBody struct {
+	Mode       string             `json:"mode"`
+	Raw        string             `json:"raw,omitempty"`
+	URLEncoded []_PostmanKeyValue `json:"urlencoded,omitempty"`
+	FormData   []_PostmanKeyValue `json:"formdata,omitempty"`
+	GraphQL    *_PostmanGraphQL   `json:"graphql,omitempty"`
+	Options    *_PostmanOptions   `json:"options,omitempty"`
+}
+
+type _PostmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+type _PostmanOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type _PostmanAuth struct {
+	Type   string             `json:"type"`
+	Basic  []_PostmanKeyValue `json:"basic,omitempty"`
+	Bearer []_PostmanKeyValue `json:"bearer,omitempty"`
+}
+
+type _PostmanEvent struct {
+	// "prerequest" or "test"
+	Listen string         `json:"listen"`
+	Script _PostmanScript `json:"script"`
+}
+
+type _PostmanScript struct {
+	Type string   `json:"type"`
+	Exec []string `json:"exec"`
+}
+
+// ExportPostman writes the collection in dir as a Postman v2.1 collection, with the variables kept as they are.
+// The scripts and tests are exported with the Bruno APIs rewritten to the Postman ones where possible.
+func ExportPostman(w io.Writer, dir string) error {
+	collection, err := readCollection(dir)
+	if err != nil {
+		return err
+	}
+
+	postmanCollection := _PostmanCollection{
+		Info:  _PostmanInfo{Name: collection.name, Schema: _postmanSchema},
+		Item:  postmanItems(collection._Folder),
+		Event: postmanEvents(collection.document),
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(postmanCollection); err != nil {
+		return fmt.Errorf("could not write collection: %w", err)
+	}
+	return nil
+}
+
+// postmanItems returns the requests and then the sub-folders of the folder, as brux runs them
+func postmanItems(folder _Folder) []_PostmanItem {
+	items := make([]_PostmanItem, 0, len(folder.requests)+len(folder.folders))
+	for _, request := range folder.requests {
+		items = append(items, _PostmanItem{
+			Name:    request.name,
+			Request: postmanRequest(request.document),
+			Event:   postmanEvents(request.document),
+		})
+	}
+	for _, subFolder := range folder.folders {
+		items = append(items, _PostmanItem{
+			Name:        subFolder.name,
+			Item:        postmanItems(subFolder),
+			Event:       postmanEvents(subFolder.document),
+			Description: docs(subFolder.document),
+		})
+	}
+	return items
+}
+
+func postmanRequest(document *bruparser.Document) *_PostmanRequest {
+	method, _ := document.Method()
+	request := &_PostmanRequest{
+		Method:      strings.ToUpper(method.Name),
+		Header:      postmanKeyValues(document, "headers"),
+		URL:         _PostmanURL{Raw: method.Value("url")},
+		Description: docs(document),
+	}
+	request.URL.Query = postmanKeyValues(document, "params:query")
+	request.URL.Variable = postmanKeyValues(document, "params:path")
+	request.Body = postmanBody(document, method.Value("body"))
+
+	switch mode := method.Value("auth"); mode {
+	case "basic", "bearer":
+		section, _ := document.Section("auth:" + mode)
+		auth := &_PostmanAuth{Type: mode}
+		for _, kv := range section.EnabledEntries() {
+			kv := _PostmanKeyValue{Key: kv.Key, Value: kv.Value, Type: "string"}
+			if mode == "basic" {
+				auth.Basic = append(auth.Basic, kv)
+			} else {
+				auth.Bearer = append(auth.Bearer, kv)
+			}
+		}
+		request.Auth = auth
+	case "none":
+		request.Auth = &_PostmanAuth{Type: "noauth"}
+	}
+	return request
+}
+
+func postmanBody(document *bruparser.Document, bodyType string) *_PostmanBody {
+	section, ok := document.Section("body:" + bodyType)
+	if !ok {
+		return nil
+	}
+
+	switch bodyType {
+	case "json", "xml", "text", "sparql":
+		body := &_PostmanBody{Mode: "raw", Raw: section.Text, Options: &_PostmanOptions{}}
+		body.Options.Raw.Language = map[string]string{"json": "json", "xml": "xml"}[bodyType]
+		if body.Options.Raw.Language == "" {
+			body.Options.Raw.Language = "text"
+		}
+		return body
+	case "form-urlencoded":
+		return &_PostmanBody{Mode: "urlencoded", URLEncoded: postmanKeyValues(document, section.Name)}
+	case "multipart-form":
+		body := &_PostmanBody{Mode: "formdata"}
+		for _, kv := range section.Entries {
+			field := _PostmanKeyValue{Key: kv.Key, Value: kv.Value, Disabled: !kv.Enabled, Type: "text"}
+			if strings.HasPrefix(kv.Value, "@file(") && strings.HasSuffix(kv.Value, ")") {
+				field.Type = "file"
+				field.Value = ""
+				field.Src = strings.Split(strings.TrimSuffix(strings.TrimPrefix(kv.Value, "@file("), ")"), "|")
+			}
+			body.FormData = append(body.FormData, field)
+		}
+		return body
+	case "graphql":
+		variables, _ := document.Section("body:graphql:vars")
+		return &_PostmanBody{Mode: "graphql", GraphQL: &_PostmanGraphQL{Query: section.Text, Variables: variables.Text}}
+	default:
+		return nil
+	}
+}
+
+func postmanKeyValues(document *bruparser.Document, sectionName string) []_PostmanKeyValue {
+	section, _ := document.Section(sectionName)
+	kvs := make([]_PostmanKeyValue, 0, len(section.Entries))
+	for _, kv := range section.Entries {
+		kvs = append(kvs, _PostmanKeyValue{Key: kv.Key, Value: kv.Value, Disabled: !kv.Enabled})
+	}
+	return kvs
+}
+
+// postmanEvents returns the pre-request script and, as Postman runs both after the response, the post-response
+// script and the tests
+func postmanEvents(document *bruparser.Document) []_PostmanEvent {
+	if document == nil {
+		return nil
+	}
+
+	events := make([]_PostmanEvent, 0)
+	if section, ok := document.Section("script:pre-request"); ok && section.Text != "" {
+		events = append(events, postmanEvent("prerequest", section.Text))
+	}
+	scripts := make([]string, 0)
+	for _, name := range []string{"script:post-response", "tests"} {
+		if section, ok := document.Section(name); ok && section.Text != "" {
+			scripts = append(scripts, section.Text)
+		}
+	}
+	if len(scripts) > 0 {
+		events = append(events, postmanEvent("test", strings.Join(scripts, "\n\n")))
+	}
+	return events
+}
+
+func postmanEvent(listen string, script string) _PostmanEvent {
+	script = _brunoTestRegex.ReplaceAllString(script, "${1}pm.${2}(")
+	script = _brunoScriptReplacer.Replace(script)
+	return _PostmanEvent{
+		Listen: listen,
+		Script: _PostmanScript{Type: "text/javascript", Exec: strings.Split(script, "\n")},
+	}
+}
+
+// docs returns the text of the "docs" section, empty if there is none
+func docs(document *bruparser.Document) string {
+	if document == nil {
+		return ""
+	}
+	section, _ := document.Section("docs")
+	return section.Text
+}
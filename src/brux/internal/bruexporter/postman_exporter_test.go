@@ -0,0 +1,62 @@
+package bruexporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportPostman(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	require.NoError(t, ExportPostman(&out, writeTestCollection(t)))
+
+	var collection _PostmanCollection
+	require.NoError(t, json.Unmarshal(out.Bytes(), &collection))
+	require.Equal(t, "Users API", collection.Info.Name)
+	require.Equal(t, _postmanSchema, collection.Info.Schema)
+	require.Len(t, collection.Item, 2)
+	require.Equal(t, "Health", collection.Item[0].Name)
+	require.Equal(t, "Users", collection.Item[1].Name)
+
+	createUser := collection.Item[1].Item[0]
+	require.Equal(t, "Create user", createUser.Name)
+	require.Equal(t, "POST", createUser.Request.Method)
+	require.Equal(t, "{{baseUrl}}/users", createUser.Request.URL.Raw)
+	require.Equal(t, "raw", createUser.Request.Body.Mode)
+	require.Equal(t, "json", createUser.Request.Body.Options.Raw.Language)
+	require.Equal(t, "noauth", createUser.Request.Auth.Type)
+	require.Equal(t, "Creates a user", createUser.Request.Description)
+	require.Equal(t, []_PostmanEvent{postmanEvent("prerequest", `bru.setVar("requestId", "1");`)}, createUser.Event)
+	require.Equal(t, []string{`pm.variables.set("requestId", "1");`}, createUser.Event[0].Script.Exec)
+
+	getUser := collection.Item[1].Item[1]
+	require.Equal(t, []_PostmanKeyValue{
+		{Key: "verbose", Value: "true"},
+		{Key: "page", Value: "1", Disabled: true},
+	}, getUser.Request.URL.Query)
+	require.Equal(t, []_PostmanKeyValue{{Key: "id", Value: "42"}}, getUser.Request.URL.Variable)
+	require.Equal(t, &_PostmanAuth{
+		Type:   "bearer",
+		Bearer: []_PostmanKeyValue{{Key: "token", Value: "{{token}}", Type: "string"}},
+	}, getUser.Request.Auth)
+	require.Equal(t, []string{
+		`pm.test("is ok", function() {`,
+		`  pm.expect(pm.response.code).to.equal(200);`,
+		`});`,
+	}, getUser.Event[0].Script.Exec)
+}
+
+func TestExportPostman_Scripts(t *testing.T) {
+	t.Parallel()
+	event := postmanEvent("test", `const body = res.getBody();
+bru.setEnvVar("id", body.id);
+expect(/^\d+$/.test(body.id)).to.be.true;`)
+	require.Equal(t, []string{
+		`const body = pm.response.json();`,
+		`pm.environment.set("id", body.id);`,
+		`pm.expect(/^\d+$/.test(body.id)).to.be.true;`,
+	}, event.Script.Exec)
+}
@@ -0,0 +1,190 @@
+package bruexporter
+
+import (
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/samber/lo"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+// Format is a language or tool to export requests to
+type Format string
+
+const (
+	FormatCurl           Format = "curl"
+	FormatHTTPie         Format = "httpie"
+	FormatGo             Format = "go"
+	FormatPythonRequests Format = "python-requests"
+)
+
+// SnippetFormats are the formats of ExportSnippet
+var SnippetFormats = []Format{FormatCurl, FormatHTTPie, FormatGo, FormatPythonRequests}
+
+var (
+	ErrUnknownFormat = errors.New("unknown format")
+	ErrBinaryBody    = errors.New("binary bodies can't be exported")
+)
+
+// ExportSnippet writes a command or code that sends the request.
+// The values of headers like Authorization are redacted unless showSecrets is set, secret variables are redacted
+// when resolving the request via brurunner.WithRedactedSecrets.
+func ExportSnippet(w io.Writer, format Format, request brurunner.ResolvedRequest, showSecrets bool) error {
+	if request.Body != nil && !utf8.Valid(request.Body) {
+		return ErrBinaryBody
+	}
+	headers := getHeaders(request.Headers, showSecrets)
+
+	var snippet string
+	switch format {
+	case FormatCurl:
+		snippet = curlSnippet(request, headers)
+	case FormatHTTPie:
+		snippet = httpieSnippet(request, headers)
+	case FormatGo:
+		var err error
+		if snippet, err = goSnippet(request, headers); err != nil {
+			return err
+		}
+	case FormatPythonRequests:
+		snippet = pythonRequestsSnippet(request, headers)
+	default:
+		return fmt.Errorf("%w: '%s'", ErrUnknownFormat, format)
+	}
+
+	if _, err := io.WriteString(w, snippet); err != nil {
+		return fmt.Errorf("could not write snippet: %w", err)
+	}
+	return nil
+}
+
+// getHeaders returns the headers sorted by name, so that the snippets are deterministic
+func getHeaders(headers http.Header, showSecrets bool) [][2]string {
+	names := lo.Keys(headers)
+	slices.Sort(names)
+	sortedHeaders := make([][2]string, 0, len(headers))
+	for _, name := range names {
+		for _, value := range headers[name] {
+			if !showSecrets && brurunner.IsSecretHeader(name) {
+				value = "<redacted>"
+			}
+			sortedHeaders = append(sortedHeaders, [2]string{name, value})
+		}
+	}
+	return sortedHeaders
+}
+
+func curlSnippet(request brurunner.ResolvedRequest, headers [][2]string) string {
+	lines := []string{"curl " + shellQuote(request.URL)}
+	if request.Method != http.MethodGet {
+		lines[0] = "curl -X " + request.Method + " " + shellQuote(request.URL)
+	}
+	for _, header := range headers {
+		lines = append(lines, "-H "+shellQuote(header[0]+": "+header[1]))
+	}
+	if request.Body != nil {
+		lines = append(lines, "--data-raw "+shellQuote(string(request.Body)))
+	}
+	return strings.Join(lines, " \\\n  ") + "\n"
+}
+
+func httpieSnippet(request brurunner.ResolvedRequest, headers [][2]string) string {
+	lines := []string{"http " + request.Method + " " + shellQuote(request.URL)}
+	for _, header := range headers {
+		lines = append(lines, shellQuote(header[0]+":"+header[1]))
+	}
+	if request.Body != nil {
+		lines = append(lines, "--raw "+shellQuote(string(request.Body)))
+	}
+	return strings.Join(lines, " \\\n  ") + "\n"
+}
+
+func goSnippet(request brurunner.ResolvedRequest, headers [][2]string) (string, error) {
+	var code strings.Builder
+	code.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n")
+	if request.Body != nil {
+		code.WriteString("\t\"strings\"\n")
+	}
+	code.WriteString(")\n\nfunc main() {\n")
+	body := "nil"
+	if request.Body != nil {
+		code.WriteString("\tbody := strings.NewReader(" + goQuote(string(request.Body)) + ")\n")
+		body = "body"
+	}
+	code.WriteString("\treq, err := http.NewRequest(" + strconv.Quote(request.Method) + ", " +
+		strconv.Quote(request.URL) + ", " + body + ")\n")
+	code.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, header := range headers {
+		code.WriteString("\treq.Header.Add(" + strconv.Quote(header[0]) + ", " + strconv.Quote(header[1]) + ")\n")
+	}
+	code.WriteString(`
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(resp.Status)
+	fmt.Println(string(data))
+}
+`)
+
+	formatted, err := format.Source([]byte(code.String()))
+	if err != nil {
+		return "", fmt.Errorf("could not format Go code: %w", err)
+	}
+	return string(formatted), nil
+}
+
+func pythonRequestsSnippet(request brurunner.ResolvedRequest, headers [][2]string) string {
+	var code strings.Builder
+	code.WriteString("import requests\n\n")
+	code.WriteString("url = " + pythonQuote(request.URL) + "\n")
+	code.WriteString("headers = {\n")
+	for _, header := range headers {
+		code.WriteString("    " + pythonQuote(header[0]) + ": " + pythonQuote(header[1]) + ",\n")
+	}
+	code.WriteString("}\n")
+	data := ""
+	if request.Body != nil {
+		code.WriteString("data = " + pythonQuote(string(request.Body)) + "\n")
+		data = ", data=data"
+	}
+	code.WriteString("\nresponse = requests.request(" + pythonQuote(request.Method) + ", url, headers=headers" + data + ")\n")
+	code.WriteString("print(response.status_code)\nprint(response.text)\n")
+	return code.String()
+}
+
+// shellQuote quotes str for POSIX shells, using $'...' if it contains characters like newlines
+func shellQuote(str string) string {
+	if strings.ContainsAny(str, "\n\r\t") {
+		replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+		return "$'" + replacer.Replace(str) + "'"
+	}
+	return "'" + strings.ReplaceAll(str, "'", `'\''`) + "'"
+}
+
+// goQuote uses a raw string literal for readability if possible
+func goQuote(str string) string {
+	if !strings.Contains(str, "`") && !strings.Contains(str, "\r") {
+		return "`" + str + "`"
+	}
+	return strconv.Quote(str)
+}
+
+// pythonQuote returns a Python string literal, Go's escapes are a subset of Python's for valid UTF-8
+func pythonQuote(str string) string {
+	return strconv.Quote(str)
+}
@@ -0,0 +1,85 @@
+package bruexporter
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+func TestExportSnippet(t *testing.T) {
+	t.Parallel()
+	request := brurunner.ResolvedRequest{
+		Method: http.MethodPost,
+		URL:    "https://example.com/users",
+		Headers: http.Header{
+			"Content-Type":  {"application/json"},
+			"Authorization": {"Bearer token"},
+		},
+		Body: []byte("{\"name\": \"O'Neil\"}\n"),
+	}
+	testCases := []struct {
+		format      Format
+		showSecrets bool
+		expected    string
+	}{
+		{
+			format: FormatCurl,
+			expected: `curl -X POST 'https://example.com/users' \
+  -H 'Authorization: <redacted>' \
+  -H 'Content-Type: application/json' \
+  --data-raw $'{"name": "O\'Neil"}\n'
+`,
+		},
+		{
+			format:      FormatHTTPie,
+			showSecrets: true,
+			expected: `http POST 'https://example.com/users' \
+  'Authorization:Bearer token' \
+  'Content-Type:application/json' \
+  --raw $'{"name": "O\'Neil"}\n'
+`,
+		},
+		{
+			format: FormatPythonRequests,
+			expected: `import requests
+
+url = "https://example.com/users"
+headers = {
+    "Authorization": "<redacted>",
+    "Content-Type": "application/json",
+}
+data = "{\"name\": \"O'Neil\"}\n"
+
+response = requests.request("POST", url, headers=headers, data=data)
+print(response.status_code)
+print(response.text)
+`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(string(testCase.format), func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, ExportSnippet(&buf, testCase.format, request, testCase.showSecrets))
+			require.Equal(t, testCase.expected, buf.String())
+		})
+	}
+
+	t.Run("go", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, ExportSnippet(&buf, FormatGo, request, false))
+		require.Contains(t, buf.String(), "body := strings.NewReader(`{\"name\": \"O'Neil\"}\n`)")
+		require.Contains(t, buf.String(), `req.Header.Add("Authorization", "<redacted>")`)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		t.Parallel()
+		err := ExportSnippet(&bytes.Buffer{}, FormatCurl, brurunner.ResolvedRequest{Method: http.MethodPost, Body: []byte{0xff}}, false)
+		require.ErrorIs(t, err, ErrBinaryBody)
+	})
+}
@@ -0,0 +1,287 @@
+package bruexpr
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ashishb/brux/src/brux/internal/jsonquery"
+)
+
+var (
+	ErrUnknownOperator = errors.New("unknown operator")
+	ErrInvalidOperand  = errors.New("invalid operand")
+)
+
+// AssertionResult is the outcome of evaluating a single assertion
+type AssertionResult struct {
+	// Expression like "res.status"
+	Expression string
+	// Operator like "eq"
+	Operator string
+	// Expected value as written in the Bru file
+	Expected string
+	Actual   any
+	Passed   bool
+	// Set if the assertion could not be evaluated
+	Err error
+}
+
+func (r AssertionResult) String() string {
+	str := strings.TrimSpace(r.Expression + ": " + r.Operator + " " + r.Expected)
+	if r.Err != nil {
+		return str + " (" + r.Err.Error() + ")"
+	}
+	if !r.Passed {
+		return fmt.Sprintf("%s (actual: %s)", str, formatValue(r.Actual))
+	}
+	return str
+}
+
+// Assert evaluates an assertion of the "assert" section, e.g. expression "res.status" and assertion "eq 200".
+// Ref: https://docs.usebruno.com/testing/tests/assertions
+func Assert(resp Response, expression string, assertion string) AssertionResult {
+	operator, expected, _ := strings.Cut(strings.TrimSpace(assertion), " ")
+	result := AssertionResult{
+		Expression: expression,
+		Operator:   operator,
+		Expected:   strings.TrimSpace(expected),
+	}
+
+	actual, err := resp.Resolve(expression)
+	if err != nil && !errors.Is(err, jsonquery.ErrNotFound) {
+		result.Err = err
+		return result
+	}
+	defined := err == nil
+	result.Actual = actual
+	result.Passed, result.Err = evaluate(operator, actual, defined, result.Expected)
+	return result
+}
+
+//nolint:gocognit // A flat list of operators is the easiest to read
+func evaluate(operator string, actual any, defined bool, expectedStr string) (bool, error) {
+	expected := parseOperand(expectedStr)
+	switch operator {
+	case "eq":
+		return equals(actual, expected), nil
+	case "neq":
+		return !equals(actual, expected), nil
+	case "gt", "gte", "lt", "lte":
+		return compareNumbers(operator, actual, expected)
+	case "between":
+		lower, upper, found := strings.Cut(expectedStr, ",")
+		if !found {
+			return false, fmt.Errorf("%w: expected 'between <min>,<max>'", ErrInvalidOperand)
+		}
+		gte, err := compareNumbers("gte", actual, parseOperand(strings.TrimSpace(lower)))
+		if err != nil {
+			return false, err
+		}
+		lte, err := compareNumbers("lte", actual, parseOperand(strings.TrimSpace(upper)))
+		return gte && lte, err
+	case "in":
+		return isIn(actual, expectedStr), nil
+	case "notIn":
+		return !isIn(actual, expectedStr), nil
+	case "contains":
+		return contains(actual, expected), nil
+	case "notContains":
+		return !contains(actual, expected), nil
+	case "startsWith":
+		return strings.HasPrefix(toString(actual), toString(expected)), nil
+	case "endsWith":
+		return strings.HasSuffix(toString(actual), toString(expected)), nil
+	case "matches", "notMatches":
+		re, err := regexp.Compile(toString(expected))
+		if err != nil {
+			return false, fmt.Errorf("%w: %w", ErrInvalidOperand, err)
+		}
+		return re.MatchString(toString(actual)) == (operator == "matches"), nil
+	case "length":
+		length, ok := lengthOf(actual)
+		return ok && equals(length, expected), nil
+	case "isEmpty":
+		length, ok := lengthOf(actual)
+		return ok && length == 0, nil
+	case "isNotEmpty":
+		length, ok := lengthOf(actual)
+		return ok && length > 0, nil
+	case "isNull":
+		return defined && actual == nil, nil
+	case "isDefined":
+		return defined, nil
+	case "isUndefined":
+		return !defined, nil
+	case "isTruthy":
+		return isTruthy(actual), nil
+	case "isFalsy":
+		return !isTruthy(actual), nil
+	case "isNumber":
+		_, ok := toNumber(actual)
+		_, isString := actual.(string)
+		return ok && !isString, nil
+	case "isString":
+		_, ok := actual.(string)
+		return ok, nil
+	case "isBoolean":
+		_, ok := actual.(bool)
+		return ok, nil
+	case "isArray":
+		_, ok := actual.([]any)
+		return ok, nil
+	case "isJson":
+		_, ok := actual.(map[string]any)
+		return ok, nil
+	default:
+		return false, fmt.Errorf("%w: '%s'", ErrUnknownOperator, operator)
+	}
+}
+
+// parseOperand parses the operand as a JSON literal, falling back to a plain string
+func parseOperand(str string) any {
+	if str == "" {
+		return nil
+	}
+	value, err := jsonquery.Decode([]byte(str))
+	if err != nil {
+		return strings.Trim(str, `'`)
+	}
+	return value
+}
+
+func equals(actual any, expected any) bool {
+	a, ok1 := toNumber(actual)
+	b, ok2 := toNumber(expected)
+	if ok1 && ok2 {
+		return a == b
+	}
+
+	actualJSON, err1 := json.Marshal(actual)
+	expectedJSON, err2 := json.Marshal(expected)
+	return err1 == nil && err2 == nil && bytes.Equal(actualJSON, expectedJSON)
+}
+
+func compareNumbers(operator string, actual any, expected any) (bool, error) {
+	a, ok := toNumber(actual)
+	if !ok {
+		return false, nil
+	}
+	b, ok := toNumber(expected)
+	if !ok {
+		return false, fmt.Errorf("%w: '%v' is not a number", ErrInvalidOperand, expected)
+	}
+	switch operator {
+	case "gt":
+		return a > b, nil
+	case "gte":
+		return a >= b, nil
+	case "lt":
+		return a < b, nil
+	default:
+		return a <= b, nil
+	}
+}
+
+func isIn(actual any, expectedStr string) bool {
+	var candidates []any
+	if list, ok := parseOperand(expectedStr).([]any); ok {
+		candidates = list
+	} else {
+		for _, value := range strings.Split(expectedStr, ",") {
+			candidates = append(candidates, parseOperand(strings.TrimSpace(value)))
+		}
+	}
+	for _, candidate := range candidates {
+		if equals(actual, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(actual any, expected any) bool {
+	switch value := actual.(type) {
+	case string:
+		return strings.Contains(value, toString(expected))
+	case []any:
+		for _, item := range value {
+			if equals(item, expected) {
+				return true
+			}
+		}
+		return false
+	case map[string]any:
+		_, ok := value[toString(expected)]
+		return ok
+	default:
+		return false
+	}
+}
+
+func lengthOf(value any) (int, bool) {
+	switch v := value.(type) {
+	case string:
+		return len(v), true
+	case []any:
+		return len(v), true
+	case map[string]any:
+		return len(v), true
+	default:
+		return 0, false
+	}
+}
+
+func isTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	default:
+		if number, ok := toNumber(v); ok {
+			return number != 0
+		}
+		return true
+	}
+}
+
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func toString(value any) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	return formatValue(value)
+}
+
+func formatValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
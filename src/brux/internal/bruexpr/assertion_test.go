@@ -0,0 +1,95 @@
+package bruexpr
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/jsonquery"
+)
+
+func TestAssert(t *testing.T) {
+	t.Parallel()
+	resp := Response{
+		Status:  200,
+		Headers: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+		Body:    []byte(`{"items": [{"id": 7, "name": "foo"}], "total": 1, "next": null}`),
+	}
+
+	tests := []struct {
+		expression string
+		assertion  string
+		passed     bool
+	}{
+		{"res.status", "eq 200", true},
+		{"res.status", "neq 200", false},
+		{"res.status", "in 200, 201", true},
+		{"res.status", "between 200,299", true},
+		{"res.headers.content-type", "contains json", true},
+		{`res.headers["content-type"]`, "eq application/json; charset=utf-8", true},
+		{"res.headers['Content-Type']", "contains json", true},
+		{"res.body.items[0].id", "eq 7", true},
+		{"res.body.items[0].id", "gt 7", false},
+		{"res.body.items[0].name", `eq "foo"`, true},
+		{"res.body.items[-1].name", "startsWith f", true},
+		{"res.body.items", "length 1", true},
+		{"res.body.items", "isArray", true},
+		{"res.body.total", "isNumber", true},
+		{"res.body.next", "isNull", true},
+		{"res.body.missing", "isUndefined", true},
+		{"res.body.missing", "isDefined", false},
+		{"res.body", "isJson", true},
+		{"res.body.items[*].id", "length 1", true},
+		{"res.body..name", `contains "foo"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression+" "+tt.assertion, func(t *testing.T) {
+			t.Parallel()
+			result := Assert(resp, tt.expression, tt.assertion)
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.passed, result.Passed, result.String())
+		})
+	}
+}
+
+func TestAssert_Errors(t *testing.T) {
+	t.Parallel()
+	resp := Response{Status: 200}
+	require.ErrorIs(t, Assert(resp, "res.status", "foo 200").Err, ErrUnknownOperator)
+	require.ErrorIs(t, Assert(resp, "req.url", "eq x").Err, ErrUnknownExpression)
+}
+
+func TestResponse_Query(t *testing.T) {
+	t.Parallel()
+	resp := Response{
+		Status:  200,
+		Headers: http.Header{"Etag": {`"v1"`}},
+		Body:    []byte(`{"items": [{"id": 7, "name": "foo"}, {"id": 8, "name": "bar"}]}`),
+	}
+	testCases := map[string]string{
+		"res.body.items[0].id": "7",
+		"$.items[0].id":        "7",
+		".items[0].id":         "7",
+		"items[0].id":          "7",
+		"$.items[*].name":      `["foo","bar"]`,
+		".items[] | .id":       "[7,8]",
+		".items[0].name":       "foo",
+		".missing":             "null",
+		"res.headers.etag":     `"v1"`,
+		`res.headers["etag"]`:  `"v1"`,
+		"res.status":           "200",
+	}
+	for filter, expected := range testCases {
+		value, err := resp.Query(filter)
+		require.NoError(t, err, filter)
+		actual, err := FormatValue(value)
+		require.NoError(t, err, filter)
+		require.Equal(t, expected, actual, filter)
+	}
+
+	require.ErrorIs(t, ValidateQuery("res.foo"), ErrUnknownExpression)
+	require.ErrorIs(t, ValidateQuery("res.headers.etag[0]"), jsonquery.ErrInvalidPath)
+	require.Error(t, ValidateQuery(".items[x]"))
+	require.NoError(t, ValidateQuery(""))
+}
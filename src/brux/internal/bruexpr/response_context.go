@@ -0,0 +1,116 @@
+package bruexpr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ashishb/brux/src/brux/internal/jsonquery"
+)
+
+var ErrUnknownExpression = errors.New("unknown expression")
+
+const _responsePrefix = "res"
+
+// Response is the response against which expressions like "res.body.id" are evaluated
+type Response struct {
+	Status       int
+	Headers      http.Header
+	Body         []byte
+	ResponseTime time.Duration
+}
+
+// Resolve evaluates an expression like "res.status", "res.headers.content-type", `res.headers["content-type"]` or
+// "res.body.items[0].id".
+// The body is decoded as JSON if possible and is treated as a string otherwise.
+func (r Response) Resolve(expr string) (any, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(expr), _responsePrefix+".")
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownExpression, expr)
+	}
+
+	name, path := rest, ""
+	if i := strings.IndexAny(rest, ".["); i >= 0 {
+		name, path = rest[:i], rest[i:]
+	}
+
+	switch name {
+	case "status":
+		return r.Status, nil
+	case "responseTime":
+		return r.ResponseTime.Milliseconds(), nil
+	case "headers":
+		name, err := jsonquery.Key(path)
+		if err != nil {
+			return nil, err
+		}
+		return r.resolveHeader(name), nil
+	case "body":
+		return jsonquery.Get(r.decodedBody(), path)
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownExpression, expr)
+	}
+}
+
+// Query evaluates a filter against the response: either an expression like "res.body.items[0].id" or
+// "res.headers.etag", or a JSONPath or jq path like "$.items[*].id" or ".items[].id" evaluated against the body.
+// Missing values are nil as in jq.
+func (r Response) Query(filter string) (any, error) {
+	filter = strings.TrimSpace(filter)
+	var (
+		value any
+		err   error
+	)
+	if strings.HasPrefix(filter, _responsePrefix+".") {
+		value, err = r.Resolve(filter)
+	} else {
+		value, err = jsonquery.Get(r.decodedBody(), filter)
+	}
+	if err != nil && !errors.Is(err, jsonquery.ErrNotFound) {
+		return nil, err
+	}
+	return value, nil
+}
+
+// ValidateQuery returns an error if the filter of Query is invalid
+func ValidateQuery(filter string) error {
+	_, err := Response{}.Query(filter)
+	return err
+}
+
+// FormatValue formats a value of an expression as text: strings as they are and other values as JSON
+func FormatValue(value any) (string, error) {
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("could not serialize '%v': %w", value, err)
+	}
+	return string(data), nil
+}
+
+func (r Response) resolveHeader(name string) any {
+	if name == "" {
+		headers := make(map[string]any, len(r.Headers))
+		for k := range r.Headers {
+			headers[strings.ToLower(k)] = r.Headers.Get(k)
+		}
+		return headers
+	}
+	if values := r.Headers.Values(name); len(values) > 0 {
+		return strings.Join(values, ", ")
+	}
+	return nil
+}
+
+func (r Response) decodedBody() any {
+	value, err := jsonquery.Decode(r.Body)
+	if err != nil {
+		return string(r.Body)
+	}
+	return value
+}
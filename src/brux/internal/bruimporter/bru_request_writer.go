@@ -0,0 +1,173 @@
+package bruimporter
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+// Characters not allowed in file names on some platforms
+var _invalidFileNameRegex = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]+`)
+
+// WriteRequest writes the request to a new file in dir, named after the request, with the next free seq of dir.
+// It returns the path of the file.
+func WriteRequest(dir string, document *bruparser.Document) (string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", fmt.Errorf("could not create directory: %w", err)
+	}
+
+	seq, err := getNextSeq(dir)
+	if err != nil {
+		return "", err
+	}
+	meta, _ := document.Section("meta")
+	meta.Name = "meta"
+	meta.SetValue("seq", strconv.Itoa(seq))
+	document.SetSection(meta)
+
+	filePath := getFreeFilePath(dir, meta.Value("name"))
+	if err := os.WriteFile(filePath, document.Bytes(), 0o600); err != nil {
+		return "", fmt.Errorf("could not write file: %w", err)
+	}
+	log.Info().
+		Str("filePath", filePath).
+		Int("seq", seq).
+		Msg("request written")
+	return filePath, nil
+}
+
+// getNextSeq returns one more than the largest seq of the requests in dir
+func getNextSeq(dir string) (int, error) {
+	filePaths, err := filepath.Glob(filepath.Join(dir, "*.bru"))
+	if err != nil {
+		return 0, fmt.Errorf("could not list requests: %w", err)
+	}
+
+	maxSeq := 0
+	for _, filePath := range filePaths {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return 0, fmt.Errorf("could not read file: %w", err)
+		}
+		bruFile, err := bruparser.NewBruFile(bytes.NewReader(data))
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("filePath", filePath).
+				Msg("could not parse file, ignoring its seq")
+			continue
+		}
+		if seq, ok := bruFile.Seq(); ok && bruFile.IsRequest() {
+			maxSeq = max(maxSeq, seq)
+		}
+	}
+	return maxSeq + 1, nil
+}
+
+// getFreeFilePath returns the path of a file named after the request that does not exist yet,
+// e.g. "users-2.bru" if "users.bru" exists
+func getFreeFilePath(dir string, name string) string {
+	fileName := getFileName(name)
+	if fileName == "" {
+		fileName = "request"
+	}
+	filePath := filepath.Join(dir, fileName+".bru")
+	for i := 2; fileOrDirExists(filePath); i++ {
+		filePath = filepath.Join(dir, fileName+"-"+strconv.Itoa(i)+".bru")
+	}
+	return filePath
+}
+
+// getFileName replaces the characters not allowed in file names
+func getFileName(name string) string {
+	return strings.TrimSpace(_invalidFileNameRegex.ReplaceAllString(name, "-"))
+}
+
+// MergeRequest adds the parts of generated that are missing from existing, e.g. new query params, and keeps
+// everything else of existing, so that importing a request again does not overwrite the changes made to it,
+// e.g. to the scripts or assertions. Empty values and "none", e.g. of the body mode, are replaced as well.
+// If the method changed, the section of the existing method is renamed to the generated one.
+func MergeRequest(existing *bruparser.Document, generated *bruparser.Document) *bruparser.Document {
+	merged := &bruparser.Document{Sections: slices.Clone(existing.Sections)}
+	if index := methodSectionIndex(merged); index >= 0 {
+		if generatedIndex := methodSectionIndex(generated); generatedIndex >= 0 {
+			merged.Sections[index].Name = generated.Sections[generatedIndex].Name
+		}
+	}
+	for _, section := range generated.Sections {
+		existingSection, ok := merged.Section(section.Name)
+		switch {
+		case !ok:
+			merged.SetSection(section)
+		case section.IsText():
+			// Keep the existing body or script
+		case section.IsList():
+			existingSection.Entries = slices.Clone(existingSection.Entries)
+			for _, entry := range section.Entries {
+				if !slices.ContainsFunc(existingSection.Entries, func(kv bruparser.KeyValue) bool { return kv.Key == entry.Key }) {
+					existingSection.Entries = append(existingSection.Entries, entry)
+				}
+			}
+			merged.SetSection(existingSection)
+		default:
+			existingSection.Entries = slices.Clone(existingSection.Entries)
+			for _, entry := range section.Entries {
+				index := slices.IndexFunc(existingSection.Entries, func(kv bruparser.KeyValue) bool {
+					return kv.Key == entry.Key
+				})
+				switch {
+				case index < 0:
+					existingSection.Entries = append(existingSection.Entries, entry)
+				case existingSection.Entries[index].Value == "" || existingSection.Entries[index].Value == "none":
+					existingSection.Entries[index].Value = entry.Value
+				}
+			}
+			merged.SetSection(existingSection)
+		}
+	}
+	setURLQuery(merged)
+	return merged
+}
+
+// methodSectionIndex returns the index of the request section, e.g. "get", -1 if there is none
+func methodSectionIndex(document *bruparser.Document) int {
+	return slices.IndexFunc(document.Sections, func(section bruparser.Section) bool {
+		return slices.Contains(bruparser.HTTPMethods, section.Name)
+	})
+}
+
+// setURLQuery sets the query of the URL to the enabled entries of the "params:query" section, as Bruno does
+func setURLQuery(document *bruparser.Document) {
+	queryParams, ok := document.Section("params:query")
+	if !ok {
+		return
+	}
+	request, ok := document.Method()
+	if !ok {
+		return
+	}
+	urlPath, _, _ := strings.Cut(request.Value("url"), "?")
+	query := make([]string, 0)
+	for _, kv := range queryParams.EnabledEntries() {
+		query = append(query, kv.Key+"="+kv.Value)
+	}
+	if len(query) > 0 {
+		urlPath += "?" + strings.Join(query, "&")
+	}
+	request.SetValue("url", urlPath)
+	document.SetSection(request)
+}
+
+func fileOrDirExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
@@ -0,0 +1,70 @@
+package bruimporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+func TestMergeRequest_MethodChanged(t *testing.T) {
+	t.Parallel()
+	existing, err := bruparser.ParseDocument(strings.NewReader(`meta {
+  name: Save user
+  type: http
+  seq: 3
+}
+
+put {
+  url: {{baseUrl}}/users/1
+  body: none
+  auth: none
+}
+
+tests {
+  test("saved", () => {});
+}
+`))
+	require.NoError(t, err)
+	generated, err := bruparser.ParseDocument(strings.NewReader(`meta {
+  name: Save user
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: none
+}
+
+body:json {
+  {"name": "string"}
+}
+`))
+	require.NoError(t, err)
+
+	// The existing method section is replaced, rather than both methods being in the file
+	require.Equal(t, `meta {
+  name: Save user
+  type: http
+  seq: 3
+}
+
+post {
+  url: {{baseUrl}}/users/1
+  body: json
+  auth: none
+}
+
+body:json {
+  {"name": "string"}
+}
+
+tests {
+  test("saved", () => {});
+}
+`, string(MergeRequest(existing, generated).Bytes()))
+}
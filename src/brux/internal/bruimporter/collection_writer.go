@@ -0,0 +1,294 @@
+package bruimporter
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+const (
+	_environmentsDir    = "environments"
+	_folderFileName     = "folder.bru"
+	_collectionFileName = "collection.bru"
+	_collectionConfig   = "bruno.json"
+)
+
+// ImportResult lists the files written by an import
+type ImportResult struct {
+	Created []string
+	Updated []string
+	// Parts of the source that could not be converted, e.g. "Users/Login: auth type 'ntlm' is not supported"
+	Report []string
+}
+
+// _ImportedRequest is a request, or a folder.bru, to write to dir
+type _ImportedRequest struct {
+	dir      string
+	document *bruparser.Document
+}
+
+// _ImportedCollection is a collection converted from another tool, written by writeCollection
+type _ImportedCollection struct {
+	name     string
+	folders  []_ImportedRequest
+	requests []_ImportedRequest
+	// Environments by name
+	environments map[string]*bruparser.Document
+	// collection.bru, for the scripts of the collection, nil if there are none
+	collection *bruparser.Document
+	report     []string
+}
+
+// _RequestKeyFunc returns the key by which an imported request is matched with an existing request in dir,
+// empty if the document is not a request
+type _RequestKeyFunc func(dir string, document *bruparser.Document) string
+
+// addReport records that a part of item could not be converted
+func (c *_ImportedCollection) addReport(item string, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	if item != "" {
+		message = item + ": " + message
+	}
+	c.report = append(c.report, message)
+}
+
+// entry returns the key-value pair, it reports and skips values spanning multiple lines, as Bru files can't have them
+func (c *_ImportedCollection) entry(item string, key string, value string, enabled bool) (bruparser.KeyValue, bool) {
+	if strings.ContainsAny(key, "\r\n") || strings.ContainsAny(value, "\r\n") {
+		c.addReport(item, "'%s' is skipped as its value spans multiple lines", key)
+		return bruparser.KeyValue{}, false
+	}
+	return bruparser.KeyValue{Key: key, Value: value, Enabled: enabled}, true
+}
+
+// writeCollection writes the collection to outDir. Requests that exist already, matched by folder and name, and
+// environments keep the changes made to them, only the parts missing from them are added.
+func writeCollection(outDir string, collection *_ImportedCollection) (*ImportResult, error) {
+	result := &ImportResult{Report: collection.report}
+	name := collection.name
+	if name == "" {
+		name = filepath.Base(outDir)
+	}
+	if err := writeCollectionConfig(outDir, name, result); err != nil {
+		return nil, err
+	}
+	if collection.collection != nil {
+		if err := writeMergedDocument(filepath.Join(outDir, _collectionFileName), collection.collection, result); err != nil {
+			return nil, err
+		}
+	}
+
+	envNames := lo.Keys(collection.environments)
+	slices.Sort(envNames)
+	for _, envName := range envNames {
+		filePath := filepath.Join(outDir, _environmentsDir, getFileName(envName)+".bru")
+		if err := writeMergedDocument(filePath, collection.environments[envName], result); err != nil {
+			return nil, err
+		}
+	}
+	for _, folder := range collection.folders {
+		if err := writeMergedDocument(filepath.Join(folder.dir, _folderFileName), folder.document, result); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeRequests(outDir, collection.requests, getNamedRequestKey, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// environmentDocument returns an environment with the variables, and the names of the secret ones, whose values
+// are set in the ".env" file
+func environmentDocument(variables []bruparser.KeyValue, secretVars []string) *bruparser.Document {
+	document := &bruparser.Document{}
+	document.SetSection(bruparser.Section{Name: "vars", Entries: variables})
+	if len(secretVars) > 0 {
+		secrets := make([]bruparser.KeyValue, 0, len(secretVars))
+		for _, name := range secretVars {
+			secrets = append(secrets, bruparser.KeyValue{Key: name, Enabled: true})
+		}
+		document.SetSection(bruparser.Section{Name: "vars:secret", Entries: secrets})
+	}
+	return document
+}
+
+func writeCollectionConfig(outDir string, name string, result *ImportResult) error {
+	filePath := filepath.Join(outDir, _collectionConfig)
+	if fileOrDirExists(filePath) {
+		return nil
+	}
+	data, err := json.MarshalIndent(map[string]any{
+		"version": "1",
+		"name":    name,
+		"type":    "collection",
+		"ignore":  []string{"node_modules", ".git"},
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not encode collection config: %w", err)
+	}
+	if err := os.MkdirAll(outDir, 0o750); err != nil {
+		return fmt.Errorf("could not create directory: %w", err)
+	}
+	return writeFile(filePath, append(data, '\n'), result)
+}
+
+// writeMergedDocument writes the document, merged into the existing file if there is one
+func writeMergedDocument(filePath string, document *bruparser.Document, result *ImportResult) error {
+	existing, err := bruparser.ReadDocumentFile(filePath)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		document = MergeRequest(existing, document)
+	}
+	return writeFile(filePath, document.Bytes(), result)
+}
+
+// writeRequests writes the requests, merging them into the existing requests with the same key
+func writeRequests(outDir string, requests []_ImportedRequest, requestKey _RequestKeyFunc, result *ImportResult) error {
+	existing, err := getExistingRequests(outDir, requestKey)
+	if err != nil {
+		return err
+	}
+
+	for _, request := range requests {
+		key := requestKey(request.dir, request.document)
+		filePaths := existing[key]
+		if len(filePaths) == 0 {
+			if _, err := os.Stat(request.dir); os.IsNotExist(err) {
+				if err := writeFolder(request.dir, result); err != nil {
+					return err
+				}
+			}
+			filePath, err := WriteRequest(request.dir, request.document)
+			if err != nil {
+				return err
+			}
+			result.Created = append(result.Created, filePath)
+			continue
+		}
+
+		// Requests with the same key are matched in the order of their seq
+		filePath := filePaths[0]
+		existing[key] = filePaths[1:]
+		document, err := bruparser.ReadDocumentFile(filePath)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(filePath, MergeRequest(document, request.document).Bytes(), result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFolder(dir string, result *ImportResult) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("could not create directory: %w", err)
+	}
+	document := &bruparser.Document{}
+	document.SetSection(bruparser.Section{Name: "meta", Entries: []bruparser.KeyValue{
+		{Key: "name", Value: filepath.Base(dir), Enabled: true},
+	}})
+	return writeFile(filepath.Join(dir, _folderFileName), document.Bytes(), result)
+}
+
+// getExistingRequests returns the paths of the requests in dir and its sub-directories by their key,
+// sorted by seq
+func getExistingRequests(dir string, requestKey _RequestKeyFunc) (map[string][]string, error) {
+	type _ExistingRequest struct {
+		filePath string
+		seq      int
+	}
+	requests := make(map[string][]_ExistingRequest)
+	err := filepath.WalkDir(dir, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if entry.IsDir() && filePath != dir && bruparser.IsIgnoredDir(entry.Name()) {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || filepath.Ext(filePath) != ".bru" || entry.Name() == _folderFileName {
+			return nil
+		}
+		document, err := bruparser.ReadDocumentFile(filePath)
+		if err != nil {
+			log.Warn().
+				Err(err).
+				Str("filePath", filePath).
+				Msg("could not parse file, ignoring it")
+			return nil
+		}
+		if key := requestKey(filepath.Dir(filePath), document); key != "" {
+			meta, _ := document.Section("meta")
+			seq, _ := strconv.Atoi(meta.Value("seq"))
+			requests[key] = append(requests[key], _ExistingRequest{filePath: filePath, seq: seq})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read existing requests: %w", err)
+	}
+
+	filePaths := make(map[string][]string, len(requests))
+	for key, existing := range requests {
+		slices.SortStableFunc(existing, func(a, b _ExistingRequest) int {
+			return cmp.Compare(a.seq, b.seq)
+		})
+		filePaths[key] = lo.Map(existing, func(r _ExistingRequest, _ int) string { return r.filePath })
+	}
+	return filePaths, nil
+}
+
+// getOperationKey returns the method and URL path of the request, e.g. "get {{baseUrl}}/users/:id"
+func getOperationKey(_ string, document *bruparser.Document) string {
+	method, ok := document.Method()
+	if !ok {
+		return ""
+	}
+	urlPath, _, _ := strings.Cut(method.Value("url"), "?")
+	return method.Name + " " + urlPath
+}
+
+// getNamedRequestKey returns the directory and name of the request, e.g. "collection/users/Login"
+func getNamedRequestKey(dir string, document *bruparser.Document) string {
+	if _, ok := document.Method(); !ok {
+		return ""
+	}
+	meta, _ := document.Section("meta")
+	return filepath.Join(dir, meta.Value("name"))
+}
+
+// writeFile writes the file if its contents changed, and records it in result
+func writeFile(filePath string, data []byte, result *ImportResult) error {
+	existing, err := os.ReadFile(filePath)
+	if err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+		return fmt.Errorf("could not create directory: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return fmt.Errorf("could not write file: %w", err)
+	}
+	if existing != nil {
+		result.Updated = append(result.Updated, filePath)
+	} else {
+		result.Created = append(result.Created, filePath)
+	}
+	return nil
+}
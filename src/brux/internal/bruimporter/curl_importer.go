@@ -0,0 +1,400 @@
+package bruimporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+var (
+	ErrNotCurlCommand = errors.New("not a cURL command")
+	ErrMissingURL     = errors.New("missing URL")
+	ErrMissingValue   = errors.New("missing value of flag")
+)
+
+// Flags of curl that take a value, the ones not used for the request are ignored
+var _curlValueFlags = map[string]string{
+	"-X": "--request", "-H": "--header", "-d": "--data", "-F": "--form", "-u": "--user", "-b": "--cookie",
+	"-A": "--user-agent", "-e": "--referer", "-m": "--max-time", "-o": "--output", "-x": "--proxy",
+	"-w": "--write-out", "-c": "--cookie-jar", "-E": "--cert", "-T": "--upload-file", "-U": "--proxy-user",
+	"-K": "--config", "-r": "--range", "-Y": "--speed-limit", "-y": "--speed-time", "-z": "--time-cond",
+}
+
+// Boolean flags of curl used for the request, the other ones are ignored
+var _curlBoolFlags = map[string]string{"-G": "--get", "-I": "--head", "-k": "--insecure"}
+
+var _curlLongValueFlags = []string{
+	"--request", "--header", "--data", "--data-ascii", "--data-raw", "--data-binary", "--data-urlencode",
+	"--form", "--form-string", "--user", "--cookie", "--user-agent", "--referer", "--url", "--max-time",
+	"--output", "--proxy", "--write-out", "--cookie-jar", "--cert", "--key", "--cacert", "--upload-file",
+	"--connect-timeout", "--retry", "--retry-delay", "--retry-max-time", "--proxy-user", "--config", "--range",
+	"--resolve", "--interface", "--max-redirs", "--oauth2-bearer", "--cert-type", "--key-type", "--pass",
+	"--speed-limit", "--speed-time", "--time-cond",
+}
+
+// _CurlFlag is a flag of a curl command with its long name, e.g. "--request" of "-X", and its value if it is part
+// of the word, e.g. "POST" of "-XPOST" or "--request=POST"
+type _CurlFlag struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// _CurlRequest is the request described by the flags of a curl command
+type _CurlRequest struct {
+	method  string
+	url     string
+	headers []bruparser.KeyValue
+	data    []string
+	form    []bruparser.KeyValue
+	user    string
+	bearer  string
+	// Sends the data as the query string, via -G
+	dataAsQuery bool
+	head        bool
+	// Seconds, via --max-time
+	maxTime string
+}
+
+// ImportCurl converts a curl command, e.g. from "Copy as cURL" of the browsers, to a Bru request named name.
+// The name defaults to the last segment of the URL path. The seq is set by WriteRequest.
+func ImportCurl(command string, name string) (*bruparser.Document, error) {
+	words, err := splitShellWords(command)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse command: %w", err)
+	}
+	if len(words) > 0 && path.Base(words[0]) == "curl" {
+		words = words[1:]
+	}
+
+	request, err := parseCurlFlags(words)
+	if err != nil {
+		return nil, err
+	}
+	return request.document(name)
+}
+
+func parseCurlFlags(words []string) (*_CurlRequest, error) {
+	request := &_CurlRequest{}
+	for i := 0; i < len(words); i++ {
+		flags := splitCurlFlags(words[i])
+		if len(flags) == 0 {
+			if request.url != "" {
+				return nil, fmt.Errorf("%w: unexpected argument '%s'", ErrNotCurlCommand, words[i])
+			}
+			request.url = words[i]
+			continue
+		}
+		for _, flag := range flags {
+			if isCurlValueFlag(flag.name) && !flag.hasValue {
+				// Only the last flag of the word can take its value from the next word
+				i++
+				if i >= len(words) {
+					return nil, fmt.Errorf("%w: '%s'", ErrMissingValue, flag.name)
+				}
+				flag.value = words[i]
+			}
+			if err := request.setFlag(flag.name, flag.value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if request.url == "" {
+		return nil, ErrMissingURL
+	}
+	if len(request.data) > 0 && len(request.form) > 0 {
+		// curl refuses this as well
+		return nil, fmt.Errorf("%w: --data and --form can't be combined", ErrNotCurlCommand)
+	}
+	return request, nil
+}
+
+// splitCurlFlags returns the flags of the word, e.g. "--request=POST", "-XPOST" or the combined short flags of
+// "-sSLX POST" of which the last one takes the next word as its value. It returns none if the word is not a flag.
+func splitCurlFlags(word string) []_CurlFlag {
+	switch {
+	case strings.HasPrefix(word, "--"):
+		name, value, hasValue := strings.Cut(word, "=")
+		return []_CurlFlag{{name: name, value: value, hasValue: hasValue}}
+	case strings.HasPrefix(word, "-") && len(word) > 1:
+		flags := make([]_CurlFlag, 0, len(word)-1)
+		for i := 1; i < len(word); i++ {
+			shortFlag := "-" + word[i:i+1]
+			if name, ok := _curlValueFlags[shortFlag]; ok {
+				// The rest of the word is the value, e.g. "POST" of "-sXPOST"
+				return append(flags, _CurlFlag{name: name, value: word[i+1:], hasValue: i+1 < len(word)})
+			}
+			if name, ok := _curlBoolFlags[shortFlag]; ok {
+				shortFlag = name
+			}
+			flags = append(flags, _CurlFlag{name: shortFlag})
+		}
+		return flags
+	default:
+		return nil
+	}
+}
+
+func isCurlValueFlag(flag string) bool {
+	return slices.Contains(_curlLongValueFlags, flag)
+}
+
+func (r *_CurlRequest) setFlag(flag string, value string) error {
+	switch flag {
+	case "--url":
+		r.url = value
+	case "--request":
+		r.method = strings.ToUpper(value)
+	case "--header":
+		key, headerValue, _ := strings.Cut(value, ":")
+		r.headers = append(r.headers, bruparser.KeyValue{
+			Key: strings.TrimSpace(key), Value: strings.TrimSpace(headerValue), Enabled: true,
+		})
+	case "--data", "--data-ascii", "--data-binary":
+		data, err := readCurlData(value, flag != "--data-binary")
+		if err != nil {
+			return err
+		}
+		r.data = append(r.data, data)
+	case "--data-raw":
+		r.data = append(r.data, value)
+	case "--data-urlencode":
+		r.data = append(r.data, urlEncodeCurlData(value))
+	case "--form", "--form-string":
+		key, formValue, _ := strings.Cut(value, "=")
+		if flag == "--form" && strings.HasPrefix(formValue, "@") {
+			// Options like ";type=image/png" are not supported by Bru files
+			filePath, _, _ := strings.Cut(strings.TrimPrefix(formValue, "@"), ";")
+			formValue = "@file(" + filePath + ")"
+		}
+		r.form = append(r.form, bruparser.KeyValue{Key: key, Value: formValue, Enabled: true})
+	case "--user":
+		r.user = value
+	case "--oauth2-bearer":
+		r.bearer = value
+	case "--cookie":
+		if !strings.Contains(value, "=") {
+			log.Warn().
+				Str("cookieFile", value).
+				Msg("cookie files are not supported, ignoring them")
+			return nil
+		}
+		r.headers = append(r.headers, bruparser.KeyValue{Key: "Cookie", Value: value, Enabled: true})
+	case "--user-agent":
+		r.headers = append(r.headers, bruparser.KeyValue{Key: "User-Agent", Value: value, Enabled: true})
+	case "--referer":
+		r.headers = append(r.headers, bruparser.KeyValue{Key: "Referer", Value: value, Enabled: true})
+	case "--get":
+		r.dataAsQuery = true
+	case "--head":
+		r.head = true
+	case "--max-time":
+		r.maxTime = value
+	case "--insecure":
+		log.Warn().
+			Msg("--insecure is not part of Bru files, pass it to 'brux run' instead")
+	case "--compressed":
+		// Responses are decompressed by brux anyway
+	default:
+		log.Debug().
+			Str("flag", flag).
+			Msg("ignoring curl flag")
+	}
+	return nil
+}
+
+// readCurlData reads the data of "-d @file" from the file, stripping the newlines like curl does unless it is binary
+func readCurlData(value string, stripNewlines bool) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+
+	data, err := os.ReadFile(strings.TrimPrefix(value, "@"))
+	if err != nil {
+		return "", fmt.Errorf("could not read data file: %w", err)
+	}
+	if stripNewlines {
+		data = bytes.ReplaceAll(bytes.ReplaceAll(data, []byte("\r"), nil), []byte("\n"), nil)
+	}
+	return string(data), nil
+}
+
+// urlEncodeCurlData encodes the value of --data-urlencode, which is "content", "=content" or "name=content"
+func urlEncodeCurlData(value string) string {
+	name, content, found := strings.Cut(value, "=")
+	if !found {
+		return url.QueryEscape(value)
+	}
+	if name == "" {
+		return url.QueryEscape(content)
+	}
+	return name + "=" + url.QueryEscape(content)
+}
+
+func (r *_CurlRequest) getMethod() string {
+	switch {
+	case r.method != "":
+		return r.method
+	case r.head:
+		return "HEAD"
+	case r.dataAsQuery:
+		return "GET"
+	case len(r.data) > 0 || len(r.form) > 0:
+		return "POST"
+	default:
+		return "GET"
+	}
+}
+
+func (r *_CurlRequest) document(name string) (*bruparser.Document, error) {
+	requestURL := r.url
+	if !strings.Contains(requestURL, "://") {
+		// curl defaults to http
+		requestURL = "http://" + requestURL
+	}
+	if r.dataAsQuery && len(r.data) > 0 {
+		separator := "?"
+		if strings.Contains(requestURL, "?") {
+			separator = "&"
+		}
+		requestURL += separator + strings.Join(r.data, "&")
+	}
+	parsedURL, err := url.Parse(requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL '%s': %w", r.url, err)
+	}
+	if name == "" {
+		name = getRequestName(parsedURL)
+	}
+
+	method := strings.ToLower(r.getMethod())
+	if !slices.Contains(bruparser.HTTPMethods, method) {
+		return nil, fmt.Errorf("%w: unsupported method '%s'", ErrNotCurlCommand, r.method)
+	}
+
+	document := &bruparser.Document{}
+	document.SetSection(bruparser.Section{Name: "meta", Entries: []bruparser.KeyValue{
+		{Key: "name", Value: name, Enabled: true},
+		{Key: "type", Value: "http", Enabled: true},
+	}})
+	headers := slices.Clone(r.headers)
+	bodyType := "none"
+	if !r.dataAsQuery {
+		var bodySection *bruparser.Section
+		bodyType, bodySection = r.getBody(headers)
+		if bodySection != nil {
+			document.SetSection(*bodySection)
+		}
+		if bodyType == "multipart-form" {
+			// The boundary is set when sending the request
+			headers = slices.DeleteFunc(headers, func(kv bruparser.KeyValue) bool {
+				return strings.EqualFold(kv.Key, "Content-Type")
+			})
+		}
+	}
+	authMode := "none"
+	switch {
+	case r.user != "":
+		authMode = "basic"
+		username, password, _ := strings.Cut(r.user, ":")
+		document.SetSection(bruparser.Section{Name: "auth:basic", Entries: []bruparser.KeyValue{
+			{Key: "username", Value: username, Enabled: true},
+			{Key: "password", Value: password, Enabled: true},
+		}})
+	case r.bearer != "":
+		authMode = "bearer"
+		document.SetSection(bruparser.Section{Name: "auth:bearer", Entries: []bruparser.KeyValue{
+			{Key: "token", Value: r.bearer, Enabled: true},
+		}})
+	}
+	document.SetSection(bruparser.Section{Name: method, Entries: []bruparser.KeyValue{
+		{Key: "url", Value: requestURL, Enabled: true},
+		{Key: "body", Value: bodyType, Enabled: true},
+		{Key: "auth", Value: authMode, Enabled: true},
+	}})
+	if len(headers) > 0 {
+		document.SetSection(bruparser.Section{Name: "headers", Entries: headers})
+	}
+	if r.maxTime != "" {
+		seconds, err := strconv.ParseFloat(r.maxTime, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --max-time '%s': %w", r.maxTime, err)
+		}
+		document.SetSection(bruparser.Section{Name: "settings", Entries: []bruparser.KeyValue{
+			{Key: "timeout", Value: strconv.Itoa(int(seconds * 1000)), Enabled: true},
+		}})
+	}
+	return document, nil
+}
+
+// getBody returns the body type and section based on the content type, or the data if there is no content type
+func (r *_CurlRequest) getBody(headers []bruparser.KeyValue) (string, *bruparser.Section) {
+	if len(r.form) > 0 {
+		return "multipart-form", &bruparser.Section{Name: "body:multipart-form", Entries: r.form}
+	}
+	if len(r.data) == 0 {
+		return "none", nil
+	}
+
+	data := strings.Join(r.data, "&")
+	contentType := ""
+	for _, header := range headers {
+		if strings.EqualFold(header.Key, "Content-Type") {
+			contentType = strings.ToLower(header.Value)
+		}
+	}
+	switch {
+	case strings.Contains(contentType, "json") || (contentType == "" && json.Valid([]byte(data))):
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, []byte(data), "", "  "); err == nil {
+			data = buf.String()
+		}
+		return "json", &bruparser.Section{Name: "body:json", Text: data}
+	case strings.Contains(contentType, "xml"):
+		return "xml", &bruparser.Section{Name: "body:xml", Text: data}
+	case contentType == "" || strings.Contains(contentType, "x-www-form-urlencoded"):
+		if entries, ok := parseFormData(data); ok {
+			return "form-urlencoded", &bruparser.Section{Name: "body:form-urlencoded", Entries: entries}
+		}
+	}
+	return "text", &bruparser.Section{Name: "body:text", Text: data}
+}
+
+// parseFormData parses "a=1&b=2" in order, it returns false if the data is not URL encoded
+func parseFormData(data string) ([]bruparser.KeyValue, bool) {
+	entries := make([]bruparser.KeyValue, 0)
+	for _, pair := range strings.Split(data, "&") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found || strings.ContainsAny(pair, " \n") {
+			return nil, false
+		}
+		key, keyErr := url.QueryUnescape(key)
+		value, valueErr := url.QueryUnescape(value)
+		// Bru keys can't contain ":" and values can't span lines
+		if keyErr != nil || valueErr != nil || key == "" || strings.Contains(key, ":") || strings.Contains(value, "\n") {
+			return nil, false
+		}
+		entries = append(entries, bruparser.KeyValue{Key: key, Value: value, Enabled: true})
+	}
+	return entries, true
+}
+
+// getRequestName returns the last segment of the URL path, or the host for the root path
+func getRequestName(u *url.URL) string {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if name := segments[len(segments)-1]; name != "" {
+		return name
+	}
+	return u.Hostname()
+}
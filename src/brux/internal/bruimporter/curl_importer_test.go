@@ -0,0 +1,191 @@
+package bruimporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCurl(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name     string
+		command  string
+		expected string
+	}{
+		{
+			name: "browser copy as cURL",
+			command: `curl 'https://example.com/api/users?page=1' \
+  -H 'accept: application/json' \
+  -H 'content-type: application/json' \
+  -b 'session=abc; theme=dark' \
+  --data-raw $'{"name":"O\'Neil"}' \
+  --compressed`,
+			expected: `meta {
+  name: users
+  type: http
+}
+
+post {
+  url: https://example.com/api/users?page=1
+  body: json
+  auth: none
+}
+
+headers {
+  accept: application/json
+  content-type: application/json
+  Cookie: session=abc; theme=dark
+}
+
+body:json {
+  {
+    "name": "O'Neil"
+  }
+}
+`,
+		},
+		{
+			name:    "form data with basic auth and timeout",
+			command: `curl -XPUT -u bob:secret -d name=John%20Doe -d "id=1" -m 1.5 example.com`,
+			expected: `meta {
+  name: example.com
+  type: http
+}
+
+put {
+  url: http://example.com
+  body: form-urlencoded
+  auth: basic
+}
+
+auth:basic {
+  username: bob
+  password: secret
+}
+
+body:form-urlencoded {
+  name: John Doe
+  id: 1
+}
+
+settings {
+  timeout: 1500
+}
+`,
+		},
+		{
+			name:    "multipart form",
+			command: `curl -F 'file=@photo.png;type=image/png' -F title=Photo -H 'Content-Type: multipart/form-data; boundary=x' https://example.com/upload`,
+			expected: `meta {
+  name: upload
+  type: http
+}
+
+post {
+  url: https://example.com/upload
+  body: multipart-form
+  auth: none
+}
+
+body:multipart-form {
+  file: @file(photo.png)
+  title: Photo
+}
+`,
+		},
+		{
+			name:    "query data and text body",
+			command: `curl -sSG https://example.com/search --data-urlencode 'q=a b' -A brux`,
+			expected: `meta {
+  name: search
+  type: http
+}
+
+get {
+  url: https://example.com/search?q=a+b
+  body: none
+  auth: none
+}
+
+headers {
+  User-Agent: brux
+}
+`,
+		},
+		{
+			name:    "combined short flags",
+			command: `curl -sX POST -skH 'Accept: text/plain' -Y 100 -y5 https://example.com/jobs -d run`,
+			expected: `meta {
+  name: jobs
+  type: http
+}
+
+post {
+  url: https://example.com/jobs
+  body: text
+  auth: none
+}
+
+headers {
+  Accept: text/plain
+}
+
+body:text {
+  run
+}
+`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			document, err := ImportCurl(testCase.command, "")
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, string(document.Bytes()))
+		})
+	}
+}
+
+func TestSplitCurlFlags(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []_CurlFlag{{name: "-s"}, {name: "--request", value: "POST", hasValue: true}},
+		splitCurlFlags("-sXPOST"))
+	require.Equal(t, []_CurlFlag{{name: "-s"}, {name: "--insecure"}}, splitCurlFlags("-sk"))
+	require.Equal(t, []_CurlFlag{{name: "--speed-limit"}}, splitCurlFlags("-Y"))
+	require.Equal(t, []_CurlFlag{{name: "--data", value: "a=1", hasValue: true}}, splitCurlFlags("--data=a=1"))
+	require.Empty(t, splitCurlFlags("https://example.com"))
+
+	// Every short value flag takes a value
+	for shortFlag, name := range _curlValueFlags {
+		require.True(t, isCurlValueFlag(name), shortFlag)
+	}
+}
+
+func TestImportCurl_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, command := range []string{"curl -X POST", "curl 'https://example.com", "curl -H"} {
+		_, err := ImportCurl(command, "")
+		require.Error(t, err, command)
+	}
+}
+
+func TestWriteRequest(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "folder.bru"), []byte("meta {\n  name: users\n  seq: 9\n}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "list.bru"),
+		[]byte("meta {\n  name: list\n  type: http\n  seq: 4\n}\n\nget {\n  url: https://example.com\n}\n"), 0o600))
+
+	document, err := ImportCurl("curl https://example.com/users/list", "")
+	require.NoError(t, err)
+	filePath, err := WriteRequest(dir, document)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "list-2.bru"), filePath)
+
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "meta {\n  name: list\n  type: http\n  seq: 5\n}\n")
+}
@@ -0,0 +1,373 @@
+package bruimporter
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+var (
+	// Variables like "{{ _.baseUrl }}" or "{{ baseUrl }}", which are "{{baseUrl}}" in Bru files
+	_insomniaVariableRegex = regexp.MustCompile(`{{\s*(?:_\.)?([\w.-]+)\s*}}`)
+	// Template tags like "{% uuid 'v4' %}"
+	_insomniaTagRegex = regexp.MustCompile(`{%.*?%}`)
+)
+
+// _InsomniaExport is the subset of Insomnia v4 exports used to create requests.
+// Ref: https://docs.insomnia.rest/insomnia/import-export-data
+type _InsomniaExport struct {
+	Type         string               `json:"_type"`
+	ExportFormat int                  `json:"__export_format"`
+	Resources    []*_InsomniaResource `json:"resources"`
+}
+
+// _InsomniaResource is a workspace, a folder ("request_group"), a request or an environment
+type _InsomniaResource struct {
+	ID             string               `json:"_id"`
+	Type           string               `json:"_type"`
+	ParentID       string               `json:"parentId"`
+	Name           string               `json:"name"`
+	Description    string               `json:"description"`
+	MetaSortKey    float64              `json:"metaSortKey"`
+	Method         string               `json:"method"`
+	URL            string               `json:"url"`
+	Body           _InsomniaBody        `json:"body"`
+	Headers        []_InsomniaParameter `json:"headers"`
+	Parameters     []_InsomniaParameter `json:"parameters"`
+	PathParameters []_InsomniaParameter `json:"pathParameters"`
+	Authentication _InsomniaAuth        `json:"authentication"`
+	Data           map[string]any       `json:"data"`
+	PreRequest     string               `json:"preRequestScript"`
+	AfterResponse  string               `json:"afterResponseScript"`
+}
+
+type _InsomniaBody struct {
+	MimeType string               `json:"mimeType"`
+	Text     string               `json:"text"`
+	Params   []_InsomniaParameter `json:"params"`
+}
+
+type _InsomniaParameter struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	// "file" for files of multipart forms
+	Type     string `json:"type"`
+	FileName string `json:"fileName"`
+}
+
+type _InsomniaAuth struct {
+	// "basic", "bearer", "apikey" or "none", other types like "digest" are not supported.
+	// Empty means the auth of the parent folder.
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	Prefix   string `json:"prefix"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	// "header", "queryParams" or "cookie" for "apikey"
+	AddTo string `json:"addTo"`
+}
+
+// _InsomniaConverter converts the resources of an export, which reference their parent by ID
+type _InsomniaConverter struct {
+	collection *_ImportedCollection
+	children   map[string][]*_InsomniaResource
+}
+
+// ImportInsomnia creates a collection in outDir from an Insomnia v4 export, in JSON.
+// It creates a folder per folder, a request per HTTP request and an environment per sub-environment, with the
+// variables of the base environment. The variables and scripts are converted to Bruno's syntax and API.
+// The parts that could not be converted, e.g. template tags, are listed in the report of the result.
+func ImportInsomnia(data []byte, outDir string) (*ImportResult, error) {
+	export := &_InsomniaExport{}
+	if err := json.Unmarshal(data, export); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCollection, err)
+	}
+	if export.Type != "export" || export.ExportFormat != 4 {
+		return nil, fmt.Errorf("%w: not an Insomnia v4 export", ErrInvalidCollection)
+	}
+
+	converter := &_InsomniaConverter{
+		collection: &_ImportedCollection{environments: make(map[string]*bruparser.Document)},
+		children:   make(map[string][]*_InsomniaResource),
+	}
+	for _, resource := range export.Resources {
+		converter.children[resource.ParentID] = append(converter.children[resource.ParentID], resource)
+	}
+	for _, children := range converter.children {
+		slices.SortStableFunc(children, func(a, b *_InsomniaResource) int {
+			return cmp.Compare(a.MetaSortKey, b.MetaSortKey)
+		})
+	}
+
+	workspaces := lo.Filter(export.Resources, func(r *_InsomniaResource, _ int) bool { return r.Type == "workspace" })
+	if len(workspaces) == 0 {
+		return nil, fmt.Errorf("%w: the export has no workspace", ErrInvalidCollection)
+	}
+	converter.collection.name = workspaces[0].Name
+	for _, workspace := range workspaces {
+		dir := outDir
+		if len(workspaces) > 1 {
+			// Each workspace is a collection in Insomnia
+			dir = filepath.Join(outDir, getFileName(workspace.Name))
+		}
+		converter.convertResources(workspace.ID, dir, "", _InsomniaAuth{})
+		converter.convertEnvironments(workspace.ID)
+	}
+	return writeCollection(outDir, converter.collection)
+}
+
+// convertResources adds the folders and requests whose parent is parentID to dir, auth is the auth inherited from
+// the parents
+func (c *_InsomniaConverter) convertResources(parentID string, dir string, itemPath string, auth _InsomniaAuth) {
+	for _, resource := range c.children[parentID] {
+		name := childPath(itemPath, resource.Name)
+		resourceAuth := auth
+		if resource.Authentication.Type != "" {
+			resourceAuth = resource.Authentication
+		}
+
+		switch resource.Type {
+		case "request_group":
+			folderDir := filepath.Join(dir, getFileName(resource.Name))
+			document := &bruparser.Document{}
+			document.SetSection(bruparser.Section{Name: "meta", Entries: []bruparser.KeyValue{
+				{Key: "name", Value: resource.Name, Enabled: true},
+			}})
+			c.collection.setScript(document, name, "script:pre-request", resource.PreRequest)
+			c.collection.setScript(document, name, "tests", resource.AfterResponse)
+			setDocs(document, resource.Description)
+			c.collection.folders = append(c.collection.folders, _ImportedRequest{dir: folderDir, document: document})
+			c.convertResources(resource.ID, folderDir, name, resourceAuth)
+		case "request":
+			if document, ok := c.request(resource, name, resourceAuth); ok {
+				c.collection.requests = append(c.collection.requests, _ImportedRequest{dir: dir, document: document})
+			}
+		case "grpc_request", "websocket_request":
+			c.collection.addReport(name, "%s is not supported, the request is skipped", resource.Type)
+		}
+	}
+}
+
+func (c *_InsomniaConverter) request(resource *_InsomniaResource, name string, auth _InsomniaAuth) (*bruparser.Document, bool) {
+	method := strings.ToLower(resource.Method)
+	if !slices.Contains(bruparser.HTTPMethods, method) {
+		c.collection.addReport(name, "method '%s' is not supported, the request is skipped", resource.Method)
+		return nil, false
+	}
+
+	document := &bruparser.Document{}
+	document.SetSection(bruparser.Section{Name: "meta", Entries: []bruparser.KeyValue{
+		{Key: "name", Value: resource.Name, Enabled: true},
+		{Key: "type", Value: "http", Enabled: true},
+	}})
+	requestURL, query, _ := strings.Cut(c.template(name, resource.URL), "?")
+	// Insomnia appends the parameters to the query of the URL
+	queryParams := slices.Concat(queryEntries(query), c.entries(name, resource.Parameters))
+	pathParams := c.entries(name, resource.PathParameters)
+	headers := c.entries(name, resource.Headers)
+	bodyType := c.setBody(document, name, resource.Body)
+	authMode := c.setAuth(document, name, auth, &headers, &queryParams)
+
+	document.SetSection(bruparser.Section{Name: method, Entries: []bruparser.KeyValue{
+		{Key: "url", Value: requestURL, Enabled: true},
+		{Key: "body", Value: bodyType, Enabled: true},
+		{Key: "auth", Value: authMode, Enabled: true},
+	}})
+	if len(queryParams) > 0 {
+		document.SetSection(bruparser.Section{Name: "params:query", Entries: queryParams})
+	}
+	if len(pathParams) > 0 {
+		document.SetSection(bruparser.Section{Name: "params:path", Entries: pathParams})
+	}
+	if len(headers) > 0 {
+		document.SetSection(bruparser.Section{Name: "headers", Entries: headers})
+	}
+	c.collection.setScript(document, name, "script:pre-request", resource.PreRequest)
+	c.collection.setScript(document, name, "tests", resource.AfterResponse)
+	setDocs(document, resource.Description)
+	setURLQuery(document)
+	return document, true
+}
+
+func (c *_InsomniaConverter) entries(item string, parameters []_InsomniaParameter) []bruparser.KeyValue {
+	entries := make([]bruparser.KeyValue, 0, len(parameters))
+	for _, parameter := range parameters {
+		if parameter.Name == "" {
+			continue
+		}
+		value := c.template(item, parameter.Value)
+		if parameter.Type == "file" {
+			value = "@file(" + parameter.FileName + ")"
+		}
+		if entry, ok := c.collection.entry(item, c.template(item, parameter.Name), value, !parameter.Disabled); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// queryEntries returns the params of a query like "a=1&b=2", in order
+func queryEntries(query string) []bruparser.KeyValue {
+	entries := make([]bruparser.KeyValue, 0)
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		entries = append(entries, bruparser.KeyValue{Key: key, Value: value, Enabled: true})
+	}
+	return entries
+}
+
+// setBody adds the body section and returns the body type
+func (c *_InsomniaConverter) setBody(document *bruparser.Document, item string, body _InsomniaBody) string {
+	mimeType := strings.ToLower(body.MimeType)
+	switch {
+	case mimeType == "" && body.Text == "":
+		return "none"
+	case mimeType == "application/x-www-form-urlencoded":
+		document.SetSection(bruparser.Section{Name: "body:form-urlencoded", Entries: c.entries(item, body.Params)})
+		return "form-urlencoded"
+	case mimeType == "multipart/form-data":
+		document.SetSection(bruparser.Section{Name: "body:multipart-form", Entries: c.entries(item, body.Params)})
+		return "multipart-form"
+	case mimeType == "application/graphql":
+		// The text is the JSON sent, with the query and the variables
+		var graphQL struct {
+			Query     string `json:"query"`
+			Variables any    `json:"variables"`
+		}
+		if err := json.Unmarshal([]byte(body.Text), &graphQL); err != nil {
+			c.collection.addReport(item, "invalid GraphQL body, the body is skipped")
+			return "none"
+		}
+		document.SetSection(bruparser.Section{Name: "body:graphql", Text: c.template(item, graphQL.Query)})
+		if graphQL.Variables != nil {
+			document.SetSection(bruparser.Section{Name: "body:graphql:vars", Text: c.template(item, toJSON(graphQL.Variables))})
+		}
+		return "graphql"
+	case mimeType == "application/octet-stream":
+		c.collection.addReport(item, "file bodies are not supported, the body is skipped")
+		return "none"
+	case strings.Contains(mimeType, "json"):
+		document.SetSection(bruparser.Section{Name: "body:json", Text: c.template(item, body.Text)})
+		return "json"
+	case strings.Contains(mimeType, "xml"):
+		document.SetSection(bruparser.Section{Name: "body:xml", Text: c.template(item, body.Text)})
+		return "xml"
+	default:
+		document.SetSection(bruparser.Section{Name: "body:text", Text: c.template(item, body.Text)})
+		return "text"
+	}
+}
+
+// setAuth adds the auth section, or the header or query param of API keys, and returns the auth mode
+func (c *_InsomniaConverter) setAuth(document *bruparser.Document, item string, auth _InsomniaAuth,
+	headers *[]bruparser.KeyValue, queryParams *[]bruparser.KeyValue,
+) string {
+	if auth.Disabled {
+		return "none"
+	}
+
+	switch auth.Type {
+	case "", "none":
+		return "none"
+	case "basic":
+		document.SetSection(bruparser.Section{Name: "auth:basic", Entries: []bruparser.KeyValue{
+			{Key: "username", Value: c.template(item, auth.Username), Enabled: true},
+			{Key: "password", Value: c.template(item, auth.Password), Enabled: true},
+		}})
+		return "basic"
+	case "bearer":
+		if auth.Prefix != "" && !strings.EqualFold(auth.Prefix, "Bearer") {
+			*headers = append(*headers, bruparser.KeyValue{
+				Key: "Authorization", Value: auth.Prefix + " " + c.template(item, auth.Token), Enabled: true,
+			})
+			return "none"
+		}
+		document.SetSection(bruparser.Section{Name: "auth:bearer", Entries: []bruparser.KeyValue{
+			{Key: "token", Value: c.template(item, auth.Token), Enabled: true},
+		}})
+		return "bearer"
+	case "apikey":
+		kv := bruparser.KeyValue{Key: c.template(item, auth.Key), Value: c.template(item, auth.Value), Enabled: true}
+		switch auth.AddTo {
+		case "queryParams":
+			*queryParams = append(*queryParams, kv)
+		case "cookie":
+			*headers = append(*headers, bruparser.KeyValue{Key: "Cookie", Value: kv.Key + "=" + kv.Value, Enabled: true})
+		default:
+			*headers = append(*headers, kv)
+		}
+		return "none"
+	default:
+		c.collection.addReport(item, "auth type '%s' is not supported", auth.Type)
+		return "none"
+	}
+}
+
+// convertEnvironments adds an environment per sub-environment of the base environment of the workspace, with the
+// variables of both, or the base environment if there are no sub-environments
+func (c *_InsomniaConverter) convertEnvironments(workspaceID string) {
+	for _, base := range c.children[workspaceID] {
+		if base.Type != "environment" {
+			continue
+		}
+		environments := lo.Filter(c.children[base.ID], func(r *_InsomniaResource, _ int) bool {
+			return r.Type == "environment"
+		})
+		if len(environments) == 0 {
+			environments = []*_InsomniaResource{base}
+		}
+		for _, environment := range environments {
+			variables := make(map[string]string)
+			flattenVariables(variables, "", base.Data)
+			if environment != base {
+				flattenVariables(variables, "", environment.Data)
+			}
+			names := lo.Keys(variables)
+			slices.Sort(names)
+			entries := make([]bruparser.KeyValue, 0, len(names))
+			for _, name := range names {
+				item := "environment " + environment.Name
+				if entry, ok := c.collection.entry(item, name, c.template(item, variables[name]), true); ok {
+					entries = append(entries, entry)
+				}
+			}
+			c.collection.environments[environment.Name] = environmentDocument(entries, nil)
+		}
+	}
+}
+
+// flattenVariables adds the variables of data, the ones of nested objects are named like "api.url", as they are
+// used like "{{ _.api.url }}"
+func flattenVariables(variables map[string]string, prefix string, data map[string]any) {
+	for k, v := range data {
+		if object, ok := v.(map[string]any); ok {
+			flattenVariables(variables, prefix+k+".", object)
+			continue
+		}
+		variables[prefix+k] = formValue(v)
+	}
+}
+
+// template converts the variables of the Nunjucks template to Bru variables, it reports the template tags, which
+// can't be converted
+func (c *_InsomniaConverter) template(item string, str string) string {
+	if tags := _insomniaTagRegex.FindAllString(str, -1); len(tags) > 0 {
+		c.collection.addReport(item, "template tags are not supported: %s", strings.Join(tags, ", "))
+	}
+	return _insomniaVariableRegex.ReplaceAllString(str, "{{$1}}")
+}
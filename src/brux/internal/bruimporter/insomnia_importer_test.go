@@ -0,0 +1,138 @@
+package bruimporter
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testInsomniaExport = `{
+  "_type": "export",
+  "__export_format": 4,
+  "resources": [
+    {"_id": "wrk_1", "_type": "workspace", "parentId": null, "name": "Shop"},
+    {"_id": "env_base", "_type": "environment", "parentId": "wrk_1", "name": "Base Environment",
+      "data": {"baseUrl": "https://api.example.com", "api": {"version": "v2"}}},
+    {"_id": "env_dev", "_type": "environment", "parentId": "env_base", "name": "Dev",
+      "data": {"baseUrl": "http://localhost:3000", "token": "dev-token"}},
+    {"_id": "fld_1", "_type": "request_group", "parentId": "wrk_1", "name": "Orders", "metaSortKey": -1,
+      "authentication": {"type": "bearer", "token": "{{ _.token }}"}},
+    {"_id": "req_2", "_type": "request", "parentId": "fld_1", "name": "Create order", "metaSortKey": 2,
+      "method": "POST", "url": "{{ _.baseUrl }}/{{ _.api.version }}/orders",
+      "body": {"mimeType": "application/json", "text": "{\"id\": \"{% uuid 'v4' %}\"}"},
+      "headers": [{"name": "Content-Type", "value": "application/json"}],
+      "authentication": {}},
+    {"_id": "req_1", "_type": "request", "parentId": "fld_1", "name": "List orders", "metaSortKey": 1,
+      "method": "GET", "url": "{{ _.baseUrl }}/orders?sort=desc",
+      "parameters": [{"name": "limit", "value": "10"}, {"name": "page", "value": "2", "disabled": true}],
+      "authentication": {"type": "apikey", "key": "X-Api-Key", "value": "{{ _.token }}", "addTo": "header"},
+      "afterResponseScript": "insomnia.test('ok', () => insomnia.expect(insomnia.response.code).to.equal(200));"},
+    {"_id": "req_3", "_type": "request", "parentId": "wrk_1", "name": "Login", "method": "POST",
+      "url": "{{ _.baseUrl }}/login",
+      "body": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "user", "value": "admin"}]},
+      "authentication": {"type": "ntlm"}},
+    {"_id": "ws_1", "_type": "websocket_request", "parentId": "wrk_1", "name": "Events"}
+  ]
+}`
+
+func TestImportInsomnia(t *testing.T) {
+	t.Parallel()
+	outDir := t.TempDir()
+	result, err := ImportInsomnia([]byte(_testInsomniaExport), outDir)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		filepath.Join(outDir, "bruno.json"),
+		filepath.Join(outDir, "environments", "Dev.bru"),
+		filepath.Join(outDir, "Orders", "folder.bru"),
+		filepath.Join(outDir, "Orders", "List orders.bru"),
+		filepath.Join(outDir, "Orders", "Create order.bru"),
+		filepath.Join(outDir, "Login.bru"),
+	}, result.Created)
+	require.Equal(t, []string{
+		"Orders/Create order: template tags are not supported: {% uuid 'v4' %}",
+		"Login: auth type 'ntlm' is not supported",
+		"Events: websocket_request is not supported, the request is skipped",
+	}, result.Report)
+
+	requireFile(t, filepath.Join(outDir, "environments", "Dev.bru"), `vars {
+  api.version: v2
+  baseUrl: http://localhost:3000
+  token: dev-token
+}
+`)
+	requireFile(t, filepath.Join(outDir, "Orders", "List orders.bru"), `meta {
+  name: List orders
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/orders?sort=desc&limit=10
+  body: none
+  auth: none
+}
+
+params:query {
+  sort: desc
+  limit: 10
+  ~page: 2
+}
+
+headers {
+  X-Api-Key: {{token}}
+}
+
+tests {
+  test('ok', () => expect(res.getStatus()).to.equal(200));
+}
+`)
+	requireFile(t, filepath.Join(outDir, "Orders", "Create order.bru"), `meta {
+  name: Create order
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/{{api.version}}/orders
+  body: json
+  auth: bearer
+}
+
+headers {
+  Content-Type: application/json
+}
+
+auth:bearer {
+  token: {{token}}
+}
+
+body:json {
+  {"id": "{% uuid 'v4' %}"}
+}
+`)
+	requireFile(t, filepath.Join(outDir, "Login.bru"), `meta {
+  name: Login
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/login
+  body: form-urlencoded
+  auth: none
+}
+
+body:form-urlencoded {
+  user: admin
+}
+`)
+}
+
+func TestImportInsomnia_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := ImportInsomnia([]byte(`{"_type": "export", "__export_format": 3, "resources": []}`), t.TempDir())
+	require.ErrorIs(t, err, ErrInvalidCollection)
+	_, err = ImportInsomnia([]byte(`{"_type": "export", "__export_format": 4, "resources": []}`), t.TempDir())
+	require.ErrorIs(t, err, ErrInvalidCollection)
+}
@@ -0,0 +1,381 @@
+package bruimporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+	"github.com/ashishb/brux/src/brux/internal/openapi"
+)
+
+const _baseURLVariable = "{{baseUrl}}"
+
+var ErrInvalidSpec = openapi.ErrInvalidSpec
+
+// Path params like "{id}" of OpenAPI, which are ":id" in Bru files
+var _openAPIPathParamRegex = regexp.MustCompile(`{([^{}/]+)}`)
+
+// ImportOpenAPI creates a collection in outDir from an OpenAPI 3 or Swagger 2 spec, in YAML or JSON.
+// It creates a folder per tag, a request per operation and an environment per server.
+// Requests that exist already, matched by method and path, keep the changes made to them, e.g. to the scripts or
+// assertions, only the parts missing from them are added.
+func ImportOpenAPI(data []byte, outDir string) (*ImportResult, error) {
+	spec, err := parseOpenAPISpec(data)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	name := spec.Info.Title
+	if name == "" {
+		name = filepath.Base(outDir)
+	}
+	if err := writeCollectionConfig(outDir, name, result); err != nil {
+		return nil, err
+	}
+	environments := spec.environments()
+	envNames := lo.Keys(environments)
+	slices.Sort(envNames)
+	for _, envName := range envNames {
+		if err := writeEnvironment(filepath.Join(outDir, _environmentsDir, envName+".bru"), environments[envName],
+			spec.secretVariables(), result); err != nil {
+			return nil, err
+		}
+	}
+
+	requests := make([]_ImportedRequest, 0)
+	for _, pathOperation := range spec.PathOperations() {
+		dir := outDir
+		if tags := pathOperation.Operation.Tags; len(tags) > 0 {
+			dir = filepath.Join(outDir, getFileName(tags[0]))
+		}
+		document := spec.request(pathOperation.Path, pathOperation.PathItem, pathOperation.Method, pathOperation.Operation)
+		requests = append(requests, _ImportedRequest{dir: dir, document: document})
+	}
+	if err := writeRequests(outDir, requests, getOperationKey, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// environments returns the base URL by environment name of the servers
+func (s *_OpenAPISpec) environments() map[string]string {
+	environments := make(map[string]string)
+	if s.IsSwagger2() {
+		if s.Host == "" {
+			return environments
+		}
+		scheme := "https"
+		if len(s.Schemes) > 0 && !slices.Contains(s.Schemes, "https") {
+			scheme = s.Schemes[0]
+		}
+		environments[getFileName(s.Host)] = scheme + "://" + s.Host + strings.TrimSuffix(s.BasePath, "/")
+		return environments
+	}
+
+	for i, server := range s.Servers {
+		baseURL := server.URL
+		for name, variable := range server.Variables {
+			baseURL = strings.ReplaceAll(baseURL, "{"+name+"}", variable.Default)
+		}
+		envName := getFileName(server.Description)
+		if envName == "" {
+			if parsedURL, err := url.Parse(baseURL); err == nil && parsedURL.Host != "" {
+				envName = getFileName(parsedURL.Host)
+			} else {
+				envName = fmt.Sprintf("server-%d", i+1)
+			}
+		}
+		environments[envName] = strings.TrimSuffix(baseURL, "/")
+	}
+	return environments
+}
+
+// secretVariables returns the variables used by the auth of the requests, which are secrets
+func (s *_OpenAPISpec) secretVariables() []string {
+	secrets := make([]string, 0)
+	for _, scheme := range s.SecuritySchemes() {
+		for _, variable := range authVariables(scheme) {
+			if !slices.Contains(secrets, variable) {
+				secrets = append(secrets, variable)
+			}
+		}
+	}
+	slices.Sort(secrets)
+	return secrets
+}
+
+func authVariables(scheme *openapi.SecurityScheme) []string {
+	switch {
+	case scheme.Type == "basic" || (scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic")):
+		return []string{"username", "password"}
+	case scheme.Type == "apiKey":
+		return []string{"apiKey"}
+	default:
+		return []string{"token"}
+	}
+}
+
+func (s *_OpenAPISpec) request(path string, pathItem *openapi.PathItem, method string, operation *openapi.Operation) *bruparser.Document {
+	name := operation.Summary
+	if name == "" {
+		name = operation.OperationID
+	}
+	if name == "" {
+		name = strings.ToUpper(method) + " " + path
+	}
+
+	document := &bruparser.Document{}
+	document.SetSection(bruparser.Section{Name: "meta", Entries: []bruparser.KeyValue{
+		{Key: "name", Value: name, Enabled: true},
+		{Key: "type", Value: "http", Enabled: true},
+	}})
+
+	queryParams := make([]bruparser.KeyValue, 0)
+	pathParams := make([]bruparser.KeyValue, 0)
+	headers := make([]bruparser.KeyValue, 0)
+	bodyType := "none"
+	var formParams []bruparser.KeyValue
+	for _, parameter := range s.parameters(pathItem, operation) {
+		kv := bruparser.KeyValue{Key: parameter.Name, Value: s.parameterExample(parameter), Enabled: parameter.Required}
+		switch parameter.In {
+		case "query":
+			queryParams = append(queryParams, kv)
+		case "path":
+			kv.Enabled = true
+			// An empty value would request another path, the variable fails the run until it is defined
+			if kv.Value == "" {
+				kv.Value = "{{" + parameter.Name + "}}"
+			}
+			pathParams = append(pathParams, kv)
+		case "header":
+			headers = append(headers, kv)
+		case "body":
+			bodyType = "json"
+			document.SetSection(bruparser.Section{Name: "body:json", Text: toJSON(s.example(parameter.Schema))})
+		case "formData":
+			formParams = append(formParams, kv)
+		}
+	}
+	if len(formParams) > 0 {
+		bodyType = "form-urlencoded"
+		if slices.Contains(slices.Concat(operation.Consumes, s.Consumes), "multipart/form-data") {
+			bodyType = "multipart-form"
+		}
+		document.SetSection(bruparser.Section{Name: "body:" + bodyType, Entries: formParams})
+	}
+	if requestBody := s.ResolveRequestBody(operation.RequestBody); requestBody != nil {
+		bodyType = s.setRequestBody(document, requestBody)
+	}
+
+	authMode := s.setAuth(document, operation, &headers, &queryParams)
+	requestURL := _baseURLVariable + _openAPIPathParamRegex.ReplaceAllString(path, ":$1")
+	document.SetSection(bruparser.Section{Name: method, Entries: []bruparser.KeyValue{
+		{Key: "url", Value: requestURL, Enabled: true},
+		{Key: "body", Value: bodyType, Enabled: true},
+		{Key: "auth", Value: authMode, Enabled: true},
+	}})
+	if len(queryParams) > 0 {
+		document.SetSection(bruparser.Section{Name: "params:query", Entries: queryParams})
+	}
+	if len(pathParams) > 0 {
+		document.SetSection(bruparser.Section{Name: "params:path", Entries: pathParams})
+	}
+	if len(headers) > 0 {
+		document.SetSection(bruparser.Section{Name: "headers", Entries: headers})
+	}
+	if description := strings.TrimSpace(operation.Description); description != "" {
+		document.SetSection(bruparser.Section{Name: "docs", Text: description})
+	}
+	setURLQuery(document)
+	return document
+}
+
+// parameters returns the parameters of the path and the operation, the latter override the former
+func (s *_OpenAPISpec) parameters(pathItem *openapi.PathItem, operation *openapi.Operation) []*openapi.Parameter {
+	parameters := make([]*openapi.Parameter, 0)
+	for _, parameter := range append(slices.Clone(pathItem.Parameters), operation.Parameters...) {
+		parameter = s.ResolveParameter(parameter)
+		if parameter == nil {
+			continue
+		}
+		parameters = slices.DeleteFunc(parameters, func(p *openapi.Parameter) bool {
+			return p.Name == parameter.Name && p.In == parameter.In
+		})
+		parameters = append(parameters, parameter)
+	}
+	return parameters
+}
+
+// parameterExample returns the example or default value of the parameter, empty if there is none
+func (s *_OpenAPISpec) parameterExample(parameter *openapi.Parameter) string {
+	values := []any{parameter.Example, parameter.Default}
+	if len(parameter.Enum) > 0 {
+		values = append(values, parameter.Enum[0])
+	}
+	if schema := s.ResolveSchema(parameter.Schema); schema != nil {
+		values = append(values, schema.Example, schema.Default)
+		if len(schema.Enum) > 0 {
+			values = append(values, schema.Enum[0])
+		}
+	}
+	for _, value := range values {
+		if value != nil {
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// setRequestBody adds the body section for the first supported media type and returns the body type
+func (s *_OpenAPISpec) setRequestBody(document *bruparser.Document, requestBody *openapi.RequestBody) string {
+	mediaTypes := lo.Keys(requestBody.Content)
+	slices.Sort(mediaTypes)
+	for _, mediaType := range mediaTypes {
+		content := requestBody.Content[mediaType]
+		switch {
+		case strings.Contains(mediaType, "json"):
+			example := content.Example
+			exampleNames := lo.Keys(content.Examples)
+			slices.Sort(exampleNames)
+			for _, name := range exampleNames {
+				if example == nil && content.Examples[name] != nil {
+					example = content.Examples[name].Value
+				}
+			}
+			if example == nil {
+				example = s.example(content.Schema)
+			}
+			document.SetSection(bruparser.Section{Name: "body:json", Text: toJSON(example)})
+			return "json"
+		case mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data":
+			bodyType := "form-urlencoded"
+			if mediaType == "multipart/form-data" {
+				bodyType = "multipart-form"
+			}
+			entries := make([]bruparser.KeyValue, 0)
+			if schema := s.ResolveSchema(content.Schema); schema != nil {
+				names := lo.Keys(schema.Properties)
+				slices.Sort(names)
+				for _, name := range names {
+					entries = append(entries, bruparser.KeyValue{
+						Key: name, Value: formValue(s.example(schema.Properties[name])), Enabled: true,
+					})
+				}
+			}
+			document.SetSection(bruparser.Section{Name: "body:" + bodyType, Entries: entries})
+			return bodyType
+		case strings.Contains(mediaType, "xml"):
+			document.SetSection(bruparser.Section{Name: "body:xml", Text: ""})
+			return "xml"
+		case strings.HasPrefix(mediaType, "text/"):
+			document.SetSection(bruparser.Section{Name: "body:text", Text: ""})
+			return "text"
+		}
+	}
+	return "none"
+}
+
+// setAuth adds the auth of the first security requirement of the operation, or of the spec, and returns the auth mode
+func (s *_OpenAPISpec) setAuth(document *bruparser.Document, operation *openapi.Operation,
+	headers *[]bruparser.KeyValue, queryParams *[]bruparser.KeyValue,
+) string {
+	security := operation.Security
+	if security == nil {
+		security = s.Security
+	}
+	for _, requirement := range security {
+		schemeNames := lo.Keys(requirement)
+		slices.Sort(schemeNames)
+		for _, schemeName := range schemeNames {
+			scheme, ok := s.SecuritySchemes()[schemeName]
+			if !ok {
+				continue
+			}
+			switch {
+			case scheme.Type == "basic" || (scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic")):
+				document.SetSection(bruparser.Section{Name: "auth:basic", Entries: []bruparser.KeyValue{
+					{Key: "username", Value: "{{username}}", Enabled: true},
+					{Key: "password", Value: "{{password}}", Enabled: true},
+				}})
+				return "basic"
+			case scheme.Type == "apiKey" && scheme.In == "header":
+				*headers = append(*headers, bruparser.KeyValue{Key: scheme.Name, Value: "{{apiKey}}", Enabled: true})
+				return "none"
+			case scheme.Type == "apiKey" && scheme.In == "query":
+				*queryParams = append(*queryParams, bruparser.KeyValue{Key: scheme.Name, Value: "{{apiKey}}", Enabled: true})
+				return "none"
+			case scheme.Type == "http" || scheme.Type == "oauth2" || scheme.Type == "openIdConnect":
+				document.SetSection(bruparser.Section{Name: "auth:bearer", Entries: []bruparser.KeyValue{
+					{Key: "token", Value: "{{token}}", Enabled: true},
+				}})
+				return "bearer"
+			}
+		}
+	}
+	return "none"
+}
+
+// formValue returns the value of a form field, arrays and objects are encoded as JSON
+func formValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toJSON(value any) string {
+	if value == nil {
+		return "{}"
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Msg("could not encode example as JSON")
+		return "{}"
+	}
+	return string(data)
+}
+
+// writeEnvironment sets the base URL of the environment, keeping its other variables if it exists
+func writeEnvironment(filePath string, baseURL string, secretVars []string, result *ImportResult) error {
+	document, err := bruparser.ReadDocumentFile(filePath)
+	if err != nil {
+		return err
+	}
+	if document == nil {
+		document = &bruparser.Document{}
+	}
+
+	vars, _ := document.Section("vars")
+	vars.Name = "vars"
+	vars.SetValue("baseUrl", baseURL)
+	document.SetSection(vars)
+	secrets, _ := document.Section("vars:secret")
+	secrets.Name = "vars:secret"
+	for _, name := range secretVars {
+		if !slices.ContainsFunc(secrets.Entries, func(kv bruparser.KeyValue) bool { return kv.Key == name }) {
+			secrets.Entries = append(secrets.Entries, bruparser.KeyValue{Key: name, Enabled: true})
+		}
+	}
+	if len(secrets.Entries) > 0 {
+		document.SetSection(secrets)
+	}
+	return writeFile(filePath, document.Bytes(), result)
+}
@@ -0,0 +1,421 @@
+package bruimporter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testOpenAPISpec = `openapi: 3.0.3
+info:
+  title: Pet Store
+servers:
+  - url: https://api.example.com/v1
+    description: Production
+  - url: http://localhost:{port}
+    variables:
+      port:
+        default: "8080"
+security:
+  - bearerAuth: []
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema:
+          type: integer
+          example: 42
+    get:
+      summary: Get pet
+      tags: [pets]
+      parameters:
+        - name: fields
+          in: query
+          schema:
+            type: string
+        - name: verbose
+          in: query
+          required: true
+          schema:
+            type: boolean
+            default: false
+  /pets:
+    post:
+      summary: Create pet
+      description: Creates a pet.
+      tags: [pets]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+  /health:
+    get:
+      operationId: health
+      security: []
+components:
+  securitySchemes:
+    bearerAuth:
+      type: http
+      scheme: bearer
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Rex
+        tags:
+          type: array
+          items:
+            type: string
+`
+
+func TestImportOpenAPI(t *testing.T) {
+	t.Parallel()
+	outDir := t.TempDir()
+	result, err := ImportOpenAPI([]byte(_testOpenAPISpec), outDir)
+	require.NoError(t, err)
+	require.Empty(t, result.Updated)
+	require.ElementsMatch(t, []string{
+		filepath.Join(outDir, "bruno.json"),
+		filepath.Join(outDir, "environments", "Production.bru"),
+		filepath.Join(outDir, "environments", "localhost-8080.bru"),
+		filepath.Join(outDir, "health.bru"),
+		filepath.Join(outDir, "pets", "folder.bru"),
+		filepath.Join(outDir, "pets", "Create pet.bru"),
+		filepath.Join(outDir, "pets", "Get pet.bru"),
+	}, result.Created)
+
+	requireFile(t, filepath.Join(outDir, "environments", "Production.bru"), `vars {
+  baseUrl: https://api.example.com/v1
+}
+
+vars:secret [
+  token
+]
+`)
+	requireFile(t, filepath.Join(outDir, "pets", "Get pet.bru"), `meta {
+  name: Get pet
+  type: http
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/pets/:petId?verbose=false
+  body: none
+  auth: bearer
+}
+
+params:query {
+  ~fields: 
+  verbose: false
+}
+
+params:path {
+  petId: 42
+}
+
+auth:bearer {
+  token: {{token}}
+}
+`)
+	requireFile(t, filepath.Join(outDir, "pets", "Create pet.bru"), `meta {
+  name: Create pet
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/pets
+  body: json
+  auth: bearer
+}
+
+auth:bearer {
+  token: {{token}}
+}
+
+body:json {
+  {
+    "name": "Rex",
+    "tags": [
+      "string"
+    ]
+  }
+}
+
+docs {
+  Creates a pet.
+}
+`)
+	requireFile(t, filepath.Join(outDir, "health.bru"), `meta {
+  name: health
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/health
+  body: none
+  auth: none
+}
+`)
+}
+
+func TestImportOpenAPI_Swagger2(t *testing.T) {
+	t.Parallel()
+	spec := `{
+  "swagger": "2.0",
+  "info": {"title": "Users"},
+  "host": "api.example.com",
+  "basePath": "/v2",
+  "schemes": ["http", "https"],
+  "securityDefinitions": {"basic": {"type": "basic"}},
+  "paths": {
+    "/users": {
+      "post": {
+        "operationId": "createUser",
+        "security": [{"basic": []}],
+        "parameters": [
+          {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/User"}}
+        ]
+      }
+    }
+  },
+  "definitions": {
+    "User": {"type": "object", "properties": {"email": {"type": "string", "format": "email"}}}
+  }
+}`
+	outDir := t.TempDir()
+	_, err := ImportOpenAPI([]byte(spec), outDir)
+	require.NoError(t, err)
+
+	requireFile(t, filepath.Join(outDir, "environments", "api.example.com.bru"), `vars {
+  baseUrl: https://api.example.com/v2
+}
+
+vars:secret [
+  password,
+  username
+]
+`)
+	requireFile(t, filepath.Join(outDir, "createUser.bru"), `meta {
+  name: createUser
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: basic
+}
+
+auth:basic {
+  username: {{username}}
+  password: {{password}}
+}
+
+body:json {
+  {
+    "email": "user@example.com"
+  }
+}
+`)
+}
+
+func TestImportOpenAPI_PathParamWithoutExample(t *testing.T) {
+	t.Parallel()
+	spec := `openapi: 3.0.0
+paths:
+  /users/{userId}:
+    delete:
+      operationId: deleteUser
+      parameters:
+        - name: userId
+          in: path
+          required: true
+          schema:
+            type: string
+`
+	outDir := t.TempDir()
+	_, err := ImportOpenAPI([]byte(spec), outDir)
+	require.NoError(t, err)
+
+	// The variable fails the run until it is defined, rather than requesting "/users/"
+	requireFile(t, filepath.Join(outDir, "deleteUser.bru"), `meta {
+  name: deleteUser
+  type: http
+  seq: 1
+}
+
+delete {
+  url: {{baseUrl}}/users/:userId
+  body: none
+  auth: none
+}
+
+params:path {
+  userId: {{userId}}
+}
+`)
+}
+
+func TestImportOpenAPI_RecursiveSchema(t *testing.T) {
+	t.Parallel()
+	spec := `openapi: 3.0.0
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+        parent:
+          $ref: "#/components/schemas/Pet"
+        children:
+          type: array
+          items:
+            $ref: "#/components/schemas/Pet"
+        owner:
+          $ref: "#/components/schemas/Owner"
+    Owner:
+      type: object
+      properties:
+        pets:
+          type: array
+          items:
+            $ref: "#/components/schemas/Pet"
+`
+	outDir := t.TempDir()
+	_, err := ImportOpenAPI([]byte(spec), outDir)
+	require.NoError(t, err)
+
+	// The references back to Pet are not expanded again
+	requireFile(t, filepath.Join(outDir, "createPet.bru"), `meta {
+  name: createPet
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/pets
+  body: json
+  auth: none
+}
+
+body:json {
+  {
+    "children": [],
+    "name": "string",
+    "owner": {
+      "pets": []
+    },
+    "parent": null
+  }
+}
+`)
+}
+
+func TestImportOpenAPI_Reimport(t *testing.T) {
+	t.Parallel()
+	outDir := t.TempDir()
+	_, err := ImportOpenAPI([]byte(_testOpenAPISpec), outDir)
+	require.NoError(t, err)
+
+	// The user edits the request and the environment
+	filePath := filepath.Join(outDir, "pets", "Get pet.bru")
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	edited := strings.Replace(string(data), "petId: 42", "petId: 7", 1) +
+		"\nassert {\n  res.status: eq 200\n}\n\nscript:post-response {\n  bru.setVar(\"id\", res.body.id);\n}\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(edited), 0o600))
+	envPath := filepath.Join(outDir, "environments", "Production.bru")
+	require.NoError(t, os.WriteFile(envPath, []byte("vars {\n  baseUrl: https://old.example.com\n  user: me\n}\n"), 0o600))
+
+	// The spec gets a new query param
+	spec := strings.Replace(_testOpenAPISpec, "        - name: fields\n", `        - name: limit
+          in: query
+          required: true
+          example: 10
+        - name: fields
+`, 1)
+	result, err := ImportOpenAPI([]byte(spec), outDir)
+	require.NoError(t, err)
+	require.Empty(t, result.Created)
+	require.ElementsMatch(t, []string{filePath, envPath}, result.Updated)
+
+	requireFile(t, filePath, `meta {
+  name: Get pet
+  type: http
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/pets/:petId?verbose=false&limit=10
+  body: none
+  auth: bearer
+}
+
+params:query {
+  ~fields: 
+  verbose: false
+  limit: 10
+}
+
+params:path {
+  petId: 7
+}
+
+auth:bearer {
+  token: {{token}}
+}
+
+assert {
+  res.status: eq 200
+}
+
+script:post-response {
+  bru.setVar("id", res.body.id);
+}
+`)
+	requireFile(t, envPath, `vars {
+  baseUrl: https://api.example.com/v1
+  user: me
+}
+
+vars:secret [
+  token
+]
+`)
+}
+
+func TestImportOpenAPI_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := ImportOpenAPI([]byte("info:\n  title: not a spec\n"), t.TempDir())
+	require.ErrorIs(t, err, ErrInvalidSpec)
+	_, err = ImportOpenAPI([]byte("openapi: [3"), t.TempDir())
+	require.ErrorIs(t, err, ErrInvalidSpec)
+}
+
+func requireFile(t *testing.T, filePath string, expected string) {
+	t.Helper()
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(data))
+}
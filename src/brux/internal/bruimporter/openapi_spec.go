@@ -0,0 +1,110 @@
+package bruimporter
+
+import (
+	"slices"
+
+	"github.com/ashishb/brux/src/brux/internal/openapi"
+)
+
+// Depth of nested schemas up to which examples are generated, recursive references are stopped earlier
+const _maxExampleDepth = 8
+
+// _OpenAPISpec is a spec that requests are created from
+type _OpenAPISpec struct {
+	*openapi.Spec
+}
+
+// parseOpenAPISpec parses a YAML or JSON spec
+func parseOpenAPISpec(data []byte) (*_OpenAPISpec, error) {
+	spec, err := openapi.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return &_OpenAPISpec{Spec: spec}, nil
+}
+
+// example returns an example value of the schema, the example or default of the schema if it has one
+func (s *_OpenAPISpec) example(schema *openapi.Schema) any {
+	return s.exampleOf(schema, 0, nil)
+}
+
+// exampleOf returns an example value of the schema nested at depth. refs are the references expanded on the way to
+// the schema, a schema referencing one of them again, e.g. "Pet.parent", is recursive and its example is null.
+func (s *_OpenAPISpec) exampleOf(schema *openapi.Schema, depth int, refs []string) any {
+	for schema != nil && schema.Ref != "" {
+		if slices.Contains(refs, schema.Ref) {
+			return nil
+		}
+		// Copied, so that the siblings of the schema don't share the references
+		refs = append(slices.Clone(refs), schema.Ref)
+		schema = s.SchemaRef(schema.Ref)
+	}
+	if schema == nil || depth > _maxExampleDepth {
+		return nil
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case len(schema.Examples) > 0:
+		return schema.Examples[0]
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	case len(schema.AllOf) > 0:
+		merged := make(map[string]any)
+		for _, subSchema := range schema.AllOf {
+			if object, ok := s.exampleOf(subSchema, depth+1, refs).(map[string]any); ok {
+				for k, v := range object {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	case len(schema.OneOf) > 0:
+		return s.exampleOf(schema.OneOf[0], depth+1, refs)
+	case len(schema.AnyOf) > 0:
+		return s.exampleOf(schema.AnyOf[0], depth+1, refs)
+	}
+
+	switch schema.Type {
+	case "array":
+		item := s.exampleOf(schema.Items, depth+1, refs)
+		if item == nil {
+			return []any{}
+		}
+		return []any{item}
+	case "string":
+		return stringExample(schema.Format)
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "object", "":
+		object := make(map[string]any, len(schema.Properties))
+		for name, property := range schema.Properties {
+			object[name] = s.exampleOf(property, depth+1, refs)
+		}
+		return object
+	default:
+		return nil
+	}
+}
+
+func stringExample(format string) string {
+	switch format {
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "date":
+		return "2024-01-01"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
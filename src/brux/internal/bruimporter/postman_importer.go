@@ -0,0 +1,515 @@
+package bruimporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+var ErrInvalidCollection = errors.New("invalid collection")
+
+// _PostmanCollection is the subset of Postman v2.0 and v2.1 collections used to create requests.
+// Ref: https://schema.postman.com/collection/json/v2.1.0/draft-07/docs/index.html
+type _PostmanCollection struct {
+	Info     _PostmanInfo       `json:"info"`
+	Item     []*_PostmanItem    `json:"item"`
+	Auth     *_PostmanAuth      `json:"auth"`
+	Event    []_PostmanEvent    `json:"event"`
+	Variable []_PostmanVariable `json:"variable"`
+}
+
+type _PostmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// _PostmanItem is a folder, if it has items, or a request
+type _PostmanItem struct {
+	Name        string              `json:"name"`
+	Item        []*_PostmanItem     `json:"item"`
+	Request     *_PostmanRequest    `json:"request"`
+	Auth        *_PostmanAuth       `json:"auth"`
+	Event       []_PostmanEvent     `json:"event"`
+	Description _PostmanDescription `json:"description"`
+}
+
+type _PostmanRequest struct {
+	Method      string              `json:"method"`
+	Header      []_PostmanKeyValue  `json:"header"`
+	URL         _PostmanURL         `json:"url"`
+	Body        *_PostmanBody       `json:"body"`
+	Auth        *_PostmanAuth       `json:"auth"`
+	Description _PostmanDescription `json:"description"`
+}
+
+// UnmarshalJSON accepts a URL as well, which is a GET request
+func (r *_PostmanRequest) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		r.Method = "GET"
+		data = []byte(`{"url": ` + string(data) + `}`)
+	}
+	type _Request _PostmanRequest
+	if err := json.Unmarshal(data, (*_Request)(r)); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	return nil
+}
+
+type _PostmanURL struct {
+	Raw      string             `json:"raw"`
+	Query    []_PostmanKeyValue `json:"query"`
+	Variable []_PostmanKeyValue `json:"variable"`
+}
+
+// UnmarshalJSON accepts a string as well
+func (u *_PostmanURL) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		data = []byte(`{"raw": ` + string(data) + `}`)
+	}
+	type _URL _PostmanURL
+	if err := json.Unmarshal(data, (*_URL)(u)); err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	return nil
+}
+
+type _PostmanKeyValue struct {
+	Key      string        `json:"key"`
+	Value    _PostmanValue `json:"value"`
+	Disabled bool          `json:"disabled"`
+	// "text" or "file" for form data
+	Type string `json:"type"`
+	// Path, or paths, of the file of form data
+	Src any `json:"src"`
+}
+
+// _PostmanVariable is a variable of a collection, which can be disabled, or an environment, which can be enabled
+type _PostmanVariable struct {
+	Key      string        `json:"key"`
+	Value    _PostmanValue `json:"value"`
+	Disabled bool          `json:"disabled"`
+	Enabled  *bool         `json:"enabled"`
+	// "secret" for secrets
+	Type string `json:"type"`
+}
+
+func (v _PostmanVariable) isEnabled() bool {
+	return !v.Disabled && (v.Enabled == nil || *v.Enabled)
+}
+
+// _PostmanValue is a string, values like numbers are converted to strings
+type _PostmanValue string
+
+func (v *_PostmanValue) UnmarshalJSON(data []byte) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid value: %w", err)
+	}
+	*v = _PostmanValue(formValue(value))
+	return nil
+}
+
+// _PostmanDescription is a string, or an object with the string as "content"
+type _PostmanDescription string
+
+func (d *_PostmanDescription) UnmarshalJSON(data []byte) error {
+	var description struct {
+		Content string `json:"content"`
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`{`)) {
+		if err := json.Unmarshal(data, &description); err != nil {
+			return fmt.Errorf("invalid description: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &description.Content); err != nil {
+		return fmt.Errorf("invalid description: %w", err)
+	}
+	*d = _PostmanDescription(description.Content)
+	return nil
+}
+
+type _PostmanBody struct {
+	// "raw", "urlencoded", "formdata", "file" or "graphql"
+	Mode       string             `json:"mode"`
+	Raw        string             `json:"raw"`
+	URLEncoded []_PostmanKeyValue `json:"urlencoded"`
+	FormData   []_PostmanKeyValue `json:"formdata"`
+	GraphQL    struct {
+		Query     string        `json:"query"`
+		Variables _PostmanValue `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			// "json", "xml", "text", "html" or "javascript"
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+	Disabled bool `json:"disabled"`
+}
+
+// _PostmanAuth is the auth of a request, folder or collection, with the parameters of its type
+type _PostmanAuth struct {
+	// "noauth", "basic", "bearer", "apikey" or "inherit", other types like "digest" are not supported
+	Type   string
+	Params map[string]string
+}
+
+// UnmarshalJSON reads the parameters of the type, which are a list of key-value pairs in v2.1 and an object in v2.0
+func (a *_PostmanAuth) UnmarshalJSON(data []byte) error {
+	var auth map[string]json.RawMessage
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return fmt.Errorf("invalid auth: %w", err)
+	}
+	if authType, ok := auth["type"]; ok {
+		if err := json.Unmarshal(authType, &a.Type); err != nil {
+			return fmt.Errorf("invalid auth: %w", err)
+		}
+	}
+	a.Params = make(map[string]string)
+	params, ok := auth[a.Type]
+	if !ok {
+		return nil
+	}
+	var list []_PostmanKeyValue
+	if err := json.Unmarshal(params, &list); err == nil {
+		for _, kv := range list {
+			a.Params[kv.Key] = string(kv.Value)
+		}
+		return nil
+	}
+	var object map[string]_PostmanValue
+	if err := json.Unmarshal(params, &object); err != nil {
+		return fmt.Errorf("invalid auth: %w", err)
+	}
+	for k, v := range object {
+		a.Params[k] = string(v)
+	}
+	return nil
+}
+
+type _PostmanEvent struct {
+	// "prerequest" or "test"
+	Listen string `json:"listen"`
+	Script struct {
+		Exec any `json:"exec"`
+	} `json:"script"`
+	Disabled bool `json:"disabled"`
+}
+
+// script returns the lines of the script joined
+func (e _PostmanEvent) script() string {
+	switch exec := e.Script.Exec.(type) {
+	case string:
+		return exec
+	case []any:
+		return strings.Join(lo.Map(exec, func(line any, _ int) string { return formValue(line) }), "\n")
+	default:
+		return ""
+	}
+}
+
+// _PostmanEnvironment is an environment exported from Postman
+type _PostmanEnvironment struct {
+	Name   string             `json:"name"`
+	Values []_PostmanVariable `json:"values"`
+}
+
+// ImportPostman creates a collection in outDir from a Postman v2.0 or v2.1 collection, and the environments
+// exported from Postman.
+// It creates a folder per folder, a request per request and an environment per environment, with the variables of
+// the collection. The pre-request and test scripts are converted to Bruno's API.
+// The parts that could not be converted, e.g. auth types other than basic, bearer and API key, are listed in the
+// report of the result.
+func ImportPostman(data []byte, environments [][]byte, outDir string) (*ImportResult, error) {
+	postmanCollection := &_PostmanCollection{}
+	if err := json.Unmarshal(data, postmanCollection); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCollection, err)
+	}
+	if postmanCollection.Item == nil || !strings.Contains(postmanCollection.Info.Schema, "/v2.") {
+		return nil, fmt.Errorf("%w: not a Postman v2.0 or v2.1 collection", ErrInvalidCollection)
+	}
+
+	collection := &_ImportedCollection{
+		name:         postmanCollection.Info.Name,
+		environments: make(map[string]*bruparser.Document),
+	}
+	for _, envData := range environments {
+		environment := &_PostmanEnvironment{}
+		if err := json.Unmarshal(envData, environment); err != nil || environment.Name == "" {
+			return nil, fmt.Errorf("%w: not a Postman environment", ErrInvalidCollection)
+		}
+		collection.environments[environment.Name] = collection.postmanEnvironment(environment.Name,
+			postmanCollection.Variable, environment.Values)
+	}
+	if len(collection.environments) == 0 && len(postmanCollection.Variable) > 0 {
+		// The variables of the collection need an environment to be used by brux
+		collection.environments[collection.name] = collection.postmanEnvironment(collection.name,
+			postmanCollection.Variable, nil)
+	}
+
+	document := &bruparser.Document{}
+	collection.setPostmanScripts(document, "", postmanCollection.Event)
+	if len(document.Sections) > 0 {
+		collection.collection = document
+	}
+	collection.convertPostmanItems(postmanCollection.Item, outDir, "", postmanCollection.Auth)
+	return writeCollection(outDir, collection)
+}
+
+// postmanEnvironment returns an environment with the variables of the collection and the environment, the latter
+// take precedence. The values of secrets are not imported, they are set in the ".env" file.
+func (c *_ImportedCollection) postmanEnvironment(name string, collectionVars []_PostmanVariable,
+	envVars []_PostmanVariable,
+) *bruparser.Document {
+	variables := make([]bruparser.KeyValue, 0)
+	secretVars := make([]string, 0)
+	for _, variable := range slices.Concat(collectionVars, envVars) {
+		secretVars = slices.DeleteFunc(secretVars, func(key string) bool { return key == variable.Key })
+		if variable.Type == "secret" {
+			variables = slices.DeleteFunc(variables, func(kv bruparser.KeyValue) bool { return kv.Key == variable.Key })
+			secretVars = append(secretVars, variable.Key)
+			continue
+		}
+		kv, ok := c.entry("environment "+name, variable.Key, string(variable.Value), variable.isEnabled())
+		if !ok {
+			continue
+		}
+		if index := slices.IndexFunc(variables, func(v bruparser.KeyValue) bool { return v.Key == kv.Key }); index >= 0 {
+			variables[index] = kv
+		} else {
+			variables = append(variables, kv)
+		}
+	}
+	if len(secretVars) > 0 {
+		c.addReport("environment "+name, "the values of the secrets %s are not imported, set them in the .env file",
+			strings.Join(secretVars, ", "))
+	}
+	return environmentDocument(variables, secretVars)
+}
+
+// convertPostmanItems adds the folders and requests of items to dir, auth is the auth inherited from the parents
+func (c *_ImportedCollection) convertPostmanItems(items []*_PostmanItem, dir string, itemPath string,
+	auth *_PostmanAuth,
+) {
+	for _, item := range items {
+		name := childPath(itemPath, item.Name)
+		itemAuth := auth
+		if item.Auth != nil && item.Auth.Type != "inherit" {
+			itemAuth = item.Auth
+		}
+
+		if item.Request == nil {
+			folderDir := filepath.Join(dir, getFileName(item.Name))
+			document := &bruparser.Document{}
+			document.SetSection(bruparser.Section{Name: "meta", Entries: []bruparser.KeyValue{
+				{Key: "name", Value: item.Name, Enabled: true},
+			}})
+			c.setPostmanScripts(document, name, item.Event)
+			setDocs(document, string(item.Description))
+			c.folders = append(c.folders, _ImportedRequest{dir: folderDir, document: document})
+			c.convertPostmanItems(item.Item, folderDir, name, itemAuth)
+			continue
+		}
+
+		if item.Request.Auth != nil && item.Request.Auth.Type != "inherit" {
+			itemAuth = item.Request.Auth
+		}
+		document, ok := c.postmanRequest(item, name, itemAuth)
+		if ok {
+			c.requests = append(c.requests, _ImportedRequest{dir: dir, document: document})
+		}
+	}
+}
+
+func (c *_ImportedCollection) postmanRequest(item *_PostmanItem, name string, auth *_PostmanAuth) (*bruparser.Document, bool) {
+	request := item.Request
+	method := strings.ToLower(request.Method)
+	if method == "" {
+		method = "get"
+	}
+	if !slices.Contains(bruparser.HTTPMethods, method) {
+		c.addReport(name, "method '%s' is not supported, the request is skipped", request.Method)
+		return nil, false
+	}
+
+	document := &bruparser.Document{}
+	document.SetSection(bruparser.Section{Name: "meta", Entries: []bruparser.KeyValue{
+		{Key: "name", Value: item.Name, Enabled: true},
+		{Key: "type", Value: "http", Enabled: true},
+	}})
+	queryParams := c.postmanEntries(name, request.URL.Query)
+	pathParams := c.postmanEntries(name, request.URL.Variable)
+	headers := c.postmanEntries(name, request.Header)
+	bodyType := c.setPostmanBody(document, name, request.Body)
+	authMode := c.setPostmanAuth(document, name, auth, &headers, &queryParams)
+
+	document.SetSection(bruparser.Section{Name: method, Entries: []bruparser.KeyValue{
+		{Key: "url", Value: request.URL.Raw, Enabled: true},
+		{Key: "body", Value: bodyType, Enabled: true},
+		{Key: "auth", Value: authMode, Enabled: true},
+	}})
+	if len(queryParams) > 0 {
+		document.SetSection(bruparser.Section{Name: "params:query", Entries: queryParams})
+	}
+	if len(pathParams) > 0 {
+		document.SetSection(bruparser.Section{Name: "params:path", Entries: pathParams})
+	}
+	if len(headers) > 0 {
+		document.SetSection(bruparser.Section{Name: "headers", Entries: headers})
+	}
+	c.setPostmanScripts(document, name, item.Event)
+	setDocs(document, string(request.Description))
+	setURLQuery(document)
+	return document, true
+}
+
+func (c *_ImportedCollection) postmanEntries(item string, kvs []_PostmanKeyValue) []bruparser.KeyValue {
+	entries := make([]bruparser.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		if kv.Key == "" {
+			continue
+		}
+		if entry, ok := c.entry(item, kv.Key, string(kv.Value), !kv.Disabled); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// setPostmanBody adds the body section and returns the body type
+func (c *_ImportedCollection) setPostmanBody(document *bruparser.Document, item string, body *_PostmanBody) string {
+	if body == nil || body.Disabled {
+		return "none"
+	}
+
+	switch body.Mode {
+	case "raw":
+		if body.Raw == "" {
+			return "none"
+		}
+		bodyType := "text"
+		switch language := body.Options.Raw.Language; {
+		case language == "json" || (language == "" && json.Valid([]byte(body.Raw))):
+			bodyType = "json"
+		case language == "xml":
+			bodyType = "xml"
+		}
+		document.SetSection(bruparser.Section{Name: "body:" + bodyType, Text: body.Raw})
+		return bodyType
+	case "urlencoded":
+		document.SetSection(bruparser.Section{
+			Name: "body:form-urlencoded", Entries: c.postmanEntries(item, body.URLEncoded),
+		})
+		return "form-urlencoded"
+	case "formdata":
+		entries := make([]bruparser.KeyValue, 0, len(body.FormData))
+		for _, kv := range body.FormData {
+			value := string(kv.Value)
+			if kv.Type == "file" {
+				files := lo.Compact(lo.Map(toSlice(kv.Src), func(src any, _ int) string { return formValue(src) }))
+				if len(files) == 0 {
+					c.addReport(item, "form field '%s' has no file, it is skipped", kv.Key)
+					continue
+				}
+				value = "@file(" + strings.Join(files, "|") + ")"
+			}
+			if entry, ok := c.entry(item, kv.Key, value, !kv.Disabled); ok {
+				entries = append(entries, entry)
+			}
+		}
+		document.SetSection(bruparser.Section{Name: "body:multipart-form", Entries: entries})
+		return "multipart-form"
+	case "graphql":
+		document.SetSection(bruparser.Section{Name: "body:graphql", Text: body.GraphQL.Query})
+		if variables := strings.TrimSpace(string(body.GraphQL.Variables)); variables != "" {
+			document.SetSection(bruparser.Section{Name: "body:graphql:vars", Text: variables})
+		}
+		return "graphql"
+	default:
+		c.addReport(item, "body mode '%s' is not supported, the body is skipped", body.Mode)
+		return "none"
+	}
+}
+
+// setPostmanAuth adds the auth section, or the header or query param of API keys, and returns the auth mode
+func (c *_ImportedCollection) setPostmanAuth(document *bruparser.Document, item string, auth *_PostmanAuth,
+	headers *[]bruparser.KeyValue, queryParams *[]bruparser.KeyValue,
+) string {
+	if auth == nil {
+		return "none"
+	}
+
+	switch auth.Type {
+	case "noauth", "inherit":
+		return "none"
+	case "basic":
+		document.SetSection(bruparser.Section{Name: "auth:basic", Entries: []bruparser.KeyValue{
+			{Key: "username", Value: auth.Params["username"], Enabled: true},
+			{Key: "password", Value: auth.Params["password"], Enabled: true},
+		}})
+		return "basic"
+	case "bearer":
+		document.SetSection(bruparser.Section{Name: "auth:bearer", Entries: []bruparser.KeyValue{
+			{Key: "token", Value: auth.Params["token"], Enabled: true},
+		}})
+		return "bearer"
+	case "apikey":
+		kv := bruparser.KeyValue{Key: auth.Params["key"], Value: auth.Params["value"], Enabled: true}
+		if auth.Params["in"] == "query" {
+			*queryParams = append(*queryParams, kv)
+		} else {
+			*headers = append(*headers, kv)
+		}
+		return "none"
+	default:
+		c.addReport(item, "auth type '%s' is not supported", auth.Type)
+		return "none"
+	}
+}
+
+// setPostmanScripts adds the pre-request and test scripts of the events
+func (c *_ImportedCollection) setPostmanScripts(document *bruparser.Document, item string, events []_PostmanEvent) {
+	for _, event := range events {
+		if event.Disabled {
+			continue
+		}
+		switch event.Listen {
+		case "prerequest":
+			c.setScript(document, item, "script:pre-request", event.script())
+		case "test":
+			c.setScript(document, item, "tests", event.script())
+		}
+	}
+}
+
+func setDocs(document *bruparser.Document, docs string) {
+	if docs = strings.Trim(docs, "\r\n"); strings.TrimSpace(docs) != "" {
+		document.SetSection(bruparser.Section{Name: "docs", Text: docs})
+	}
+}
+
+// childPath returns the path of an item in the collection, e.g. "Users/Login"
+func childPath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "/" + name
+}
+
+func toSlice(value any) []any {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return v
+	default:
+		return []any{v}
+	}
+}
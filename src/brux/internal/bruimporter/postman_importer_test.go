@@ -0,0 +1,235 @@
+package bruimporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testPostmanCollection = `{
+  "info": {
+    "name": "Users API",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [{"key": "baseUrl", "value": "https://api.example.com"}, {"key": "limit", "value": 10}],
+  "item": [
+    {
+      "name": "Users",
+      "item": [
+        {
+          "name": "Get user",
+          "event": [
+            {"listen": "test", "script": {"exec": [
+              "pm.test(\"ok\", function () {",
+              "  pm.response.to.have.status(200);",
+              "  pm.environment.set(\"name\", pm.response.json().name);",
+              "});",
+              "pm.sendRequest(\"https://example.com\");"
+            ]}}
+          ],
+          "request": {
+            "method": "GET",
+            "header": [{"key": "Accept", "value": "application/json"}, {"key": "X-Debug", "value": "1", "disabled": true}],
+            "url": {
+              "raw": "{{baseUrl}}/users/:id?limit={{limit}}",
+              "query": [{"key": "limit", "value": "{{limit}}"}, {"key": "page", "value": "2", "disabled": true}],
+              "variable": [{"key": "id", "value": "42"}]
+            }
+          }
+        },
+        {
+          "name": "Create user",
+          "request": {
+            "method": "POST",
+            "auth": {"type": "basic", "basic": [{"key": "username", "value": "admin"}, {"key": "password", "value": "{{password}}"}]},
+            "body": {"mode": "raw", "raw": "{\n  \"name\": \"John\"\n}", "options": {"raw": {"language": "json"}}},
+            "url": "{{baseUrl}}/users",
+            "description": "Creates a user."
+          }
+        }
+      ]
+    },
+    {
+      "name": "Upload",
+      "request": {
+        "method": "PUT",
+        "auth": {"type": "digest", "digest": [{"key": "username", "value": "admin"}]},
+        "body": {"mode": "formdata", "formdata": [
+          {"key": "avatar", "type": "file", "src": "/tmp/avatar.png"},
+          {"key": "note", "value": "hi", "type": "text"}
+        ]},
+        "url": {"raw": "{{baseUrl}}/upload"}
+      }
+    }
+  ]
+}`
+
+const _testPostmanEnvironment = `{
+  "name": "Dev",
+  "values": [
+    {"key": "baseUrl", "value": "http://localhost:8080", "enabled": true},
+    {"key": "password", "value": "hunter2", "type": "secret", "enabled": true}
+  ]
+}`
+
+func TestImportPostman(t *testing.T) {
+	t.Parallel()
+	outDir := t.TempDir()
+	result, err := ImportPostman([]byte(_testPostmanCollection), [][]byte{[]byte(_testPostmanEnvironment)}, outDir)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		filepath.Join(outDir, "bruno.json"),
+		filepath.Join(outDir, "environments", "Dev.bru"),
+		filepath.Join(outDir, "Users", "folder.bru"),
+		filepath.Join(outDir, "Users", "Get user.bru"),
+		filepath.Join(outDir, "Users", "Create user.bru"),
+		filepath.Join(outDir, "Upload.bru"),
+	}, result.Created)
+	require.Equal(t, []string{
+		"environment Dev: the values of the secrets password are not imported, set them in the .env file",
+		"Users/Get user: 'tests' uses APIs that could not be converted: pm.sendRequest",
+		"Upload: auth type 'digest' is not supported",
+	}, result.Report)
+
+	requireFile(t, filepath.Join(outDir, "environments", "Dev.bru"), `vars {
+  baseUrl: http://localhost:8080
+  limit: 10
+}
+
+vars:secret [
+  password
+]
+`)
+	requireFile(t, filepath.Join(outDir, "Users", "Get user.bru"), `meta {
+  name: Get user
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/users/:id?limit={{limit}}
+  body: none
+  auth: bearer
+}
+
+params:query {
+  limit: {{limit}}
+  ~page: 2
+}
+
+params:path {
+  id: 42
+}
+
+headers {
+  Accept: application/json
+  ~X-Debug: 1
+}
+
+auth:bearer {
+  token: {{token}}
+}
+
+tests {
+  test("ok", function () {
+    expect(res.getStatus()).to.equal(200);
+    bru.setEnvVar("name", res.getBody().name);
+  });
+  pm.sendRequest("https://example.com");
+}
+`)
+	requireFile(t, filepath.Join(outDir, "Users", "Create user.bru"), `meta {
+  name: Create user
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+  auth: basic
+}
+
+auth:basic {
+  username: admin
+  password: {{password}}
+}
+
+body:json {
+  {
+    "name": "John"
+  }
+}
+
+docs {
+  Creates a user.
+}
+`)
+	requireFile(t, filepath.Join(outDir, "Upload.bru"), `meta {
+  name: Upload
+  type: http
+  seq: 1
+}
+
+put {
+  url: {{baseUrl}}/upload
+  body: multipart-form
+  auth: none
+}
+
+body:multipart-form {
+  avatar: @file(/tmp/avatar.png)
+  note: hi
+}
+`)
+}
+
+func TestImportPostman_Reimport(t *testing.T) {
+	t.Parallel()
+	outDir := t.TempDir()
+	_, err := ImportPostman([]byte(_testPostmanCollection), nil, outDir)
+	require.NoError(t, err)
+	requireFile(t, filepath.Join(outDir, "environments", "Users API.bru"), `vars {
+  baseUrl: https://api.example.com
+  limit: 10
+}
+`)
+
+	filePath := filepath.Join(outDir, "Users", "Create user.bru")
+	data, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	edited := string(data) + "\nassert {\n  res.status: eq 201\n}\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(edited), 0o600))
+
+	result, err := ImportPostman([]byte(_testPostmanCollection), nil, outDir)
+	require.NoError(t, err)
+	require.Empty(t, result.Created)
+	require.Empty(t, result.Updated)
+	requireFile(t, filePath, edited)
+}
+
+func TestImportPostman_Invalid(t *testing.T) {
+	t.Parallel()
+	_, err := ImportPostman([]byte(`{"info": {"name": "v1"}, "requests": []}`), nil, t.TempDir())
+	require.ErrorIs(t, err, ErrInvalidCollection)
+	_, err = ImportPostman([]byte(_testPostmanCollection), [][]byte{[]byte(`[]`)}, t.TempDir())
+	require.ErrorIs(t, err, ErrInvalidCollection)
+}
+
+func TestTranslateScript(t *testing.T) {
+	t.Parallel()
+	script, unsupported := translateScript(`const token = pm.collectionVariables.get("token");
+insomnia.environment.set("id", insomnia.response.json().id);
+postman.setEnvironmentVariable("a", pm.response.code);
+pm.expect(pm.response.text()).to.include("ok");
+pm.cookies.get("session");`)
+	require.Equal(t, `const token = bru.getVar("token");
+bru.setEnvVar("id", res.getBody().id);
+bru.setEnvVar("a", res.getStatus());
+expect(res.getBody()).to.include("ok");
+pm.cookies.get("session");`, script)
+	require.Equal(t, []string{"pm.cookies.get"}, unsupported)
+}
@@ -0,0 +1,76 @@
+package bruimporter
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+// Postman APIs and their Bruno equivalents.
+// Ref: https://learning.postman.com/docs/tests-and-scripts/write-scripts/postman-sandbox-reference/ and
+// https://docs.usebruno.com/testing/script/javascript-reference
+var _postmanScriptReplacer = strings.NewReplacer(
+	"pm.environment.get(", "bru.getEnvVar(",
+	"pm.environment.set(", "bru.setEnvVar(",
+	"pm.variables.get(", "bru.getVar(",
+	"pm.variables.set(", "bru.setVar(",
+	"pm.collectionVariables.get(", "bru.getVar(",
+	"pm.collectionVariables.set(", "bru.setVar(",
+	"pm.globals.get(", "bru.getGlobalEnvVar(",
+	"pm.globals.set(", "bru.setGlobalEnvVar(",
+	"pm.setNextRequest(", "bru.setNextRequest(",
+	"pm.test(", "test(",
+	"pm.expect(", "expect(",
+	"pm.response.json()", "res.getBody()",
+	// Bruno parses JSON bodies, other bodies are the text as is
+	"pm.response.text()", "res.getBody()",
+	"pm.response.code", "res.getStatus()",
+	"pm.response.responseTime", "res.getResponseTime()",
+	"pm.response.headers.get(", "res.getHeader(",
+	"pm.request.url", "req.getUrl()",
+	"pm.request.method", "req.getMethod()",
+	"pm.info.requestName", "req.getName()",
+	"postman.setEnvironmentVariable(", "bru.setEnvVar(",
+	"postman.getEnvironmentVariable(", "bru.getEnvVar(",
+	"postman.setNextRequest(", "bru.setNextRequest(",
+)
+
+var (
+	_postmanStatusAssertionRegex = regexp.MustCompile(`pm\.response\.to\.have\.status\((\d+)\)`)
+	// Insomnia's script API is modelled after Postman's
+	_insomniaScriptRegex = regexp.MustCompile(`\binsomnia\.`)
+	_postmanAPIRegex     = regexp.MustCompile(`\b(?:pm|postman)\.[A-Za-z_.]+`)
+)
+
+// translateScript rewrites the Postman, or Insomnia, APIs used by the script to the Bruno ones.
+// It returns the script and the APIs that could not be translated, e.g. "pm.sendRequest".
+func translateScript(script string) (string, []string) {
+	script = _insomniaScriptRegex.ReplaceAllString(script, "pm.")
+	script = _postmanStatusAssertionRegex.ReplaceAllString(script, "expect(res.getStatus()).to.equal($1)")
+	script = _postmanScriptReplacer.Replace(script)
+
+	unsupported := make([]string, 0)
+	for _, api := range _postmanAPIRegex.FindAllString(script, -1) {
+		api = strings.TrimSuffix(api, ".")
+		if !slices.Contains(unsupported, api) {
+			unsupported = append(unsupported, api)
+		}
+	}
+	return script, unsupported
+}
+
+// setScript adds the translated script as the section of the document, e.g. "tests", and reports the APIs that
+// could not be translated
+func (c *_ImportedCollection) setScript(document *bruparser.Document, item string, sectionName string, script string) {
+	script = strings.Trim(script, "\r\n")
+	if strings.TrimSpace(script) == "" {
+		return
+	}
+	script, unsupported := translateScript(script)
+	if len(unsupported) > 0 {
+		c.addReport(item, "'%s' uses APIs that could not be converted: %s", sectionName, strings.Join(unsupported, ", "))
+	}
+	document.SetSection(bruparser.Section{Name: sectionName, Text: script})
+}
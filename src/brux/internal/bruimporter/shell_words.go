@@ -0,0 +1,130 @@
+package bruimporter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrUnterminatedQuote = errors.New("unterminated quote")
+
+// splitShellWords splits a command into words the way a POSIX shell does, including "$'...'" quoting used
+// by "Copy as cURL" of the browsers. Line continuations via a trailing backslash are joined.
+func splitShellWords(command string) ([]string, error) {
+	words := make([]string, 0)
+	var word strings.Builder
+	inWord := false
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case r == '\\':
+			i++
+			// A backslash before a newline continues the line
+			if i < len(runes) && runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: %s", ErrUnterminatedQuote, string(runes[i:]))
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+		case r == '"':
+			end, err := readDoubleQuoted(runes, i+1, &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i = end
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			end, err := readANSICQuoted(runes, i+2, &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i = end
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// readDoubleQuoted writes the contents of a "..." string starting at start and returns the index of the closing quote
+func readDoubleQuoted(runes []rune, start int, word *strings.Builder) (int, error) {
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '"':
+			return i, nil
+		case '\\':
+			if i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+				i++
+				if runes[i] != '\n' {
+					word.WriteRune(runes[i])
+				}
+				continue
+			}
+			word.WriteRune(runes[i])
+		default:
+			word.WriteRune(runes[i])
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnterminatedQuote, string(runes[start-1:]))
+}
+
+// readANSICQuoted writes the contents of a $'...' string starting at start and returns the index of the closing quote
+func readANSICQuoted(runes []rune, start int, word *strings.Builder) (int, error) {
+	escapes := map[rune]string{
+		'n': "\n", 't': "\t", 'r': "\r", '\\': "\\", '\'': "'", '"': "\"", '0': "\x00", 'e': "\x1b",
+	}
+	for i := start; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\'':
+			return i, nil
+		case runes[i] == '\\' && i+1 < len(runes):
+			i++
+			if escaped, ok := escapes[runes[i]]; ok {
+				word.WriteString(escaped)
+				continue
+			}
+			// Hex and unicode escapes like \x41 and \u00e9
+			if size := map[rune]int{'x': 2, 'u': 4, 'U': 8}[runes[i]]; size > 0 && i+size < len(runes) {
+				if code, err := strconv.ParseUint(string(runes[i+1:i+1+size]), 16, 32); err == nil {
+					word.WriteRune(rune(code))
+					i += size
+					continue
+				}
+			}
+			word.WriteRune('\\')
+			word.WriteRune(runes[i])
+		default:
+			word.WriteRune(runes[i])
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrUnterminatedQuote, string(runes[start-2:]))
+}
+
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,166 @@
+package brumock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrInvalidMock = errors.New("invalid mock")
+
+// Options configures the responses of the mock server
+type Options struct {
+	// Directory of the snapshots saved by "brux run --snapshot-dir", served for the requests without a "mock" section
+	SnapshotDir string
+	// Delay of every response, requests can override it via "delay" in the "mock" section
+	Latency time.Duration
+	// Share of the requests between 0 and 1 that fail with ErrorStatus instead of their response
+	ErrorRate float64
+	// Status of the injected errors, 500 if zero
+	ErrorStatus int
+}
+
+// Handler serves the responses of the requests of a collection, routed by method and URL path
+type Handler struct {
+	options Options
+	// Sorted by the number of path params, to prefer "/users/me" over "/users/:id"
+	routes []*_Route
+}
+
+// Route is a request of the collection served by the mock server
+type Route struct {
+	// Upper case, e.g. "GET"
+	Method string
+	// URL path as in the Bru file without the server, e.g. "/users/:id"
+	Path string
+	// Path relative to the collection, e.g. "users/get-user.bru"
+	FilePath string
+	// False if the request has neither a "mock" section nor a snapshot, it is answered with 501
+	HasResponse bool
+}
+
+type _Route struct {
+	Route
+	regex    *regexp.Regexp
+	params   int
+	response *_Response
+}
+
+// NewHandler reads the requests of the collection directory and their responses
+func NewHandler(dir string, options Options) (*Handler, error) {
+	if options.ErrorRate < 0 || options.ErrorRate > 1 {
+		return nil, fmt.Errorf("%w: error rate must be between 0 and 1", ErrInvalidMock)
+	}
+	if options.ErrorStatus == 0 {
+		options.ErrorStatus = http.StatusInternalServerError
+	}
+	if options.ErrorStatus < 100 || options.ErrorStatus > 599 {
+		return nil, fmt.Errorf("%w: invalid error status %d", ErrInvalidMock, options.ErrorStatus)
+	}
+	if options.Latency < 0 {
+		return nil, fmt.Errorf("%w: latency must not be negative", ErrInvalidMock)
+	}
+
+	routes, err := readRoutes(dir, options.SnapshotDir)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(routes, func(a, b *_Route) int {
+		return a.params - b.params
+	})
+	return &Handler{options: options, routes: routes}, nil
+}
+
+// Routes returns the routes in the order they are matched
+func (h *Handler) Routes() []Route {
+	routes := make([]Route, 0, len(h.routes))
+	for _, route := range h.routes {
+		routes = append(routes, route.Route)
+	}
+	return routes
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, allowedMethods := h.findRoute(r.Method, r.URL.Path)
+	if route == nil {
+		if len(allowedMethods) > 0 {
+			w.Header().Set("Allow", strings.Join(allowedMethods, ", "))
+			h.writeError(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("no request matches %s %s", r.Method, r.URL.Path))
+			return
+		}
+		h.writeError(w, r, http.StatusNotFound, fmt.Sprintf("no request matches %s %s", r.Method, r.URL.Path))
+		return
+	}
+
+	delay := h.options.Latency
+	if route.response != nil && route.response.delay != nil {
+		delay = *route.response.delay
+	}
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	//nolint:gosec // Injected errors do not need a cryptographically secure random number
+	if h.options.ErrorRate > 0 && rand.Float64() < h.options.ErrorRate {
+		h.writeError(w, r, h.options.ErrorStatus, "injected error")
+		return
+	}
+	if route.response == nil {
+		h.writeError(w, r, http.StatusNotImplemented, fmt.Sprintf(
+			"no response for %s, add a 'mock' section or save a snapshot via 'brux run --snapshot-dir'", route.FilePath))
+		return
+	}
+
+	for name, values := range route.response.headers {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(route.response.statusCode)
+	if r.Method != http.MethodHead {
+		_, _ = w.Write(route.response.body)
+	}
+	log.Info().
+		Str("method", r.Method).
+		Str("path", r.URL.Path).
+		Int("status", route.response.statusCode).
+		Str("filePath", route.FilePath).
+		Msg("mock response")
+}
+
+// findRoute returns the route matching the method and path, or the methods of the routes matching the path
+func (h *Handler) findRoute(method string, urlPath string) (*_Route, []string) {
+	allowedMethods := make([]string, 0)
+	for _, route := range h.routes {
+		if !route.regex.MatchString(urlPath) {
+			continue
+		}
+		if route.Method == method || (method == http.MethodHead && route.Method == http.MethodGet) {
+			return route, nil
+		}
+		if !slices.Contains(allowedMethods, route.Method) {
+			allowedMethods = append(allowedMethods, route.Method)
+		}
+	}
+	return nil, allowedMethods
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	log.Warn().
+		Str("method", r.Method).
+		Str("path", r.URL.Path).
+		Int("status", statusCode).
+		Msg(message)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,220 @@
+package brumock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+var _testCollectionFiles = map[string]string{
+	"bruno.json": `{"version": "1", "name": "Users API", "type": "collection"}`,
+	"environments/local.bru": `vars {
+  baseUrl: http://localhost:8080
+}
+`,
+	"users/folder.bru": `meta {
+  name: Users
+}
+`,
+	"users/list.bru": `meta {
+  name: List users
+  type: http
+}
+
+get {
+  url: {{baseUrl}}/users?limit=10
+}
+
+mock:headers {
+  X-Total-Count: 1
+}
+
+mock:body {
+  [{"id": 1, "name": "Ada"}]
+}
+`,
+	"users/get-me.bru": `meta {
+  name: Get me
+  type: http
+}
+
+get {
+  url: https://api.example.com/users/me
+}
+
+mock:body {
+  {"id": 0}
+}
+`,
+	"users/get-user.bru": `meta {
+  name: Get user
+  type: http
+}
+
+get {
+  url: {{baseUrl}}/users/:id
+}
+`,
+	"users/create.bru": `meta {
+  name: Create user
+  type: http
+}
+
+post {
+  url: {{baseUrl}}/users
+}
+
+mock {
+  status: 201
+  delay: 0
+}
+
+mock:headers {
+  Location: /users/2
+}
+`,
+	"users/delete.bru": `meta {
+  name: Delete user
+  type: http
+}
+
+delete {
+  url: {{baseUrl}}/users/{{userId}}
+}
+`,
+	"snapshots/users/get-user.snap.json": `{
+  "status": 200,
+  "headers": {
+    "Content-Type": "application/json",
+    "Date": "<ignored>"
+  },
+  "body": {
+    "id": 12345678901234567890,
+    "name": "<ignored>"
+  }
+}
+`,
+}
+
+func writeTestCollection(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range _testCollectionFiles {
+		filePath := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o750))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+	}
+	return dir
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	dir := writeTestCollection(t)
+	handler, err := NewHandler(dir, Options{SnapshotDir: filepath.Join(dir, "snapshots")})
+	require.NoError(t, err)
+	require.Equal(t, []Route{
+		{Method: "POST", Path: "/users", FilePath: "users/create.bru", HasResponse: true},
+		{Method: "GET", Path: "/users/me", FilePath: "users/get-me.bru", HasResponse: true},
+		{Method: "GET", Path: "/users", FilePath: "users/list.bru", HasResponse: true},
+		{Method: "DELETE", Path: "/users/{{userId}}", FilePath: "users/delete.bru", HasResponse: false},
+		{Method: "GET", Path: "/users/:id", FilePath: "users/get-user.bru", HasResponse: true},
+	}, handler.Routes())
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	testCases := []struct {
+		method     string
+		path       string
+		statusCode int
+		headers    map[string]string
+		body       string
+	}{
+		{
+			method: "GET", path: "/users?limit=5", statusCode: http.StatusOK,
+			headers: map[string]string{"Content-Type": "application/json", "X-Total-Count": "1"},
+			body:    `[{"id": 1, "name": "Ada"}]`,
+		},
+		{method: "GET", path: "/users/me", statusCode: http.StatusOK, body: `{"id": 0}`},
+		{
+			method: "GET", path: "/users/42/", statusCode: http.StatusOK,
+			headers: map[string]string{"Content-Type": "application/json"},
+			body:    "{\n  \"id\": 12345678901234567890,\n  \"name\": \"<ignored>\"\n}\n",
+		},
+		{
+			method: "POST", path: "/users", statusCode: http.StatusCreated,
+			headers: map[string]string{"Location": "/users/2", "Content-Type": ""},
+		},
+		{method: "DELETE", path: "/users/42", statusCode: http.StatusNotImplemented},
+		{
+			method: "PUT", path: "/users/42", statusCode: http.StatusMethodNotAllowed,
+			headers: map[string]string{"Allow": "DELETE, GET"},
+		},
+		{method: "GET", path: "/posts", statusCode: http.StatusNotFound, body: `{"error":"no request matches GET /posts"}` + "\n"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.method+" "+testCase.path, func(t *testing.T) {
+			t.Parallel()
+			req, err := http.NewRequestWithContext(t.Context(), testCase.method, server.URL+testCase.path, nil)
+			require.NoError(t, err)
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, testCase.statusCode, resp.StatusCode, string(body))
+			for name, value := range testCase.headers {
+				require.Equal(t, value, resp.Header.Get(name), name)
+			}
+			if testCase.body != "" {
+				require.Equal(t, testCase.body, string(body))
+			}
+		})
+	}
+}
+
+func TestHandler_LatencyAndErrors(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	dir := writeTestCollection(t)
+	handler, err := NewHandler(dir, Options{Latency: 50 * time.Millisecond, ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable})
+	require.NoError(t, err)
+
+	// The delay of the "mock" section overrides the latency
+	start := time.Now()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users", nil))
+	require.Less(t, time.Since(start), 50*time.Millisecond)
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	require.JSONEq(t, `{"error": "injected error"}`, recorder.Body.String())
+
+	start = time.Now()
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users", nil))
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
+
+func TestNewHandler_Invalid(t *testing.T) {
+	t.Parallel()
+	dir := writeTestCollection(t)
+	_, err := NewHandler(dir, Options{ErrorRate: 1.5})
+	require.ErrorIs(t, err, ErrInvalidMock)
+	_, err = NewHandler(dir, Options{ErrorStatus: 42})
+	require.ErrorIs(t, err, ErrInvalidMock)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "users", "create.bru"),
+		[]byte(strings.Replace(_testCollectionFiles["users/create.bru"], "status: 201", "status: created", 1)), 0o600))
+	_, err = NewHandler(dir, Options{})
+	require.ErrorIs(t, err, ErrInvalidMock)
+}
@@ -0,0 +1,201 @@
+package brumock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+// Variables like "{{userId}}"
+var _variableRegex = regexp.MustCompile(`{{[^{}]+}}`)
+
+// _Response is the response of a request, from its "mock" section or its snapshot
+type _Response struct {
+	statusCode int
+	headers    http.Header
+	body       []byte
+	// Overrides Options.Latency if set
+	delay *time.Duration
+}
+
+// readRoutes returns the routes of the Bru files in the collection directory, sorted by path
+func readRoutes(dir string, snapshotDir string) ([]*_Route, error) {
+	routes := make([]*_Route, 0)
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if filePath != dir && bruparser.IsIgnoredDir(name) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(name) != ".bru" || name == "folder.bru" || name == "collection.bru" {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return fmt.Errorf("could not get path of '%s' in the collection: %w", filePath, err)
+		}
+		route, err := readRoute(filePath, filepath.ToSlash(relativePath), snapshotDir)
+		if err != nil {
+			return err
+		}
+		if route != nil {
+			routes = append(routes, route)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read collection '%s': %w", dir, err)
+	}
+	return routes, nil
+}
+
+// readRoute returns the route of the Bru file, nil if it is not a request
+func readRoute(filePath string, relativePath string, snapshotDir string) (*_Route, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file: %w", err)
+	}
+	defer f.Close()
+	document, err := bruparser.ParseDocument(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse '%s': %w", filePath, err)
+	}
+
+	for _, method := range bruparser.HTTPMethods {
+		section, ok := document.Section(method)
+		if !ok {
+			continue
+		}
+		urlPath := bruparser.RequestPath(section.Value("url"))
+		regex, params := pathRegex(urlPath)
+		route := &_Route{
+			Route:  Route{Method: strings.ToUpper(method), Path: urlPath, FilePath: relativePath},
+			regex:  regex,
+			params: params,
+		}
+		if route.response, err = mockResponse(document); err != nil {
+			return nil, fmt.Errorf("%w: '%s': %w", ErrInvalidMock, filePath, err)
+		}
+		if route.response == nil && snapshotDir != "" {
+			if route.response, err = snapshotResponse(snapshotDir, relativePath); err != nil {
+				return nil, err
+			}
+		}
+		route.HasResponse = route.response != nil
+		return route, nil
+	}
+	return nil, nil
+}
+
+// mockResponse returns the response of the "mock", "mock:headers" and "mock:body" sections, nil if there are none
+// Example:
+//
+//	mock {
+//	  status: 201
+//	  delay: 200ms
+//	}
+//
+//	mock:body {
+//	  {"id": 1}
+//	}
+func mockResponse(document *bruparser.Document) (*_Response, error) {
+	mock, hasMock := document.Section("mock")
+	headers, hasHeaders := document.Section("mock:headers")
+	body, hasBody := document.Section("mock:body")
+	if !hasMock && !hasHeaders && !hasBody {
+		return nil, nil
+	}
+
+	response := &_Response{statusCode: http.StatusOK, headers: make(http.Header), body: []byte(body.Text)}
+	if status := mock.Value("status"); status != "" {
+		statusCode, err := strconv.Atoi(status)
+		if err != nil || statusCode < 100 || statusCode > 599 {
+			return nil, fmt.Errorf("invalid status '%s'", status)
+		}
+		response.statusCode = statusCode
+	}
+	if delay := mock.Value("delay"); delay != "" {
+		duration, err := parseDelay(delay)
+		if err != nil {
+			return nil, err
+		}
+		response.delay = &duration
+	}
+	for _, kv := range headers.EnabledEntries() {
+		response.headers.Add(kv.Key, kv.Value)
+	}
+	setContentType(response)
+	return response, nil
+}
+
+// snapshotResponse returns the response of the snapshot of the request, nil if there is none
+func snapshotResponse(snapshotDir string, relativePath string) (*_Response, error) {
+	snapshot, err := brurunner.ReadSnapshot(snapshotDir, relativePath)
+	if err != nil || snapshot == nil {
+		return nil, err
+	}
+	response := &_Response{statusCode: snapshot.StatusCode, headers: snapshot.Headers, body: snapshot.Body}
+	setContentType(response)
+	return response, nil
+}
+
+// parseDelay parses a delay in milliseconds or a duration like "1s"
+func parseDelay(delay string) (time.Duration, error) {
+	if milliseconds, err := strconv.Atoi(delay); err == nil && milliseconds >= 0 {
+		return time.Duration(milliseconds) * time.Millisecond, nil
+	}
+	duration, err := time.ParseDuration(delay)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid delay '%s'", delay)
+	}
+	return duration, nil
+}
+
+// setContentType sets the content type of the body unless it is set already, JSON if the body is valid JSON
+func setContentType(response *_Response) {
+	if len(response.body) == 0 || response.headers.Get("Content-Type") != "" {
+		return
+	}
+	if json.Valid(response.body) {
+		response.headers.Set("Content-Type", "application/json")
+	} else {
+		response.headers.Set("Content-Type", http.DetectContentType(response.body))
+	}
+}
+
+// pathRegex returns a regex matching the path and its number of params, which are path params like ":id" and
+// variables like "{{userId}}" matching any segment
+func pathRegex(urlPath string) (*regexp.Regexp, int) {
+	params := 0
+	segments := strings.Split(strings.TrimSuffix(urlPath, "/"), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "[^/]+"
+			params++
+			continue
+		}
+		parts := _variableRegex.Split(segment, -1)
+		for j, part := range parts {
+			parts[j] = regexp.QuoteMeta(part)
+		}
+		params += len(parts) - 1
+		segments[i] = strings.Join(parts, "[^/]+")
+	}
+	return regexp.MustCompile("^" + strings.Join(segments, "/") + "/?$"), params
+}
@@ -0,0 +1,198 @@
+package bruparser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Conventional order of the sections in a Bru file, names ending with ":" match all the sections with that prefix
+var _sectionOrder = []string{
+	"meta",
+	"get", "post", "put", "delete", "patch", "options", "head", "connect", "trace",
+	"params:",
+	"headers",
+	"auth:",
+	"body:",
+	"vars",
+	"vars:secret",
+	"vars:pre-request",
+	"vars:post-response",
+	"assert",
+	"script:pre-request",
+	"script:post-response",
+	"tests",
+	"docs",
+	"settings",
+	"mock",
+	"mock:headers",
+	"mock:body",
+}
+
+// Document is the list of sections of a Bru file.
+// Unlike BruFile, it keeps every section as is, so that Bru files can be created and modified without losing
+// anything, e.g. by the importers.
+type Document struct {
+	Sections []Section
+}
+
+// ParseDocument parses the sections of a Bru file without interpreting them
+func ParseDocument(reader io.Reader) (*Document, error) {
+	lines, err := getLines(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := getSections(lines)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sections: %w", err)
+	}
+	return &Document{Sections: sections}, nil
+}
+
+// ReadDocumentFile parses the Bru file, it returns nil if the file does not exist
+func ReadDocumentFile(filePath string) (*Document, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %w", err)
+	}
+	document, err := ParseDocument(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse '%s': %w", filePath, err)
+	}
+	return document, nil
+}
+
+// IsIgnoredDir returns true for the directories of a collection without requests: the environments, "node_modules"
+// and hidden ones like ".git"
+func IsIgnoredDir(name string) bool {
+	return name == "environments" || name == "node_modules" || strings.HasPrefix(name, ".")
+}
+
+// Method returns the section of the method of the request, e.g. "get", false if the document is not a request
+func (d Document) Method() (Section, bool) {
+	for _, method := range HTTPMethods {
+		if section, ok := d.Section(method); ok {
+			return section, true
+		}
+	}
+	return Section{}, false
+}
+
+// Section returns the first section with the name
+func (d Document) Section(name string) (Section, bool) {
+	index := slices.IndexFunc(d.Sections, func(section Section) bool {
+		return section.Name == name
+	})
+	if index < 0 {
+		return Section{}, false
+	}
+	return d.Sections[index], true
+}
+
+// SetSection replaces the section with the same name, or adds it at its conventional position
+func (d *Document) SetSection(section Section) {
+	index := slices.IndexFunc(d.Sections, func(s Section) bool {
+		return s.Name == section.Name
+	})
+	if index >= 0 {
+		d.Sections[index] = section
+		return
+	}
+
+	rank := sectionRank(section.Name)
+	index = slices.IndexFunc(d.Sections, func(s Section) bool {
+		return sectionRank(s.Name) > rank
+	})
+	if index < 0 {
+		index = len(d.Sections)
+	}
+	d.Sections = slices.Insert(d.Sections, index, section)
+}
+
+// RemoveSection removes all the sections with the name
+func (d *Document) RemoveSection(name string) {
+	d.Sections = slices.DeleteFunc(d.Sections, func(section Section) bool {
+		return section.Name == name
+	})
+}
+
+// Bytes returns the document in the Bru format
+func (d Document) Bytes() []byte {
+	var buf bytes.Buffer
+	for i, section := range d.Sections {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		writeSection(&buf, section)
+	}
+	return buf.Bytes()
+}
+
+func writeSection(buf *bytes.Buffer, section Section) {
+	if section.IsList() {
+		buf.WriteString(section.Name + " [\n")
+		for i, entry := range section.Entries {
+			buf.WriteString("  ")
+			if !entry.Enabled {
+				buf.WriteString("~")
+			}
+			buf.WriteString(entry.Key)
+			if i < len(section.Entries)-1 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n")
+		}
+		buf.WriteString("]\n")
+		return
+	}
+
+	buf.WriteString(section.Name + " {\n")
+	if section.IsText() {
+		if section.Text != "" {
+			for _, line := range strings.Split(strings.TrimRight(section.Text, "\n"), "\n") {
+				if !isEmptyLine(line) {
+					buf.WriteString("  " + line)
+				}
+				buf.WriteString("\n")
+			}
+		}
+	} else {
+		for _, entry := range section.Entries {
+			buf.WriteString("  ")
+			if !entry.Enabled {
+				buf.WriteString("~")
+			}
+			buf.WriteString(entry.Key + ": " + entry.Value + "\n")
+		}
+	}
+	buf.WriteString("}\n")
+}
+
+func sectionRank(name string) int {
+	for rank, prefix := range _sectionOrder {
+		if name == prefix || (strings.HasSuffix(prefix, ":") && strings.HasPrefix(name, prefix)) {
+			return rank
+		}
+	}
+	return len(_sectionOrder)
+}
+
+// SetValue sets the value of the last entry with the key, or adds an entry if there is none
+func (s *Section) SetValue(key string, value string) {
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		if s.Entries[i].Key == key {
+			s.Entries[i].Value = value
+			return
+		}
+	}
+	s.Entries = append(s.Entries, KeyValue{Key: key, Value: value, Enabled: true})
+}
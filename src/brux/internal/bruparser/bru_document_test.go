@@ -0,0 +1,134 @@
+package bruparser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _fullRequest = `meta {
+  name: Create user
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{host}}/users
+  body: json
+  auth: bearer
+}
+
+headers {
+  Content-Type: application/json
+  ~X-Debug: true
+}
+
+auth:bearer {
+  token: {{token}}
+}
+
+body:json {
+  {
+    "name": "John",
+    "tags": ["a", "b"]
+  }
+}
+
+script:pre-request {
+  // Comments and empty lines are kept
+
+  # even the ones that look like Bru comments
+  bru.setVar("id", 1);
+}
+
+tests {
+  test("status", function() {
+    expect(res.status).to.equal(201);
+  });
+}
+`
+
+func TestDocument_RoundTrip(t *testing.T) {
+	t.Parallel()
+	document, err := ParseDocument(strings.NewReader(_fullRequest))
+	require.NoError(t, err)
+	require.Len(t, document.Sections, 7)
+
+	headers, ok := document.Section("headers")
+	require.True(t, ok)
+	require.Equal(t, []KeyValue{
+		{Key: "Content-Type", Value: "application/json", Enabled: true},
+		{Key: "X-Debug", Value: "true", Enabled: false},
+	}, headers.Entries)
+	script, ok := document.Section("script:pre-request")
+	require.True(t, ok)
+	require.Equal(t, "// Comments and empty lines are kept\n\n# even the ones that look like Bru comments\nbru.setVar(\"id\", 1);",
+		script.Text)
+
+	require.Equal(t, _fullRequest, string(document.Bytes()))
+}
+
+func TestDocument_SetSection(t *testing.T) {
+	t.Parallel()
+	document := &Document{}
+	document.SetSection(Section{Name: "tests", Text: "// tests"})
+	document.SetSection(Section{Name: "get", Entries: []KeyValue{{Key: "url", Value: "https://example.com", Enabled: true}}})
+	document.SetSection(Section{Name: "body:json", Text: "{}"})
+	document.SetSection(Section{Name: "meta", Entries: []KeyValue{{Key: "name", Value: "Get", Enabled: true}}})
+	document.SetSection(Section{Name: "body:json", Text: "[]"})
+
+	names := make([]string, 0)
+	for _, section := range document.Sections {
+		names = append(names, section.Name)
+	}
+	require.Equal(t, []string{"meta", "get", "body:json", "tests"}, names)
+	body, _ := document.Section("body:json")
+	require.Equal(t, "[]", body.Text)
+}
+
+func TestDocument_ListSection(t *testing.T) {
+	t.Parallel()
+	const environment = `vars {
+  host: https://example.com
+}
+
+vars:secret [
+  apiKey,
+  ~password
+]
+`
+	document, err := ParseDocument(strings.NewReader(environment))
+	require.NoError(t, err)
+	secrets, ok := document.Section("vars:secret")
+	require.True(t, ok)
+	require.Equal(t, []KeyValue{{Key: "apiKey", Enabled: true}, {Key: "password"}}, secrets.Entries)
+	require.Equal(t, environment, string(document.Bytes()))
+
+	bruFile, err := NewBruFile(strings.NewReader(environment))
+	require.NoError(t, err)
+	require.Equal(t, []string{"apiKey"}, bruFile.SecretVariables())
+}
+
+func TestReadDocumentFile(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "create.bru")
+	require.NoError(t, os.WriteFile(filePath, []byte(_fullRequest), 0o600))
+
+	document, err := ReadDocumentFile(filePath)
+	require.NoError(t, err)
+	method, ok := document.Method()
+	require.True(t, ok)
+	require.Equal(t, "post", method.Name)
+	require.Equal(t, "{{host}}/users", method.Value("url"))
+
+	document, err = ReadDocumentFile(filepath.Join(dir, "folder.bru"))
+	require.NoError(t, err)
+	require.Nil(t, document)
+
+	_, ok = (&Document{Sections: []Section{{Name: "meta"}}}).Method()
+	require.False(t, ok)
+}
@@ -9,14 +9,18 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func getCleanedLines(reader io.Reader) ([]string, error) {
-	// Read the file line by line and collect meaningful lines.
+// getLines returns all the lines, the empty lines, comments and annotations are skipped by getSections,
+// except in text sections like "body:json" where they are meaningful
+func getLines(reader io.Reader) ([]string, error) {
 	lines := make([]string, 0)
 	scanner := bufio.NewReader(reader)
 	for {
 		line, err := scanner.ReadString('\n')
 		if err == io.EOF {
 			log.Trace().Msg("EOF")
+			if line != "" {
+				lines = append(lines, line)
+			}
 			break
 		}
 
@@ -24,12 +28,6 @@ func getCleanedLines(reader io.Reader) ([]string, error) {
 			return nil, fmt.Errorf("error reading file: %w", err)
 		}
 
-		// Skip empty lines, comments and annotations
-		if isEmptyLine(line) || isComment(line) || isAnnotation(line) {
-			continue
-		}
-
-		// Parse the line
 		lines = append(lines, line)
 	}
 	return lines, nil
@@ -1,11 +1,14 @@
 package bruparser
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -14,13 +17,44 @@ import (
 
 // BruFile is a struct that represents a Bru file
 type BruFile struct {
-	meta     *_Meta
-	req      *_Request
-	headers  map[string]string
-	bodyJson *string
+	meta    *_Meta
+	req     *_Request
+	headers map[string]string
+	// Body sections by type, e.g. "json" for "body:json"
+	bodies map[string]Section
+	// Auth sections by mode, e.g. "basic" for "auth:basic"
+	auths map[string]Section
+	// "params:path" section, replaces segments like ":id" of the URL path
+	pathParams []KeyValue
+
+	assertions []KeyValue
+	settings   map[string]string
 
 	// This section is present in the "env" files
 	vars map[string]string
+	// Names of the secret variables, from the "vars:secret" section of the "env" files
+	secretVars []string
+
+	// Variables from the process environment, used for "{{process.env.X}}"
+	processEnv map[string]string
+
+	// "vars:pre-request" section, these take precedence over vars
+	requestVars map[string]string
+	// "vars:post-response" section, expressions like "res.body.token" evaluated after the response is received
+	postResponseVars []KeyValue
+	// Variables set by earlier requests of the run, these take precedence over all other variables
+	runtimeVars map[string]string
+
+	// Directory of the Bru file, relative file paths like "@file(data.csv)" of multipart forms are relative to it
+	dir string
+}
+
+// KeyValue is an entry of a key-value section like "assert"
+type KeyValue struct {
+	Key   string
+	Value string
+	// Entries prefixed with "~" in the Bru file are disabled
+	Enabled bool
 }
 
 type _Meta struct {
@@ -30,21 +64,33 @@ type _Meta struct {
 }
 
 type _Request struct {
-	httpMethod string // only "get" for now
+	httpMethod string
 	url        string
-	body       string // only "json" for now
-	auth       string // only "none" for now
+	body       string // e.g. "json", "form-urlencoded" or "none"
+	auth       string // e.g. "basic", "bearer" or "none"
 }
 
 var (
 	ErrUnknownSectionName            = errors.New("unknown section name")
 	ErrUnsupportedNetworkRequestType = errors.New("unsupported request type")
 	ErrTemplateVariablesFound        = errors.New("template variables found")
+	ErrEmptyPathParam                = errors.New("empty path param")
 )
 
+var (
+	_processEnvRegex = regexp.MustCompile(`{{process\.env\.([^{}\s]+)}}`)
+	// URLs starting with a variable like "{{baseUrl}}"
+	_variablePrefixRegex = regexp.MustCompile(`^{{[^{}]+}}`)
+	// URLs starting with a server like "https://{{host}}:8080"
+	_serverOriginRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]*`)
+)
+
+// HTTPMethods are the names of the request sections, e.g. "get"
+var HTTPMethods = []string{"get", "post", "put", "delete", "patch", "options", "head", "connect", "trace"}
+
 // NewBruFile creates a new BruFile object from the given reader
 func NewBruFile(reader io.Reader) (*BruFile, error) {
-	lines, err := getCleanedLines(reader)
+	document, err := ParseDocument(reader)
 	if err != nil {
 		return nil, err
 	}
@@ -52,89 +98,153 @@ func NewBruFile(reader io.Reader) (*BruFile, error) {
 	var metaSection *_Meta
 	var reqSection *_Request
 	headers := make(map[string]string)
-	var bodyJson *string
+	bodies := make(map[string]Section)
+	auths := make(map[string]Section)
+	var pathParams []KeyValue
+	var assertions []KeyValue
+	settings := make(map[string]string)
 	vars := make(map[string]string)
-	sections, err := getSections(lines)
-	if err != nil {
-		return nil, fmt.Errorf("error getting sections: %w", err)
-	}
-
-	for _, section := range sections {
+	secretVars := make([]string, 0)
+	requestVars := make(map[string]string)
+	var postResponseVars []KeyValue
+	for _, section := range document.Sections {
 		log.Debug().
-			Any("section", section.sectionName).
-			Any("values", section.sectionValues).
+			Any("section", section.Name).
+			Any("values", section.Entries).
 			Msg("section")
-		switch section.sectionName {
-		case "meta":
+		switch {
+		case section.Name == "meta":
 			metaSection = &_Meta{
-				name:    section.sectionValues["name"],
-				reqType: section.sectionValues["type"],
-				seq:     section.sectionValues["seq"],
+				name:    section.Value("name"),
+				reqType: section.Value("type"),
+				seq:     section.Value("seq"),
 			}
-			if metaSection.reqType != "http" {
+			// Folder and collection files have no type
+			if metaSection.reqType != "" && metaSection.reqType != "http" {
 				return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedNetworkRequestType, metaSection.reqType)
 			}
-		case "get", "head", "post":
-			urlStr := section.sectionValues["url"]
+		case slices.Contains(HTTPMethods, section.Name):
 			reqSection = &_Request{
-				httpMethod: section.sectionName,
-				body:       section.sectionValues["body"],
-				auth:       section.sectionValues["auth"],
-				url:        urlStr,
+				httpMethod: section.Name,
+				body:       section.Value("body"),
+				auth:       section.Value("auth"),
+				url:        section.Value("url"),
+			}
+		case section.Name == "headers":
+			for _, kv := range section.EnabledEntries() {
+				headers[kv.Key] = kv.Value
+			}
+		case section.Name == "vars":
+			for _, kv := range section.EnabledEntries() {
+				vars[kv.Key] = kv.Value
+			}
+		case section.Name == "vars:secret":
+			for _, kv := range section.EnabledEntries() {
+				secretVars = append(secretVars, kv.Key)
 			}
-		case "headers":
-			for k, v := range section.sectionValues {
-				headers[k] = v
+		case section.Name == "vars:pre-request":
+			for _, kv := range section.EnabledEntries() {
+				requestVars[kv.Key] = kv.Value
 			}
-		case "vars":
-			for k, v := range section.sectionValues {
-				vars[k] = v
+		case section.Name == "vars:post-response":
+			postResponseVars = section.Entries
+		case strings.HasPrefix(section.Name, "body:"):
+			bodies[strings.TrimPrefix(section.Name, "body:")] = section
+		case strings.HasPrefix(section.Name, "auth:"):
+			auths[strings.TrimPrefix(section.Name, "auth:")] = section
+		case section.Name == "assert":
+			assertions = section.Entries
+		case section.Name == "settings":
+			for _, kv := range section.EnabledEntries() {
+				settings[kv.Key] = kv.Value
 			}
-		case "body:json":
-			bodyJson = &section.sectionData
+		case section.Name == "script:pre-request", section.Name == "script:post-response", section.Name == "tests":
+			log.Warn().
+				Str("section", section.Name).
+				Msg("scripts and tests are not supported, ignoring them")
+		case section.Name == "params:path":
+			pathParams = section.EnabledEntries()
+		case section.Name == "params:query", section.Name == "docs", section.Name == "mock", strings.HasPrefix(section.Name, "mock:"):
+			// Query params are part of the URL as well, and docs and mock responses don't affect the request
 		default:
-			return nil, fmt.Errorf("%w: '%s'", ErrUnknownSectionName, section.sectionName)
+			return nil, fmt.Errorf("%w: '%s'", ErrUnknownSectionName, section.Name)
 		}
 	}
 
 	return &BruFile{
-		meta:     metaSection,
-		req:      reqSection,
-		headers:  headers,
-		bodyJson: bodyJson,
-		vars:     vars,
+		meta:    metaSection,
+		req:     reqSection,
+		headers: headers,
+		bodies:  bodies,
+		auths:   auths,
+		vars:    vars,
+
+		pathParams: pathParams,
+
+		secretVars: secretVars,
+
+		assertions: assertions,
+		settings:   settings,
+
+		requestVars:      requestVars,
+		postResponseVars: postResponseVars,
 	}, nil
 }
 
+// Name returns the name from the "meta" section, empty if there is none
+func (f BruFile) Name() string {
+	if f.meta == nil {
+		return ""
+	}
+	return f.meta.name
+}
+
+// Seq returns the sequence number from the "meta" section, used to order the requests of a folder.
+// It returns false if there is no valid sequence number.
+func (f BruFile) Seq() (int, bool) {
+	if f.meta == nil {
+		return 0, false
+	}
+	seq, err := strconv.Atoi(f.meta.seq)
+	return seq, err == nil
+}
+
+// IsRequest returns true if the file has a request section like "get", unlike folder or environment files
+func (f BruFile) IsRequest() bool {
+	return f.req != nil
+}
+
 func (f BruFile) HttpMethod() string {
 	return strings.ToUpper(f.req.httpMethod)
 }
 
 func (f BruFile) URL() (*string, error) {
-	u1 := replaceVariables(f.req.url, f.vars)
+	// The values are resolved before replacing the path params, so that they can be escaped
+	pathParams := make([]KeyValue, 0, len(f.pathParams))
+	for _, param := range f.pathParams {
+		value := f.replaceVariables(param.Value)
+		if hasUnreplacedVariables(value) {
+			return nil, fmt.Errorf("%w: '%s'", ErrTemplateVariablesFound, value)
+		}
+		param.Value = value
+		pathParams = append(pathParams, param)
+	}
+	u, err := replacePathParams(f.req.url, pathParams)
+	if err != nil {
+		return nil, err
+	}
+	u1 := f.replaceVariables(u)
 	if hasUnreplacedVariables(u1) {
 		return nil, fmt.Errorf("%w: '%s'", ErrTemplateVariablesFound, u1)
 	}
 	return lo.ToPtr(u1), nil
 }
 
-func (f BruFile) RequestBody() (io.Reader, error) {
-	if f.req.body == "json" && f.bodyJson != nil {
-		newJson := replaceVariables(*f.bodyJson, f.vars)
-		if hasUnreplacedVariables(newJson) {
-			return nil, fmt.Errorf("%w: '%s'", ErrTemplateVariablesFound, newJson)
-		}
-		return bytes.NewReader([]byte(newJson)), nil
-	}
-
-	return nil, nil
-}
-
 func (f BruFile) Headers() (http.Header, error) {
 	h := make(http.Header)
 	for k, v := range f.headers {
-		k1 := replaceVariables(k, f.vars)
-		v1 := replaceVariables(v, f.vars)
+		k1 := f.replaceVariables(k)
+		v1 := f.replaceVariables(v)
 		if hasUnreplacedVariables(k1) {
 			return nil, fmt.Errorf("%w: '%s'", ErrTemplateVariablesFound, k1)
 		}
@@ -143,14 +253,51 @@ func (f BruFile) Headers() (http.Header, error) {
 		}
 		h.Set(k1, v1)
 	}
+	if h.Get("Authorization") == "" {
+		authorization, err := f.authorization()
+		if err != nil {
+			return nil, err
+		}
+		if authorization != "" {
+			h.Set("Authorization", authorization)
+		}
+	}
 	return h, nil
 }
 
+// Assertions returns the enabled assertions of the "assert" section with the variables replaced
+func (f BruFile) Assertions() ([]KeyValue, error) {
+	assertions := make([]KeyValue, 0, len(f.assertions))
+	for _, assertion := range f.assertions {
+		if !assertion.Enabled {
+			continue
+		}
+		value := f.replaceVariables(assertion.Value)
+		if hasUnreplacedVariables(value) {
+			return nil, fmt.Errorf("%w: '%s'", ErrTemplateVariablesFound, value)
+		}
+		assertion.Value = value
+		assertions = append(assertions, assertion)
+	}
+	return assertions, nil
+}
+
+// Settings returns the raw values of the "settings" section, e.g. "timeout"
+func (f BruFile) Settings() map[string]string {
+	return f.settings
+}
+
 func (f BruFile) Variables() map[string]string {
 	return f.vars
 }
 
-func (f BruFile) SetVariables(variables map[string]string) {
+// SecretVariables returns the names of the secret variables of an environment file
+func (f BruFile) SecretVariables() []string {
+	return f.secretVars
+}
+
+// SetVariables adds the variables, e.g. the ones of the environment, overriding the ones with the same names
+func (f *BruFile) SetVariables(variables map[string]string) {
 	if f.vars == nil {
 		f.vars = make(map[string]string)
 	}
@@ -164,6 +311,63 @@ func (f BruFile) SetVariables(variables map[string]string) {
 		Msg("variables set")
 }
 
+// SetProcessEnv sets the variables available as "{{process.env.X}}".
+// These take precedence over the variables set via SetVariables, which act as the fallback.
+func (f *BruFile) SetProcessEnv(env map[string]string) {
+	f.processEnv = env
+	log.Debug().
+		Int("processEnv", len(f.processEnv)).
+		Msg("process env set")
+}
+
+// PostResponseVariables returns the enabled entries of the "vars:post-response" section
+func (f BruFile) PostResponseVariables() []KeyValue {
+	return lo.Filter(f.postResponseVars, func(kv KeyValue, _ int) bool {
+		return kv.Enabled
+	})
+}
+
+// SetDir sets the directory of the Bru file, relative file paths of multipart forms are relative to it
+func (f *BruFile) SetDir(dir string) {
+	f.dir = dir
+}
+
+// SetRuntimeVariables sets the variables set by earlier requests of the run, which take precedence over all
+// other variables
+func (f *BruFile) SetRuntimeVariables(runtimeVars map[string]string) {
+	f.runtimeVars = runtimeVars
+	log.Debug().
+		Int("runtimeVars", len(f.runtimeVars)).
+		Msg("runtime variables set")
+}
+
+func (f BruFile) replaceVariables(str string) string {
+	str = replaceProcessEnvVariables(str, f.processEnv)
+	// Runtime variables take precedence over request variables, which take precedence over environment variables
+	str = replaceVariables(str, f.runtimeVars)
+	str = replaceVariables(str, f.requestVars)
+	return replaceVariables(str, f.vars)
+}
+
+// replaceProcessEnvVariables replaces "{{process.env.X}}" with the value of X in the process environment
+func replaceProcessEnvVariables(str string, processEnv map[string]string) string {
+	if len(processEnv) == 0 {
+		return str
+	}
+
+	return _processEnvRegex.ReplaceAllStringFunc(str, func(match string) string {
+		key := _processEnvRegex.FindStringSubmatch(match)[1]
+		value, ok := processEnv[key]
+		if !ok {
+			return match
+		}
+		log.Debug().
+			Str("key", key).
+			Msg("replaced variable from process env")
+		return value
+	})
+}
+
 func replaceVariables(str string, vars map[string]string) string {
 	if len(vars) == 0 {
 		log.Debug().
@@ -189,6 +393,45 @@ func replaceVariables(str string, vars map[string]string) string {
 	return str
 }
 
+// replacePathParams replaces the segments like ":id" of the URL path with the escaped values of the "params:path"
+// section. A segment whose param has an empty value is an error, rather than silently requesting another path.
+func replacePathParams(rawURL string, pathParams []KeyValue) (string, error) {
+	if len(pathParams) == 0 {
+		return rawURL, nil
+	}
+
+	urlPath, query, hasQuery := strings.Cut(rawURL, "?")
+	segments := strings.Split(urlPath, "/")
+	for i, segment := range segments {
+		for _, param := range pathParams {
+			if segment != ":"+param.Key {
+				continue
+			}
+			if param.Value == "" {
+				return "", fmt.Errorf("%w: '%s'", ErrEmptyPathParam, param.Key)
+			}
+			segments[i] = url.PathEscape(param.Value)
+		}
+	}
+	urlPath = strings.Join(segments, "/")
+	if hasQuery {
+		return urlPath + "?" + query, nil
+	}
+	return urlPath, nil
+}
+
+// RequestPath returns the path of the URL of a Bru file without the server and the query, e.g. "/users/:id" of
+// "{{baseUrl}}/users/:id?verbose=true" or of "https://{{host}}/users/:id"
+func RequestPath(rawURL string) string {
+	rawURL, _, _ = strings.Cut(rawURL, "?")
+	rawURL = _variablePrefixRegex.ReplaceAllString(rawURL, "")
+	rawURL = _serverOriginRegex.ReplaceAllString(rawURL, "")
+	if !strings.HasPrefix(rawURL, "/") {
+		rawURL = "/" + rawURL
+	}
+	return rawURL
+}
+
 func hasUnreplacedVariables(str string) bool {
 	return strings.Contains(str, "{{")
 }
@@ -3,6 +3,7 @@ package bruparser
 import (
 	"bytes"
 	_ "embed"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -35,3 +36,120 @@ func TestNewBruFile1(t *testing.T) {
 	require.Equal(t, "none", bruFile.req.auth)
 	require.Equal(t, "application/json", bruFile.headers["Content-Type"])
 }
+
+func TestBruFile_URL_ProcessEnv(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	bruFile, err := NewBruFile(strings.NewReader(`
+get {
+  url: {{process.env.HOST}}/{{process.env.PATH_NAME}}/{{version}}
+}
+`))
+	require.NoError(t, err)
+
+	bruFile.SetVariables(map[string]string{"HOST": "http://dotenv.example.com", "PATH_NAME": "users", "version": "v1"})
+	bruFile.SetProcessEnv(map[string]string{"HOST": "http://os.example.com"})
+	u, err := bruFile.URL()
+	require.NoError(t, err)
+	require.Equal(t, "http://os.example.com/users/v1", *u)
+}
+
+func TestBruFile_URL_PathParams(t *testing.T) {
+	t.Parallel()
+	bruFile, err := NewBruFile(strings.NewReader(`
+get {
+  url: {{baseUrl}}/users/:id/posts/:postId?fields=:id
+}
+
+params:path {
+  id: {{userId}}
+  postId: 7
+}
+`))
+	require.NoError(t, err)
+
+	bruFile.SetVariables(map[string]string{"baseUrl": "https://example.com:8080", "userId": "42"})
+	u, err := bruFile.URL()
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com:8080/users/42/posts/7?fields=:id", *u)
+}
+
+func TestBruFile_URL_PathParams_EscapedAndEmpty(t *testing.T) {
+	t.Parallel()
+	bruFile, err := NewBruFile(strings.NewReader(`
+get {
+  url: https://example.com/files/:name/:version
+}
+
+params:path {
+  name: {{fileName}}
+  version:
+}
+`))
+	require.NoError(t, err)
+
+	bruFile.SetVariables(map[string]string{"fileName": "a b/c"})
+	_, err = bruFile.URL()
+	require.ErrorIs(t, err, ErrEmptyPathParam)
+
+	bruFile, err = NewBruFile(strings.NewReader(`
+get {
+  url: https://example.com/files/:name
+}
+
+params:path {
+  name: {{fileName}}
+}
+`))
+	require.NoError(t, err)
+	_, err = bruFile.URL()
+	require.ErrorIs(t, err, ErrTemplateVariablesFound)
+	bruFile.SetVariables(map[string]string{"fileName": "a b/c"})
+	u, err := bruFile.URL()
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/files/a%20b%2Fc", *u)
+}
+
+func TestRequestPath(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]string{
+		"{{baseUrl}}/users/:id?verbose=true": "/users/:id",
+		"{{baseUrl}}":                        "/",
+		"{{host}}/users/{{id}}":              "/users/{{id}}",
+		"https://{{host}}/v1/users":          "/v1/users",
+		"http://localhost:8080":              "/",
+		"users":                              "/users",
+	}
+	for rawURL, expected := range testCases {
+		require.Equal(t, expected, RequestPath(rawURL), rawURL)
+	}
+}
+
+func TestNewBruFile_MockSections(t *testing.T) {
+	t.Parallel()
+	bruFile, err := NewBruFile(strings.NewReader(`
+get {
+  url: https://example.com/users
+}
+
+mock {
+  status: 200
+}
+
+mock:body {
+  {
+    "id": 1
+  }
+}
+`))
+	require.NoError(t, err)
+	require.Equal(t, "GET", bruFile.HttpMethod())
+}
+
+func TestBruFile_SetVariables_ZeroValue(t *testing.T) {
+	t.Parallel()
+	var bruFile BruFile
+	bruFile.SetVariables(map[string]string{"baseUrl": "https://example.com"})
+	bruFile.SetVariables(map[string]string{"userId": "42"})
+	require.Equal(t, map[string]string{"baseUrl": "https://example.com", "userId": "42"}, bruFile.Variables())
+}
@@ -0,0 +1,204 @@
+package bruparser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Boundary of multipart forms, fixed so that the body and ContentType agree
+const _multipartBoundary = "brux-form-boundary-7MA4YWxkTrZu0gW"
+
+var (
+	ErrUnsupportedBodyType = errors.New("unsupported body type")
+	ErrUnsupportedAuthMode = errors.New("unsupported auth mode")
+)
+
+// Multipart form values like "@file(a.png|b.png)" are files
+var _multipartFileRegex = regexp.MustCompile(`^@file\((.*)\)$`)
+
+// BodyType returns the type of the request body, e.g. "json" or "form-urlencoded", empty if there is none
+func (f BruFile) BodyType() string {
+	if f.req == nil || f.req.body == "none" {
+		return ""
+	}
+	return f.req.body
+}
+
+// AuthMode returns the auth mode of the request, e.g. "basic" or "bearer", empty if there is none
+func (f BruFile) AuthMode() string {
+	if f.req == nil || f.req.auth == "none" {
+		return ""
+	}
+	return f.req.auth
+}
+
+// RequestBody returns the request body with the variables replaced, nil if there is none
+func (f BruFile) RequestBody() (io.Reader, error) {
+	section, ok := f.bodies[f.BodyType()]
+	if !ok {
+		return nil, nil
+	}
+
+	switch f.BodyType() {
+	case "json", "text", "xml", "sparql":
+		body, err := f.replaceAllVariables(section.Text)
+		if err != nil {
+			return nil, err
+		}
+		return strings.NewReader(body), nil
+	case "graphql":
+		return f.graphQLBody(section)
+	case "form-urlencoded":
+		values := make([]string, 0, len(section.Entries))
+		for _, kv := range section.EnabledEntries() {
+			value, err := f.replaceAllVariables(kv.Value)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, url.QueryEscape(kv.Key)+"="+url.QueryEscape(value))
+		}
+		return strings.NewReader(strings.Join(values, "&")), nil
+	case "multipart-form":
+		return f.multipartBody(section)
+	default:
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedBodyType, f.BodyType())
+	}
+}
+
+// ContentType returns the content type of the request body, used unless set via the "headers" section
+func (f BruFile) ContentType() string {
+	switch f.BodyType() {
+	case "json", "graphql":
+		return "application/json"
+	case "text":
+		return "text/plain"
+	case "xml":
+		return "application/xml"
+	case "sparql":
+		return "application/sparql-query"
+	case "form-urlencoded":
+		return "application/x-www-form-urlencoded"
+	case "multipart-form":
+		return "multipart/form-data; boundary=" + _multipartBoundary
+	default:
+		return ""
+	}
+}
+
+func (f BruFile) graphQLBody(section Section) (io.Reader, error) {
+	query, err := f.replaceAllVariables(section.Text)
+	if err != nil {
+		return nil, err
+	}
+	body := map[string]any{"query": query}
+	if varsSection, ok := f.bodies["graphql:vars"]; ok && strings.TrimSpace(varsSection.Text) != "" {
+		variables, err := f.replaceAllVariables(varsSection.Text)
+		if err != nil {
+			return nil, err
+		}
+		body["variables"] = json.RawMessage(variables)
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GraphQL variables: %w", err)
+	}
+	return bytes.NewReader(data), nil
+}
+
+func (f BruFile) multipartBody(section Section) (io.Reader, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(_multipartBoundary); err != nil {
+		return nil, fmt.Errorf("invalid multipart boundary: %w", err)
+	}
+	for _, kv := range section.EnabledEntries() {
+		value, err := f.replaceAllVariables(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		match := _multipartFileRegex.FindStringSubmatch(value)
+		if match == nil {
+			if err := writer.WriteField(kv.Key, value); err != nil {
+				return nil, fmt.Errorf("could not write form field '%s': %w", kv.Key, err)
+			}
+			continue
+		}
+		for _, filePath := range strings.Split(match[1], "|") {
+			if err := f.writeMultipartFile(writer, kv.Key, filePath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("could not write multipart form: %w", err)
+	}
+	return &buf, nil
+}
+
+func (f BruFile) writeMultipartFile(writer *multipart.Writer, key string, filePath string) error {
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(f.dir, filePath)
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("could not read form file of '%s': %w", key, err)
+	}
+	part, err := writer.CreateFormFile(key, filepath.Base(filePath))
+	if err != nil {
+		return fmt.Errorf("could not write form file of '%s': %w", key, err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return fmt.Errorf("could not write form file of '%s': %w", key, err)
+	}
+	return nil
+}
+
+// authorization returns the value of the Authorization header for the auth mode, empty if there is none
+func (f BruFile) authorization() (string, error) {
+	mode := f.AuthMode()
+	section, ok := f.auths[mode]
+	if !ok {
+		// e.g. "inherit", which uses the auth of the collection
+		return "", nil
+	}
+
+	switch mode {
+	case "basic":
+		username, err := f.replaceAllVariables(section.Value("username"))
+		if err != nil {
+			return "", err
+		}
+		password, err := f.replaceAllVariables(section.Value("password"))
+		if err != nil {
+			return "", err
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	case "bearer":
+		token, err := f.replaceAllVariables(section.Value("token"))
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("%w: '%s'", ErrUnsupportedAuthMode, mode)
+	}
+}
+
+// replaceAllVariables replaces the variables, and fails if any of them is not defined
+func (f BruFile) replaceAllVariables(str string) (string, error) {
+	str = f.replaceVariables(str)
+	if hasUnreplacedVariables(str) {
+		return "", fmt.Errorf("%w: '%s'", ErrTemplateVariablesFound, str)
+	}
+	return str, nil
+}
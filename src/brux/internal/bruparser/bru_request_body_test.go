@@ -0,0 +1,92 @@
+package bruparser
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBruFile_RequestBody(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data.txt"), []byte("file contents"), 0o600))
+	testCases := []struct {
+		name                string
+		bodyType            string
+		body                string
+		expectedContentType string
+		expectedBody        []string
+	}{
+		{
+			name:                "json",
+			bodyType:            "json",
+			body:                "body:json {\n  {\"id\": {{id}}}\n}\n",
+			expectedContentType: "application/json",
+			expectedBody:        []string{`{"id": 42}`},
+		},
+		{
+			name:                "form-urlencoded",
+			bodyType:            "form-urlencoded",
+			body:                "body:form-urlencoded {\n  name: John Doe\n  ~skipped: true\n  id: {{id}}\n}\n",
+			expectedContentType: "application/x-www-form-urlencoded",
+			expectedBody:        []string{"name=John+Doe&id=42"},
+		},
+		{
+			name:                "multipart-form",
+			bodyType:            "multipart-form",
+			body:                "body:multipart-form {\n  id: {{id}}\n  file: @file(data.txt)\n}\n",
+			expectedContentType: "multipart/form-data; boundary=" + _multipartBoundary,
+			expectedBody:        []string{"name=\"id\"\r\n\r\n42\r\n", "filename=\"data.txt\"", "file contents"},
+		},
+		{
+			name:                "graphql",
+			bodyType:            "graphql",
+			body:                "body:graphql {\n  { user(id: {{id}}) { name } }\n}\n\nbody:graphql:vars {\n  {\"a\": 1}\n}\n",
+			expectedContentType: "application/json",
+			expectedBody:        []string{`{"query":"{ user(id: 42) { name } }","variables":{"a":1}}`},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			bruFile, err := NewBruFile(strings.NewReader(
+				"post {\n  url: https://example.com\n  body: " + testCase.bodyType + "\n}\n\n" + testCase.body))
+			require.NoError(t, err)
+			bruFile.SetVariables(map[string]string{"id": "42"})
+			bruFile.SetDir(dir)
+
+			body, err := bruFile.RequestBody()
+			require.NoError(t, err)
+			data, err := io.ReadAll(body)
+			require.NoError(t, err)
+			for _, expected := range testCase.expectedBody {
+				require.Contains(t, string(data), expected)
+			}
+			require.Equal(t, testCase.expectedContentType, bruFile.ContentType())
+		})
+	}
+}
+
+func TestBruFile_Headers_Auth(t *testing.T) {
+	t.Parallel()
+	bruFile, err := NewBruFile(strings.NewReader(`
+get {
+  url: https://example.com
+  auth: basic
+}
+
+auth:basic {
+  username: {{user}}
+  password: secret
+}
+`))
+	require.NoError(t, err)
+	bruFile.SetVariables(map[string]string{"user": "bob"})
+	headers, err := bruFile.Headers()
+	require.NoError(t, err)
+	require.Equal(t, "Basic Ym9iOnNlY3JldA==", headers.Get("Authorization"))
+}
@@ -3,6 +3,7 @@ package bruparser
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/rs/zerolog/log"
@@ -15,7 +16,16 @@ const (
 	_sectionRunning _State = "sectionRunning"
 )
 
-// Section is a struct that represents a Bru section
+// Sections whose contents are a block of text, e.g. JSON or JavaScript, instead of key-value pairs
+var _textSectionNames = []string{
+	"body:json", "body:text", "body:xml", "body:sparql", "body:graphql", "body:graphql:vars",
+	"script:pre-request", "script:post-response", "tests", "docs", "mock:body",
+}
+
+// Sections whose contents are a list of names, e.g. the names of the secret variables of an environment
+var _listSectionNames = []string{"vars:secret"}
+
+// Section is a Bru section, either a dictionary of key-value pairs, a list of names or a block of text
 // Example:
 //
 //	get {
@@ -23,60 +33,150 @@ const (
 //	 body: json
 //	 auth: none
 //	}
-type _Section struct {
-	sectionName   string
-	sectionValues map[string]string
-	sectionData   string
+type Section struct {
+	Name string
+	// Key-value pairs of a dictionary section in the order they appear in the file.
+	// The values are empty for list sections like "vars:secret".
+	Entries []KeyValue
+	// Contents of a text section like "body:json" or "tests", without the indentation
+	Text string
+}
+
+// IsText returns true for sections whose contents are a block of text, like "body:json" or "tests"
+func (s Section) IsText() bool {
+	return isTextSection(s.Name)
+}
+
+// IsList returns true for sections whose contents are a list of names, like "vars:secret"
+func (s Section) IsList() bool {
+	return slices.Contains(_listSectionNames, s.Name)
+}
+
+// Value returns the value of the last enabled entry with the key, empty if there is none
+func (s Section) Value(key string) string {
+	value := ""
+	for _, entry := range s.Entries {
+		if entry.Enabled && entry.Key == key {
+			value = entry.Value
+		}
+	}
+	return value
+}
+
+// EnabledEntries returns the entries that are not disabled via the "~" prefix
+func (s Section) EnabledEntries() []KeyValue {
+	return slices.DeleteFunc(slices.Clone(s.Entries), func(kv KeyValue) bool {
+		return !kv.Enabled
+	})
 }
 
 var (
 	ErrInvalidSectionStart = errors.New("invalid section start")
 	ErrInvalidKeyValuePair = errors.New("invalid key value pair")
+	ErrUnterminatedSection = errors.New("unterminated section")
 )
 
-func getSections(lines []string) ([]_Section, error) {
-	sectionList := make([]_Section, 0)
+func getSections(lines []string) ([]Section, error) {
+	sectionList := make([]Section, 0)
 	nextState := _sectionStart
-	currentSectionName := ""
-	currentSectionData := ""
-	currentSectionValues := make(map[string]string)
+	var currentSection Section
+	textLines := make([]string, 0)
 	for _, line := range lines {
+		line = strings.TrimRight(line, "\r\n")
 		switch nextState {
 		case _sectionStart:
-			if !strings.HasSuffix(strings.TrimSpace(line), "{") {
+			// Skip empty lines, comments and annotations between sections
+			if isEmptyLine(line) || isComment(line) || isAnnotation(line) {
+				continue
+			}
+			trimmedLine := strings.TrimSpace(line)
+			if !strings.HasSuffix(trimmedLine, "{") && !strings.HasSuffix(trimmedLine, "[") {
+				return nil, fmt.Errorf("%w: '%s'", ErrInvalidSectionStart, line)
+			}
+			currentSection = Section{
+				Name:    strings.TrimSpace(trimmedLine[:len(trimmedLine)-1]),
+				Entries: make([]KeyValue, 0),
+			}
+			if currentSection.IsList() != strings.HasSuffix(trimmedLine, "[") {
 				return nil, fmt.Errorf("%w: '%s'", ErrInvalidSectionStart, line)
 			}
-			currentSectionName = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "{"))
 			nextState = _sectionRunning
 		case _sectionRunning:
-			if strings.TrimSpace(line) == "}" && strings.HasPrefix(line, "}") {
+			if isSectionEnd(currentSection, line) {
 				nextState = _sectionStart
-				sectionList = append(sectionList, _Section{
-					sectionName:   currentSectionName,
-					sectionValues: currentSectionValues,
-					sectionData:   currentSectionData,
+				if currentSection.IsText() {
+					currentSection.Text = strings.Join(trimEmptyLines(textLines), "\n")
+					textLines = make([]string, 0)
+				}
+				sectionList = append(sectionList, currentSection)
+			} else if currentSection.IsText() {
+				// Text sections are indented by two spaces
+				textLines = append(textLines, trimIndent(line))
+			} else if isEmptyLine(line) || isComment(line) || isAnnotation(line) {
+				continue
+			} else if currentSection.IsList() {
+				name := strings.TrimSuffix(strings.TrimSpace(line), ",")
+				currentSection.Entries = append(currentSection.Entries, KeyValue{
+					Key:     strings.TrimPrefix(name, "~"),
+					Enabled: !strings.HasPrefix(name, "~"),
 				})
-				currentSectionName = ""
-				currentSectionData = ""
-				currentSectionValues = make(map[string]string)
-			} else if strings.TrimSpace(line) == "{" || currentSectionData != "" {
-				currentSectionData += line + "\n"
 			} else {
 				// parse key value pair
 				keyValue := strings.SplitN(line, ":", 2)
 				if len(keyValue) < 2 {
 					return nil, fmt.Errorf("invalid key value pair: '%s': %w", line, ErrInvalidKeyValuePair)
 				}
-				currentSectionValues[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+				key := strings.TrimSpace(keyValue[0])
+				currentSection.Entries = append(currentSection.Entries, KeyValue{
+					Key:     strings.TrimPrefix(key, "~"),
+					Value:   strings.TrimSpace(keyValue[1]),
+					Enabled: !strings.HasPrefix(key, "~"),
+				})
 			}
 		}
 
 		log.Trace().
 			Str("line", line).
-			Str("section", currentSectionName).
+			Str("section", currentSection.Name).
 			Str("state", string(nextState)).
-			Int("values", len(currentSectionValues)).
+			Int("values", len(currentSection.Entries)).
 			Msg("section")
 	}
+	if nextState == _sectionRunning {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnterminatedSection, currentSection.Name)
+	}
 	return sectionList, nil
 }
+
+// isSectionEnd returns true for the closing bracket at the start of a line, "]" for list sections and "}" otherwise
+func isSectionEnd(section Section, line string) bool {
+	end := "}"
+	if section.IsList() {
+		end = "]"
+	}
+	return strings.TrimSpace(line) == end && strings.HasPrefix(line, end)
+}
+
+func isTextSection(name string) bool {
+	return slices.Contains(_textSectionNames, name)
+}
+
+func trimIndent(line string) string {
+	for range 2 {
+		line = strings.TrimPrefix(line, " ")
+	}
+	return line
+}
+
+// trimEmptyLines removes the leading and trailing empty lines
+func trimEmptyLines(lines []string) []string {
+	start := 0
+	for start < len(lines) && isEmptyLine(lines[start]) {
+		start++
+	}
+	end := len(lines)
+	for end > start && isEmptyLine(lines[end-1]) {
+		end--
+	}
+	return lines[start:end]
+}
@@ -0,0 +1,324 @@
+package bruprinter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/mattn/go-isatty"
+
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
+)
+
+// Mode decides which parts of the response are printed
+type Mode string
+
+const (
+	// ModeDefault prints the status line, timing and the body
+	ModeDefault Mode = "default"
+	// ModeIncludeHeaders additionally prints the response headers
+	ModeIncludeHeaders Mode = "include-headers"
+	// ModeBodyOnly prints just the body
+	ModeBodyOnly Mode = "body-only"
+	// ModeNone prints nothing
+	ModeNone Mode = "none"
+)
+
+const (
+	_colorReset  = "\033[0m"
+	_colorRed    = "\033[31m"
+	_colorGreen  = "\033[32m"
+	_colorYellow = "\033[33m"
+	_colorBlue   = "\033[34m"
+	_colorCyan   = "\033[36m"
+	_colorGray   = "\033[90m"
+)
+
+// Printer prints HTTP responses to a terminal or a pipe
+type Printer struct {
+	out  io.Writer
+	mode Mode
+	// Colorize and pretty print the output, enabled when writing to a TTY
+	color bool
+}
+
+// NewPrinter creates a new Printer writing to out.
+// Colors and pretty printing are enabled only if out is a terminal.
+func NewPrinter(out io.Writer, mode Mode) *Printer {
+	return &Printer{
+		out:   out,
+		mode:  mode,
+		color: isTerminal(out),
+	}
+}
+
+// WithOutput returns a copy of the printer writing to out, keeping the colors decided by the original output
+func (p Printer) WithOutput(out io.Writer) *Printer {
+	p.out = out
+	return &p
+}
+
+// Output returns the writer the printer writes to
+func (p Printer) Output() io.Writer {
+	return p.out
+}
+
+// Response is the part of an HTTP response that gets printed
+type Response struct {
+	Proto    string
+	Status   string
+	Headers  http.Header
+	Body     []byte
+	Duration time.Duration
+	// Breakdown of Duration, e.g. DNS lookup and TLS handshake
+	Phases []Phase
+}
+
+// Phase is a named part of the request duration
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Print prints the response as per the printer's mode
+func (p Printer) Print(resp Response) error {
+	if p.mode == ModeNone {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if p.mode != ModeBodyOnly {
+		buf.WriteString(p.colorize(statusColor(resp.Status), resp.Proto+" "+resp.Status) + "\n")
+		if p.mode == ModeIncludeHeaders {
+			p.writeHeaders(&buf, resp.Headers)
+		}
+		buf.WriteString(p.colorize(_colorGray, "Elapsed: "+formatDuration(resp.Duration)+formatPhases(resp.Phases)) + "\n\n")
+	}
+
+	buf.Write(p.formatBody(resp.Body))
+	if len(resp.Body) > 0 && !bytes.HasSuffix(resp.Body, []byte("\n")) && p.color {
+		buf.WriteString("\n")
+	}
+
+	if _, err := p.out.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("could not print response: %w", err)
+	}
+	return nil
+}
+
+// PrintTitle prints a heading, e.g. the name of the request when running a collection
+func (p Printer) PrintTitle(title string) error {
+	if p.mode == ModeNone || p.mode == ModeBodyOnly {
+		return nil
+	}
+	if _, err := io.WriteString(p.out, p.colorize(_colorBlue, "▶ "+title)+"\n"); err != nil {
+		return fmt.Errorf("could not print title: %w", err)
+	}
+	return nil
+}
+
+// PrintSummary prints the number of passed and failed requests of a collection run
+func (p Printer) PrintSummary(passed int, failed int, duration time.Duration) error {
+	if p.mode == ModeNone {
+		return nil
+	}
+	summary := fmt.Sprintf("%d passed, %d failed in %s", passed, failed, formatDuration(duration))
+	color := _colorGreen
+	if failed > 0 {
+		color = _colorRed
+	}
+	if _, err := io.WriteString(p.out, "\n"+p.colorize(color, summary)+"\n"); err != nil {
+		return fmt.Errorf("could not print summary: %w", err)
+	}
+	return nil
+}
+
+// PrintAssertions prints the outcome of each assertion
+func (p Printer) PrintAssertions(results []bruexpr.AssertionResult) error {
+	if len(results) == 0 || p.mode == ModeNone || p.mode == ModeBodyOnly {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\n")
+	for _, result := range results {
+		if result.Passed {
+			buf.WriteString(p.colorize(_colorGreen, "✓ "+result.String()) + "\n")
+		} else {
+			buf.WriteString(p.colorize(_colorRed, "✗ "+result.String()) + "\n")
+		}
+	}
+	if _, err := p.out.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("could not print assertions: %w", err)
+	}
+	return nil
+}
+
+// PrintDiff prints a unified diff, e.g. of a snapshot and the response, with the removed and added lines colored
+func (p Printer) PrintDiff(title string, diff string) error {
+	return p.printDiff(p.colorize(_colorRed, "✗ "+title), diff)
+}
+
+// PrintChange prints a unified diff that is not a failure, e.g. the change of a response since the previous run
+func (p Printer) PrintChange(title string, diff string) error {
+	return p.printDiff(p.colorize(_colorBlue, "▶ "+title), diff)
+}
+
+func (p Printer) printDiff(title string, diff string) error {
+	if p.mode == ModeNone || p.mode == ModeBodyOnly {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\n" + title + "\n")
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "@@"):
+			line = p.colorize(_colorCyan, line)
+		case strings.HasPrefix(line, "-"):
+			line = p.colorize(_colorRed, line)
+		case strings.HasPrefix(line, "+"):
+			line = p.colorize(_colorGreen, line)
+		}
+		buf.WriteString(line + "\n")
+	}
+	if _, err := p.out.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("could not print diff: %w", err)
+	}
+	return nil
+}
+
+func (p Printer) writeHeaders(buf *bytes.Buffer, headers http.Header) {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range headers[k] {
+			buf.WriteString(p.colorize(_colorCyan, k) + ": " + v + "\n")
+		}
+	}
+}
+
+func (p Printer) formatBody(body []byte) []byte {
+	if !p.color || len(body) == 0 {
+		return body
+	}
+
+	if !mimetype.Detect(body).Is("application/json") {
+		return body
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, body, "", "  "); err != nil {
+		return body
+	}
+	buf.WriteString("\n")
+	return colorizeJSON(buf.Bytes())
+}
+
+func (p Printer) colorize(color string, str string) string {
+	if !p.color {
+		return str
+	}
+	return color + str + _colorReset
+}
+
+func formatPhases(phases []Phase) string {
+	if len(phases) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		parts = append(parts, phase.Name+" "+formatDuration(phase.Duration))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+func statusColor(status string) string {
+	switch {
+	case strings.HasPrefix(status, "2"):
+		return _colorGreen
+	case strings.HasPrefix(status, "3"):
+		return _colorYellow
+	default:
+		return _colorRed
+	}
+}
+
+// colorizeJSON adds ANSI colors to an already indented JSON document
+func colorizeJSON(data []byte) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			end := endOfString(data, i)
+			color := _colorGreen
+			if isKey(data, end) {
+				color = _colorBlue
+			}
+			buf.WriteString(color)
+			buf.Write(data[i:end])
+			buf.WriteString(_colorReset)
+			i = end - 1
+		case c == '-' || (c >= '0' && c <= '9'), c == 't', c == 'f', c == 'n':
+			end := i
+			for end < len(data) && !strings.ContainsRune(",]}\n ", rune(data[end])) {
+				end++
+			}
+			buf.WriteString(_colorYellow)
+			buf.Write(data[i:end])
+			buf.WriteString(_colorReset)
+			i = end - 1
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	return buf.Bytes()
+}
+
+// endOfString returns the index just past the closing quote of the string starting at start
+func endOfString(data []byte, start int) int {
+	for i := start + 1; i < len(data); i++ {
+		switch data[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+func isKey(data []byte, end int) bool {
+	for i := end; i < len(data); i++ {
+		if data[i] != ' ' {
+			return data[i] == ':'
+		}
+	}
+	return false
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
@@ -0,0 +1,64 @@
+package bruprinter
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrinter_Print(t *testing.T) {
+	t.Parallel()
+	resp := Response{
+		Proto:    "HTTP/1.1",
+		Status:   "200 OK",
+		Headers:  http.Header{"Content-Type": {"application/json"}},
+		Body:     []byte(`{"a":1}`),
+		Duration: 1500 * time.Millisecond,
+	}
+
+	tests := []struct {
+		mode     Mode
+		expected string
+	}{
+		{ModeDefault, "HTTP/1.1 200 OK\nElapsed: 1.5s\n\n{\"a\":1}"},
+		{ModeIncludeHeaders, "HTTP/1.1 200 OK\nContent-Type: application/json\nElapsed: 1.5s\n\n{\"a\":1}"},
+		{ModeBodyOnly, "{\"a\":1}"},
+		{ModeNone, ""},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			require.NoError(t, NewPrinter(&buf, tt.mode).Print(resp))
+			require.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestColorizeJSON(t *testing.T) {
+	t.Parallel()
+	colored := colorizeJSON([]byte(`{"key": "value", "n": -1.5, "ok": true}`))
+	require.Equal(t,
+		`{`+_colorBlue+`"key"`+_colorReset+`: `+_colorGreen+`"value"`+_colorReset+`, `+
+			_colorBlue+`"n"`+_colorReset+`: `+_colorYellow+`-1.5`+_colorReset+`, `+
+			_colorBlue+`"ok"`+_colorReset+`: `+_colorYellow+`true`+_colorReset+`}`,
+		string(colored))
+}
+
+func TestPrinter_PrintDiff(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, NewPrinter(&buf, ModeDefault).PrintDiff("snapshot does not match", "--- a\n+++ b\n-1\n+2\n"))
+	require.Equal(t, "\n✗ snapshot does not match\n--- a\n+++ b\n-1\n+2\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, NewPrinter(&buf, ModeBodyOnly).PrintDiff("snapshot does not match", "-1\n+2\n"))
+	require.Empty(t, buf.String())
+
+	buf.Reset()
+	require.NoError(t, NewPrinter(&buf, ModeDefault).PrintChange("response changed", "-1\n+2\n"))
+	require.Equal(t, "\n▶ response changed\n-1\n+2\n", buf.String())
+}
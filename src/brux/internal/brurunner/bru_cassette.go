@@ -0,0 +1,265 @@
+package brurunner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrCassetteMiss is returned when replaying a request that was not recorded, the runner wraps it in ErrTransport
+	// like any error sending the request
+	ErrCassetteMiss       = errors.New("no recorded response matches the request")
+	ErrInvalidCassetteDir = errors.New("invalid cassette dir")
+)
+
+const _cassetteFileSuffix = ".json"
+
+// CassetteOptions configures recording the responses to cassettes, or replaying them instead of sending the requests
+type CassetteOptions struct {
+	// Directory to save the request and response pairs to, disabled if empty
+	RecordDir string
+	// Directory to serve the responses from instead of the network, disabled if empty.
+	// Requests that were not recorded fail with ErrCassetteMiss.
+	ReplayDir string
+	// Names of the request headers that a recorded response must match as well, e.g. "Accept".
+	// Requests are always matched by method, URL and body.
+	MatchHeaders []string
+}
+
+func (o CassetteOptions) validate() error {
+	if o.RecordDir != "" && o.ReplayDir != "" {
+		return fmt.Errorf("%w: can not record and replay at the same time", ErrInvalidCassetteDir)
+	}
+	if o.ReplayDir != "" && !dirExists(o.ReplayDir) {
+		return fmt.Errorf("%w: '%s' does not exist", ErrInvalidCassetteDir, o.ReplayDir)
+	}
+	return nil
+}
+
+// _Cassette is a recorded request and response pair, saved as JSON
+type _Cassette struct {
+	Request  _CassetteRequest  `json:"request"`
+	Response _CassetteResponse `json:"response"`
+}
+
+type _CassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	// Only the headers of CassetteOptions.MatchHeaders
+	Headers    http.Header `json:"headers,omitempty"`
+	BodySHA256 string      `json:"bodySha256,omitempty"`
+}
+
+type _CassetteResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	// Text bodies as they are, binary bodies in BodyBase64
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"bodyBase64,omitempty"`
+}
+
+// _CassetteRoundTripper records the responses of the transport to the record dir, or serves the responses of the
+// replay dir without sending the requests
+type _CassetteRoundTripper struct {
+	options   CassetteOptions
+	transport http.RoundTripper
+}
+
+// newCassetteRoundTripper returns the transport as is unless recording or replaying
+func newCassetteRoundTripper(options CassetteOptions, transport http.RoundTripper) http.RoundTripper {
+	if options.RecordDir == "" && options.ReplayDir == "" {
+		return transport
+	}
+	return &_CassetteRoundTripper{options: options, transport: transport}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *_CassetteRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	cassetteRequest := r.newCassetteRequest(req, body)
+	fileName := cassetteRequest.fileName()
+
+	if r.options.ReplayDir != "" {
+		return r.replay(req, filepath.Join(r.options.ReplayDir, fileName))
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // Errors of the transport are classified for retries
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("could not read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	cassette := _Cassette{
+		Request:  cassetteRequest,
+		Response: _CassetteResponse{Status: resp.StatusCode, Headers: resp.Header},
+	}
+	if utf8.Valid(respBody) {
+		cassette.Response.Body = string(respBody)
+	} else {
+		cassette.Response.BodyBase64 = base64.StdEncoding.EncodeToString(respBody)
+	}
+	if err := writeCassette(filepath.Join(r.options.RecordDir, fileName), cassette); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// CloseIdleConnections closes the idle connections of the transport
+func (r *_CassetteRoundTripper) CloseIdleConnections() {
+	type closeIdler interface{ CloseIdleConnections() }
+	if transport, ok := r.transport.(closeIdler); ok {
+		transport.CloseIdleConnections()
+	}
+}
+
+func (r *_CassetteRoundTripper) replay(req *http.Request, filePath string) (*http.Response, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, req.Method, req.URL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read cassette: %w", err)
+	}
+	var cassette _Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("could not decode cassette '%s': %w", filePath, err)
+	}
+
+	body := []byte(cassette.Response.Body)
+	if cassette.Response.BodyBase64 != "" {
+		if body, err = base64.StdEncoding.DecodeString(cassette.Response.BodyBase64); err != nil {
+			return nil, fmt.Errorf("could not decode body of cassette '%s': %w", filePath, err)
+		}
+	}
+	log.Debug().
+		Str("cassetteFilePath", filePath).
+		Msg("replaying response")
+	headers := cassette.Response.Headers
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(cassette.Response.Status) + " " + http.StatusText(cassette.Response.Status),
+		StatusCode:    cassette.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *_CassetteRoundTripper) newCassetteRequest(req *http.Request, body []byte) _CassetteRequest {
+	cassetteRequest := _CassetteRequest{Method: req.Method, URL: req.URL.String()}
+	if len(body) > 0 {
+		hash := sha256.Sum256(body)
+		cassetteRequest.BodySHA256 = hex.EncodeToString(hash[:])
+	}
+	for _, name := range r.options.MatchHeaders {
+		if values := req.Header.Values(name); len(values) > 0 {
+			if cassetteRequest.Headers == nil {
+				cassetteRequest.Headers = make(http.Header)
+			}
+			cassetteRequest.Headers[http.CanonicalHeaderKey(name)] = values
+		}
+	}
+	return cassetteRequest
+}
+
+// _fileNameRegex matches the characters replaced in the names of the cassettes
+var _fileNameRegex = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// fileName returns the name of the cassette of the request, readable and unique per method, URL, body and matched
+// headers, e.g. "GET-api-example-com-users-1-3f2a9c1b04d5.json"
+func (r _CassetteRequest) fileName() string {
+	key := r.Method + "\n" + r.URL + "\n" + r.BodySHA256
+	for _, name := range slices.Sorted(maps.Keys(r.Headers)) {
+		key += "\n" + name + ": " + strings.Join(r.Headers[name], ", ")
+	}
+	hash := sha256.Sum256([]byte(key))
+
+	readable := r.URL
+	if _, rest, found := strings.Cut(readable, "://"); found {
+		readable = rest
+	}
+	readable = strings.Trim(_fileNameRegex.ReplaceAllString(readable, "-"), "-")
+	if len(readable) > 80 {
+		readable = readable[:80]
+	}
+	return r.Method + "-" + readable + "-" + hex.EncodeToString(hash[:6]) + _cassetteFileSuffix
+}
+
+// readRequestBody reads the body of the request without consuming it, nil if there is none
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		bodyReader, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("could not get request body: %w", err)
+		}
+		defer bodyReader.Close()
+		body, err := io.ReadAll(bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("could not read request body: %w", err)
+		}
+		return body, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read request body: %w", err)
+	}
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func writeCassette(filePath string, cassette _Cassette) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(cassette); err != nil {
+		return fmt.Errorf("could not marshal cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+		return fmt.Errorf("could not create cassette dir: %w", err)
+	}
+	if err := os.WriteFile(filePath, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("could not write cassette: %w", err)
+	}
+	log.Debug().
+		Str("cassetteFilePath", filePath).
+		Msg("response recorded")
+	return nil
+}
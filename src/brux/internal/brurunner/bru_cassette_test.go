@@ -0,0 +1,96 @@
+package brurunner
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func writePostRequest(t *testing.T, filePath string, url string, body string) {
+	t.Helper()
+	writeFile(t, filePath, "meta {\n  name: create\n  type: http\n}\n\npost {\n  url: "+url+
+		"\n  body: json\n}\n\nheaders {\n  Accept: application/json\n}\n\nbody:json {\n  "+body+"\n}\n")
+}
+
+func TestRun_Cassettes(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/users":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"created": ` + string(body) + `}`))
+		default:
+			w.Header().Set("Content-Type", "application/octet-stream")
+			_, _ = w.Write([]byte{0xff, 0x00, 0xfe})
+		}
+	}))
+
+	dir := t.TempDir()
+	cassetteDir := filepath.Join(dir, "cassettes")
+	postFilePath := filepath.Join(dir, "create.bru")
+	getFilePath := filepath.Join(dir, "binary.bru")
+	writePostRequest(t, postFilePath, server.URL+"/users", `{"name": "Ada"}`)
+	writeRequest(t, getFilePath, 1, server.URL+"/binary", "")
+
+	run := func(bruFilePath string, cassetteOptions CassetteOptions) *Result {
+		cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithCassettes(cassetteOptions))
+		require.NoError(t, err)
+		return Run(t.Context(), *cfg)
+	}
+	recordOptions := CassetteOptions{RecordDir: cassetteDir, MatchHeaders: []string{"accept"}}
+	recorded := []*Result{run(postFilePath, recordOptions), run(getFilePath, recordOptions)}
+	require.NoError(t, recorded[0].Err)
+	require.NoError(t, recorded[1].Err)
+	require.Equal(t, int32(2), requests.Load())
+	entries, err := os.ReadDir(cassetteDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// Replayed without the server
+	server.Close()
+	replayOptions := CassetteOptions{ReplayDir: cassetteDir, MatchHeaders: []string{"Accept"}}
+	for i, bruFilePath := range []string{postFilePath, getFilePath} {
+		result := run(bruFilePath, replayOptions)
+		require.NoError(t, result.Err)
+		require.Equal(t, recorded[i].Response.StatusCode, result.Response.StatusCode)
+		require.Equal(t, recorded[i].Response.Body, result.Response.Body)
+		require.Equal(t, recorded[i].Response.Headers.Get("Content-Type"), result.Response.Headers.Get("Content-Type"))
+	}
+	require.Equal(t, int32(2), requests.Load())
+
+	// A different body or matched header is not replayed
+	writePostRequest(t, postFilePath, server.URL+"/users", `{"name": "Grace"}`)
+	result := run(postFilePath, replayOptions)
+	require.ErrorIs(t, result.Err, ErrCassetteMiss)
+	require.ErrorIs(t, result.Err, ErrTransport)
+	require.Len(t, result.Attempts, 1)
+
+	writeFile(t, getFilePath, "get {\n  url: "+server.URL+"/binary\n}\n\nheaders {\n  Accept: text/plain\n}\n")
+	result = run(getFilePath, replayOptions)
+	require.ErrorIs(t, result.Err, ErrCassetteMiss)
+}
+
+func TestNewConfig_InvalidCassettes(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	bruFilePath := filepath.Join(dir, "get.bru")
+	writeRequest(t, bruFilePath, 1, "http://localhost", "")
+	_, err := NewConfig(bruFilePath, false, "", "", false, nil, WithCassettes(CassetteOptions{RecordDir: dir, ReplayDir: dir}))
+	require.ErrorIs(t, err, ErrInvalidCassetteDir)
+	_, err = NewConfig(bruFilePath, false, "", "", false, nil,
+		WithCassettes(CassetteOptions{ReplayDir: filepath.Join(dir, "missing")}))
+	require.ErrorIs(t, err, ErrInvalidCassetteDir)
+}
@@ -0,0 +1,76 @@
+package brurunner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+const _BrunoCollectionConfigFileName = "bruno.json"
+
+// _CollectionConfig is the subset of "bruno.json" used by brux
+// Ref: https://docs.usebruno.com/bru-lang/overview
+type _CollectionConfig struct {
+	Name               string              `json:"name"`
+	ClientCertificates _ClientCertificates `json:"clientCertificates"`
+	Proxy              *_ProxyConfig       `json:"proxy"`
+	// Directory containing "bruno.json", relative paths in the config are relative to it
+	rootDir string
+}
+
+type _ClientCertificates struct {
+	Enabled bool                `json:"enabled"`
+	Certs   []_ClientCertConfig `json:"certs"`
+}
+
+type _ClientCertConfig struct {
+	// Domain, optionally with wildcards, e.g. "*.example.com"
+	Domain string `json:"domain"`
+	// "cert" for PEM files or "pfx" for PKCS#12 files
+	Type         string `json:"type"`
+	CertFilePath string `json:"certFilePath"`
+	KeyFilePath  string `json:"keyFilePath"`
+	PfxFilePath  string `json:"pfxFilePath"`
+	Passphrase   string `json:"passphrase"`
+}
+
+// getCollectionConfig returns the config of the collection containing searchDir, nil if there is none
+func getCollectionConfig(searchDir string) (*_CollectionConfig, error) {
+	dir, err := filepath.Abs(searchDir)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path of '%s': %w", searchDir, err)
+	}
+
+	for !isBrunoCollectionRootDir(dir) {
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			log.Debug().
+				Str("searchDir", searchDir).
+				Msg("no bruno collection found")
+			return nil, nil
+		}
+		dir = parentDir
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, _BrunoCollectionConfigFileName))
+	if err != nil {
+		return nil, fmt.Errorf("could not read collection config: %w", err)
+	}
+
+	collectionConfig := &_CollectionConfig{rootDir: dir}
+	if err := json.Unmarshal(data, collectionConfig); err != nil {
+		return nil, fmt.Errorf("could not parse collection config: %w", err)
+	}
+	return collectionConfig, nil
+}
+
+// resolvePath resolves a path relative to the collection root dir
+func (c _CollectionConfig) resolvePath(filePath string) string {
+	if filePath == "" || filepath.IsAbs(filePath) {
+		return filePath
+	}
+	return filepath.Join(c.rootDir, filePath)
+}
@@ -0,0 +1,293 @@
+package brurunner
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+const (
+	_folderFileName     = "folder.bru"
+	_collectionFileName = "collection.bru"
+	// Setting of folder.bru to run the requests of the folder one after another,
+	// and of a request to run it after all the previous requests finished and before the next ones start
+	_sequentialSetting = "sequential"
+)
+
+// _WorkUnit is a request, or the requests of a sequential folder, run one after another by a single worker
+type _WorkUnit struct {
+	bruFilePaths []string
+	// Runs after all the previous units finished and before the next ones start, e.g. because it sets variables
+	barrier bool
+}
+
+// _CollectionItem is a request or a folder of a collection, sorted by seq and then name
+type _CollectionItem struct {
+	name  string
+	seq   int
+	units []_WorkUnit
+}
+
+// RunCollection runs all the requests in the directory of cfg and its sub-directories, ordered by seq.
+// Up to WithParallel requests are run concurrently, except requests that set variables via "vars:post-response",
+// or set "sequential" in their "settings", and folders that set "sequential" in the "settings" of folder.bru.
+// The results are in the order of the requests and are printed in that order as well.
+func RunCollection(ctx context.Context, cfg Config) []*Result {
+	start := time.Now()
+	units, err := getWorkUnits(cfg.bruFilePath)
+	if err != nil {
+		return []*Result{{FilePath: cfg.bruFilePath, Iteration: cfg.iteration, Err: fmt.Errorf("%w: could not read collection: %w", ErrParse, err)}}
+	}
+
+	count := 0
+	for _, unit := range units {
+		count += len(unit.bruFilePaths)
+	}
+	results := make([]*Result, count)
+	var output *_OrderedOutput
+	if cfg.printer != nil {
+		output = newOrderedOutput(cfg.printer.Output())
+	}
+
+	parallel := max(cfg.parallel, 1)
+	semaphore := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	index := 0
+	for _, unit := range units {
+		if unit.barrier {
+			wg.Wait()
+		}
+		select {
+		case <-ctx.Done():
+		case semaphore <- struct{}{}:
+			wg.Add(1)
+			go func(unit _WorkUnit, firstIndex int) {
+				defer wg.Done()
+				defer func() { <-semaphore }()
+				for i, bruFilePath := range unit.bruFilePaths {
+					results[firstIndex+i] = runCollectionRequest(ctx, cfg, bruFilePath, output, firstIndex+i)
+				}
+			}(unit, index)
+		}
+		if unit.barrier {
+			wg.Wait()
+		}
+		index += len(unit.bruFilePaths)
+	}
+	wg.Wait()
+
+	// Requests not started due to cancellation
+	index = 0
+	for _, unit := range units {
+		for _, bruFilePath := range unit.bruFilePaths {
+			if results[index] == nil {
+				results[index] = &Result{FilePath: bruFilePath, Iteration: cfg.iteration, Err: fmt.Errorf("not run: %w", context.Cause(ctx))}
+				output.done(index, nil)
+			}
+			index++
+		}
+	}
+
+	if cfg.printer != nil {
+		failed := lo.CountBy(results, func(result *Result) bool { return !result.Passed() })
+		if err := cfg.printer.PrintSummary(len(results)-failed, failed, time.Since(start)); err != nil {
+			log.Warn().
+				Err(err).
+				Msg("could not print summary")
+		}
+	}
+	return results
+}
+
+func runCollectionRequest(ctx context.Context, cfg Config, bruFilePath string, output *_OrderedOutput, index int) *Result {
+	log.Debug().
+		Str("bruFilePath", bruFilePath).
+		Int("index", index).
+		Msg("running request of collection")
+
+	requestCfg := cfg
+	requestCfg.bruFilePath = bruFilePath
+	if requestCfg.outputFilePath != _stdoutFilePath {
+		// Every request is saved to its own file
+		requestCfg.outputFilePath = ""
+	}
+
+	var buf bytes.Buffer
+	if cfg.printer != nil {
+		requestCfg.printer = cfg.printer.WithOutput(&buf)
+		if err := requestCfg.printer.PrintTitle(bruFilePath); err != nil {
+			log.Warn().
+				Err(err).
+				Msg("could not print title")
+		}
+	}
+
+	result := Run(ctx, requestCfg)
+	if result.Err != nil && cfg.printer != nil {
+		buf.WriteString("Error: " + result.Err.Error() + "\n")
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	output.done(index, buf.Bytes())
+	return result
+}
+
+// getWorkUnits returns the requests of the collection directory in the order they should run
+func getWorkUnits(dir string) ([]_WorkUnit, error) {
+	item, err := getFolderItem(dir)
+	if err != nil {
+		return nil, err
+	}
+	return item.units, nil
+}
+
+func getFolderItem(dir string) (*_CollectionItem, error) {
+	folder := &_CollectionItem{name: filepath.Base(dir)}
+	sequential := false
+	if folderFile, err := parseBruFile(filepath.Join(dir, _folderFileName)); err == nil {
+		folder.seq, _ = folderFile.Seq()
+		sequential, _ = strconv.ParseBool(folderFile.Settings()[_sequentialSetting])
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory '%s': %w", dir, err)
+	}
+
+	requests := make([]_CollectionItem, 0)
+	folders := make([]_CollectionItem, 0)
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir() && !bruparser.IsIgnoredDir(entry.Name()):
+			subFolder, err := getFolderItem(entryPath)
+			if err != nil {
+				return nil, err
+			}
+			folders = append(folders, *subFolder)
+		case !entry.IsDir() && filepath.Ext(entry.Name()) == ".bru" &&
+			entry.Name() != _folderFileName && entry.Name() != _collectionFileName:
+			request, err := getRequestItem(entryPath)
+			if err != nil {
+				return nil, err
+			}
+			if request != nil {
+				requests = append(requests, *request)
+			}
+		}
+	}
+
+	// Requests of a folder run before its sub-folders
+	sortItems(requests)
+	sortItems(folders)
+	for _, item := range slices.Concat(requests, folders) {
+		folder.units = append(folder.units, item.units...)
+	}
+	if sequential && len(folder.units) > 0 {
+		unit := _WorkUnit{}
+		for _, u := range folder.units {
+			unit.bruFilePaths = append(unit.bruFilePaths, u.bruFilePaths...)
+			unit.barrier = unit.barrier || u.barrier
+		}
+		folder.units = []_WorkUnit{unit}
+	}
+	return folder, nil
+}
+
+// getRequestItem returns nil if the file is not a request
+func getRequestItem(bruFilePath string) (*_CollectionItem, error) {
+	bruFile, err := parseBruFile(bruFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if !bruFile.IsRequest() {
+		return nil, nil
+	}
+
+	seq, _ := bruFile.Seq()
+	sequential, _ := strconv.ParseBool(bruFile.Settings()[_sequentialSetting])
+	return &_CollectionItem{
+		name: filepath.Base(bruFilePath),
+		seq:  seq,
+		units: []_WorkUnit{{
+			bruFilePaths: []string{bruFilePath},
+			barrier:      sequential || len(bruFile.PostResponseVariables()) > 0,
+		}},
+	}, nil
+}
+
+func parseBruFile(bruFilePath string) (*bruparser.BruFile, error) {
+	f, err := os.Open(bruFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+	bruFile, err := bruparser.NewBruFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse '%s': %w", bruFilePath, err)
+	}
+	return bruFile, nil
+}
+
+func sortItems(items []_CollectionItem) {
+	slices.SortStableFunc(items, func(a, b _CollectionItem) int {
+		return cmp.Or(cmp.Compare(a.seq, b.seq), strings.Compare(a.name, b.name))
+	})
+}
+
+// _OrderedOutput writes the outputs of concurrently run requests in the order of the requests
+type _OrderedOutput struct {
+	mu      sync.Mutex
+	out     io.Writer
+	next    int
+	pending map[int][]byte
+}
+
+func newOrderedOutput(out io.Writer) *_OrderedOutput {
+	return &_OrderedOutput{out: out, pending: make(map[int][]byte)}
+}
+
+// done records the output of the request at index, and writes all the outputs that are no longer waiting on an
+// earlier request
+func (o *_OrderedOutput) done(index int, data []byte) {
+	if o == nil {
+		return
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.pending[index] = data
+	for {
+		data, ok := o.pending[o.next]
+		if !ok {
+			return
+		}
+		if _, err := o.out.Write(data); err != nil {
+			log.Warn().
+				Err(err).
+				Msg("could not write output")
+		}
+		delete(o.pending, o.next)
+		o.next++
+	}
+}
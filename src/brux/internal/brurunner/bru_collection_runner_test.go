@@ -0,0 +1,109 @@
+package brurunner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/bruprinter"
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func writeFile(t *testing.T, filePath string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o750))
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+}
+
+func writeRequest(t *testing.T, filePath string, seq int, url string, extra string) {
+	t.Helper()
+	writeFile(t, filePath, fmt.Sprintf("meta {\n  name: %s\n  type: http\n  seq: %d\n}\n\nget {\n  url: %s\n}\n%s",
+		filepath.Base(filePath), seq, url, extra))
+}
+
+func TestRunCollection(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	var inFlight, maxInFlight atomic.Int32
+	var mu sync.Mutex
+	paths := make([]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"token": "secret"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"name": "test"}`)
+	writeRequest(t, filepath.Join(dir, "login.bru"), 1, server.URL+"/login", "\nvars:post-response {\n  token: res.body.token\n}\n")
+	for i := 2; i <= 5; i++ {
+		writeRequest(t, filepath.Join(dir, fmt.Sprintf("get%d.bru", i)), i, server.URL+"/{{token}}", "")
+	}
+	writeFile(t, filepath.Join(dir, "folder", "folder.bru"), "meta {\n  name: folder\n}\n\nsettings {\n  sequential: true\n}\n")
+	writeRequest(t, filepath.Join(dir, "folder", "b.bru"), 2, server.URL+"/b", "")
+	writeRequest(t, filepath.Join(dir, "folder", "a.bru"), 1, server.URL+"/a", "")
+	writeFile(t, filepath.Join(dir, "environments", "local.bru"), "vars {\n  host: localhost\n}\n")
+
+	var out bytes.Buffer
+	cfg, err := NewConfig(dir, false, "", "", false, nil,
+		WithParallel(4), WithPrinter(bruprinter.NewPrinter(&out, bruprinter.ModeBodyOnly)))
+	require.NoError(t, err)
+	results := RunCollection(t.Context(), *cfg)
+
+	require.Len(t, results, 7)
+	expectedFiles := []string{"login.bru", "get2.bru", "get3.bru", "get4.bru", "get5.bru", "a.bru", "b.bru"}
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		require.Equal(t, expectedFiles[i], filepath.Base(result.FilePath))
+	}
+	// The login request sets the token for the later requests, so it runs first
+	require.Equal(t, "/login", paths[0])
+	require.Equal(t, "/secret", results[1].Request.URL[len(server.URL):])
+	require.Greater(t, maxInFlight.Load(), int32(1))
+	require.LessOrEqual(t, maxInFlight.Load(), int32(4))
+	// The output is in the order of the requests
+	require.Equal(t, 7, strings.Count(out.String(), `{"token": "secret"}`))
+}
+
+func TestRunCollection_Cancel(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	ctx, cancel := context.WithCancel(t.Context())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	for i := 1; i <= 3; i++ {
+		writeRequest(t, filepath.Join(dir, fmt.Sprintf("get%d.bru", i)), i, server.URL, "")
+	}
+	cfg, err := NewConfig(dir, false, "", "", false, nil)
+	require.NoError(t, err)
+	results := RunCollection(ctx, *cfg)
+	require.Len(t, results, 3)
+	require.ErrorIs(t, results[2].Err, context.Canceled)
+}
@@ -0,0 +1,167 @@
+package brurunner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// _CookieJar is a cookie jar shared by all the requests of a run, optionally persisted to a file between runs.
+// cookiejar.Jar does not expose the stored cookies, so the cookies set are recorded for persistence as well.
+type _CookieJar struct {
+	jar *cookiejar.Jar
+	// Empty if the cookies are not persisted
+	filePath string
+
+	mu      sync.Mutex
+	entries map[string]_CookieJarEntry
+}
+
+// _CookieJarEntry is a cookie along with the URL that set it
+type _CookieJarEntry struct {
+	URL    string       `json:"url"`
+	Cookie *http.Cookie `json:"cookie"`
+}
+
+func newCookieJar(filePath string) (*_CookieJar, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create cookie jar: %w", err)
+	}
+
+	cookieJar := &_CookieJar{
+		jar:      jar,
+		filePath: filePath,
+		entries:  make(map[string]_CookieJarEntry),
+	}
+	if err := cookieJar.load(); err != nil {
+		return nil, err
+	}
+	return cookieJar, nil
+}
+
+// SetCookies implements http.CookieJar
+func (j *_CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, cookie := range cookies {
+		key := u.Hostname() + ";" + cookie.Domain + ";" + cookie.Path + ";" + cookie.Name
+		// Max-Age is relative to now, so persist the absolute expiry instead
+		persisted := *cookie
+		if persisted.MaxAge > 0 {
+			persisted.Expires = time.Now().Add(time.Duration(persisted.MaxAge) * time.Second)
+			persisted.MaxAge = 0
+		}
+		j.entries[key] = _CookieJarEntry{URL: u.String(), Cookie: &persisted}
+	}
+}
+
+// Cookies implements http.CookieJar
+func (j *_CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.jar.Cookies(u)
+}
+
+func (j *_CookieJar) load() error {
+	if j.filePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(j.filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Debug().
+			Str("filePath", j.filePath).
+			Msg("cookie jar file does not exist yet")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read cookie jar: %w", err)
+	}
+
+	var entries []_CookieJarEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("could not parse cookie jar '%s': %w", j.filePath, err)
+	}
+	for _, entry := range entries {
+		u, err := url.Parse(entry.URL)
+		if err != nil || entry.Cookie == nil {
+			log.Warn().
+				Str("url", entry.URL).
+				Msg("skipping invalid cookie jar entry")
+			continue
+		}
+		j.SetCookies(u, []*http.Cookie{entry.Cookie})
+	}
+	log.Debug().
+		Str("filePath", j.filePath).
+		Int("cookies", len(entries)).
+		Msg("cookie jar loaded")
+	return nil
+}
+
+// save writes the unexpired cookies to the file, if any.
+// The lock is held during the write and the file is replaced atomically, so that parallel requests don't interleave.
+func (j *_CookieJar) save() error {
+	if j.filePath == "" {
+		return nil
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	keys := slices.Sorted(maps.Keys(j.entries))
+	entries := make([]_CookieJarEntry, 0, len(keys))
+	for _, key := range keys {
+		entry := j.entries[key]
+		if entry.Cookie.MaxAge < 0 || (!entry.Cookie.Expires.IsZero() && entry.Cookie.Expires.Before(now)) {
+			delete(j.entries, key)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not serialize cookie jar: %w", err)
+	}
+	if err := writeFileAtomically(j.filePath, data); err != nil {
+		return fmt.Errorf("could not write cookie jar: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomically writes to a temporary file in the same directory and renames it, so that readers never see
+// a partially written file
+func writeFileAtomically(filePath string, data []byte) error {
+	// Created with mode 0o600
+	f, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), filePath); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
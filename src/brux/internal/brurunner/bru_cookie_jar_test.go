@@ -0,0 +1,134 @@
+package brurunner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func newLoginServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", Path: "/", MaxAge: 3600})
+		http.Redirect(w, r, "/me", http.StatusFound)
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun_CookieJarFile(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := newLoginServer(t)
+	dir := t.TempDir()
+	cookieJarFilePath := filepath.Join(dir, "cookies.json")
+
+	loginFilePath := writeBruFile(t, dir, server.URL+"/login")
+	cfg, err := NewConfig(loginFilePath, false, "", "", false, nil, WithCookieJarFile(cookieJarFilePath))
+	require.NoError(t, err)
+	result := Run(t.Context(), *cfg)
+	require.NoError(t, result.Err)
+	require.Equal(t, http.StatusOK, result.Response.StatusCode)
+	require.FileExists(t, cookieJarFilePath)
+
+	// A new run loads the session cookie from the file
+	meDir := filepath.Join(dir, "me")
+	require.NoError(t, os.Mkdir(meDir, 0o750))
+	meFilePath := writeBruFile(t, meDir, server.URL+"/me")
+	cfg, err = NewConfig(meFilePath, false, "", "", false, nil, WithCookieJarFile(cookieJarFilePath))
+	require.NoError(t, err)
+	result = Run(t.Context(), *cfg)
+	require.NoError(t, result.Err)
+	require.Equal(t, http.StatusOK, result.Response.StatusCode)
+
+	cfg, err = NewConfig(meFilePath, false, "", "", false, nil)
+	require.NoError(t, err)
+	result = Run(t.Context(), *cfg)
+	require.NoError(t, result.Err)
+	require.Equal(t, http.StatusUnauthorized, result.Response.StatusCode)
+}
+
+func TestCookieJar_SaveConcurrently(t *testing.T) {
+	t.Parallel()
+	cookieJarFilePath := filepath.Join(t.TempDir(), "cookies.json")
+	jar, err := newCookieJar(cookieJarFilePath)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := range 20 {
+		wg.Go(func() {
+			u := &url.URL{Scheme: "https", Host: fmt.Sprintf("host%d.example.com", i), Path: "/"}
+			jar.SetCookies(u, []*http.Cookie{{Name: "session", Value: strings.Repeat("a", 1000), Path: "/"}})
+			errs <- jar.save()
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	// The file is valid JSON with every cookie, and no temporary file is left behind
+	loaded, err := newCookieJar(cookieJarFilePath)
+	require.NoError(t, err)
+	require.Len(t, loaded.entries, 20)
+	entries, err := os.ReadDir(filepath.Dir(cookieJarFilePath))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestRun_Redirects(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := newLoginServer(t)
+
+	tests := []struct {
+		name       string
+		settings   string
+		opts       []Option
+		statusCode int
+		err        error
+	}{
+		{"follow", "", nil, http.StatusOK, nil},
+		{"no-follow flag", "", []Option{WithNoFollowRedirects(true)}, http.StatusFound, nil},
+		{"followRedirects setting", "followRedirects: false", nil, http.StatusFound, nil},
+		{"followRedirects setting overrides flag", "followRedirects: true", []Option{WithNoFollowRedirects(true)}, http.StatusOK, nil},
+		{"maxRedirects setting", "maxRedirects: 0", nil, 0, ErrTransport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			bruFilePath := filepath.Join(t.TempDir(), "login.bru")
+			require.NoError(t, os.WriteFile(bruFilePath, []byte("get {\n  url: "+server.URL+"/login\n}\n\nsettings {\n  "+tt.settings+"\n}\n"), 0o600))
+			cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, tt.opts...)
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+			if tt.err != nil {
+				require.ErrorIs(t, result.Err, tt.err)
+				return
+			}
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.statusCode, result.Response.StatusCode)
+		})
+	}
+}
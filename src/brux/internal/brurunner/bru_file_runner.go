@@ -1,6 +1,7 @@
 package brurunner
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -9,31 +10,205 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
 	"github.com/ashishb/brux/src/brux/internal/bruparser"
+	"github.com/ashishb/brux/src/brux/internal/bruprinter"
 )
 
-func Run(ctx context.Context, cfg Config) error {
+// Run runs the Bru file of cfg.
+// The returned result is never nil, Result.Err is set if the run failed.
+func Run(ctx context.Context, cfg Config) *Result {
+	result := &Result{FilePath: cfg.bruFilePath, Iteration: cfg.iteration}
 	bruFile, err := cfg.getBruFile()
 	if err != nil {
-		return fmt.Errorf("could not get bru file: %w", err)
+		result.Err = fmt.Errorf("%w: could not get bru file: %w", ErrParse, err)
+		return result
 	}
 
-	return run(ctx, cfg, bruFile)
+	result.Err = run(ctx, cfg, bruFile, result)
+	return result
 }
 
-func run(ctx context.Context, cfg Config, bruObj *bruparser.BruFile) error {
+func run(ctx context.Context, cfg Config, bruObj *bruparser.BruFile, result *Result) error {
+	prepared, err := prepareRequest(bruObj)
+	if err != nil {
+		return err
+	}
+	assertions, err := bruObj.Assertions()
+	if err != nil {
+		return fmt.Errorf("%w: could not get assertions: %w", ErrParse, err)
+	}
+	settings, err := getRequestSettings(bruObj)
+	if err != nil {
+		return fmt.Errorf("%w: could not get settings: %w", ErrParse, err)
+	}
+	retryPolicy, err := settings.getRetryPolicy(cfg)
+	if err != nil {
+		return fmt.Errorf("%w: could not get retry policy: %w", ErrParse, err)
+	}
+	schema, err := settings.getSchema(cfg)
+	if err != nil {
+		return fmt.Errorf("%w: could not get schema: %w", ErrParse, err)
+	}
+
+	result.Request = RequestInfo{
+		Method:  prepared.method,
+		URL:     prepared.url,
+		Headers: prepared.headers,
+		Body:    prepared.body,
+	}
+	if err := sendWithRetries(ctx, cfg.newHTTPClient(settings), prepared, retryPolicy, result); err != nil {
+		return err
+	}
+
+	resp := result.Response
+	exprResponse := bruexpr.Response{
+		Status:       resp.StatusCode,
+		Headers:      resp.Headers,
+		Body:         resp.Body,
+		ResponseTime: result.Timings.Total,
+	}
+	result.Assertions = evaluateAssertions(assertions, exprResponse)
+	if schema != nil {
+		result.Assertions = append(result.Assertions, schema.validate(resp.Body)...)
+	}
+	if cfg.openAPISpec != nil {
+		result.Assertions = append(result.Assertions,
+			cfg.openAPISpec.Check(prepared.method, prepared.url, resp.StatusCode, resp.Headers, resp.Body)...)
+	}
+	varsErr := cfg.runtimeVariables.setFromResponse(bruObj.PostResponseVariables(), exprResponse)
+	output, err := cfg.filterOutput(exprResponse)
+	if err != nil {
+		return err
+	}
+	if cfg.printer != nil {
+		if err := cfg.printer.Print(bruprinter.Response{
+			Proto:    resp.Proto,
+			Status:   resp.Status,
+			Headers:  resp.Headers,
+			Body:     output,
+			Duration: result.Timings.Total,
+			Phases:   result.Timings.Phases(),
+		}); err != nil {
+			return err
+		}
+		if err := cfg.printer.PrintAssertions(result.Assertions); err != nil {
+			return err
+		}
+	}
+	snapshotErr := cfg.checkSnapshot(resp)
+	if err := cfg.maybeSaveOutput(output); err != nil {
+		return err
+	}
+	if err := cfg.cookieJar.save(); err != nil {
+		return err
+	}
+
+	if cfg.failOnErrorStatus && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		return fmt.Errorf("%w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+	if varsErr != nil {
+		// No assertion failed, the "vars:post-response" section is invalid
+		return fmt.Errorf("%w: %w", ErrParse, varsErr)
+	}
+	if failed := result.FailedAssertions(); len(failed) > 0 {
+		return fmt.Errorf("%w: %d of %d assertions failed", ErrAssertionFailed, len(failed), len(result.Assertions))
+	}
+	return snapshotErr
+}
+
+// _PreparedRequest has the variables replaced and the body buffered, so that it can be sent multiple times
+type _PreparedRequest struct {
+	method  string
+	url     string
+	headers http.Header
+	// nil if there is no body
+	body []byte
+}
+
+func prepareRequest(bruObj *bruparser.BruFile) (*_PreparedRequest, error) {
 	u1, err := bruObj.URL()
 	if err != nil {
-		return fmt.Errorf("could not get URL: %w", err)
+		return nil, fmt.Errorf("%w: could not get URL: %w", ErrParse, err)
 	}
 	reqBody, err := bruObj.RequestBody()
 	if err != nil {
-		return fmt.Errorf("could not get request body: %w", err)
+		return nil, fmt.Errorf("%w: could not get request body: %w", ErrParse, err)
+	}
+	headers, err := bruObj.Headers()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get headers: %w", ErrParse, err)
+	}
+
+	prepared := &_PreparedRequest{
+		method:  bruObj.HttpMethod(),
+		url:     *u1,
+		headers: headers,
+	}
+	if reqBody != nil {
+		if prepared.body, err = io.ReadAll(reqBody); err != nil {
+			return nil, fmt.Errorf("%w: could not read request body: %w", ErrParse, err)
+		}
+		if headers.Get("Content-Type") == "" && bruObj.ContentType() != "" {
+			headers.Set("Content-Type", bruObj.ContentType())
+		}
+	}
+	return prepared, nil
+}
+
+func (p _PreparedRequest) newRequest(ctx context.Context) (*http.Request, error) {
+	var body io.Reader
+	if p.body != nil {
+		body = bytes.NewReader(p.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, p.method, p.url, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not create request: %w", ErrParse, err)
 	}
+	req.Header = p.headers.Clone()
+	return req, nil
+}
+
+// sendWithRetries sends the request until it succeeds or the retry policy gives up.
+// The response and timings of the last attempt are stored in result.
+func sendWithRetries(ctx context.Context, client *http.Client, prepared *_PreparedRequest, retryPolicy *_RetryPolicy, result *Result) error {
+	for number := 1; ; number++ {
+		resp, timings, err := send(ctx, client, prepared)
+		attempt := Attempt{Number: number, Timings: timings, Err: err}
+		if resp != nil {
+			attempt.StatusCode = resp.StatusCode
+		}
+		result.Response, result.Timings = resp, timings
 
-	req, err := http.NewRequestWithContext(ctx, bruObj.HttpMethod(), *u1, reqBody)
+		retry, delay := retryPolicy.shouldRetry(ctx, prepared.method, number, resp, err)
+		if retry {
+			attempt.RetryDelay = delay
+		}
+		result.Attempts = append(result.Attempts, attempt)
+		if !retry {
+			return err
+		}
+
+		log.Warn().
+			Err(err).
+			Int("statusCode", attempt.StatusCode).
+			Int("attempt", number).
+			Dur("delay", delay).
+			Msg("retrying request")
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: retry cancelled: %w", ErrTransport, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send sends the request once
+func send(ctx context.Context, client *http.Client, prepared *_PreparedRequest) (*ResponseInfo, Timings, error) {
+	trace := &_RequestTrace{}
+	req, err := prepared.newRequest(trace.withContext(ctx))
 	if err != nil {
-		return fmt.Errorf("could not create request: %w", err)
+		return nil, Timings{}, err
 	}
 
 	log.Debug().
@@ -41,27 +216,42 @@ func run(ctx context.Context, cfg Config, bruObj *bruparser.BruFile) error {
 		Str("method", req.Method).
 		Msg("requesting")
 
-	req.Header, err = bruObj.Headers()
-	if err != nil {
-		return fmt.Errorf("could not get headers: %w", err)
-	}
-
-	client := &http.Client{
-		Timeout: 300 * time.Second,
-	}
+	start := time.Now()
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("could not make request: %w", err)
+		return nil, trace.timings(start, time.Now()), fmt.Errorf("%w: could not make request: %w", ErrTransport, err)
 	}
 
 	defer resp.Body.Close()
 	data, err := io.ReadAll(resp.Body)
+	timings := trace.timings(start, time.Now())
 	if err != nil {
-		return fmt.Errorf("could not read response body: %w", err)
+		return nil, timings, fmt.Errorf("%w: could not read response body: %w", ErrTransport, err)
 	}
 
 	log.Debug().
 		Int("response", len(data)).
+		Dur("duration", timings.Total).
+		Dur("ttfb", timings.TimeToFirstByte).
 		Msg("response received")
-	return cfg.maybeSaveOutput(data)
+	return &ResponseInfo{
+		Proto:      resp.Proto,
+		Status:     resp.Status,
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header,
+		Body:       data,
+	}, timings, nil
+}
+
+func evaluateAssertions(assertions []bruparser.KeyValue, resp bruexpr.Response) []bruexpr.AssertionResult {
+	results := make([]bruexpr.AssertionResult, 0, len(assertions))
+	for _, assertion := range assertions {
+		result := bruexpr.Assert(resp, assertion.Key, assertion.Value)
+		log.Debug().
+			Stringer("assertion", result).
+			Bool("passed", result.Passed).
+			Msg("assertion evaluated")
+		results = append(results, result)
+	}
+	return results
 }
@@ -0,0 +1,77 @@
+package brurunner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// newTransport creates the transport shared by all the requests of a run
+func (cfg Config) newTransport() (http.RoundTripper, error) {
+	collectionConfig, err := getCollectionConfig(cfg.searchDir())
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := cfg.tlsOptions.newTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not create TLS config: %w", err)
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default transport type %T", http.DefaultTransport)
+	}
+	proxySelector, err := newProxySelector(cfg.proxyOptions, collectionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not configure proxy: %w", err)
+	}
+
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxySelector.proxy
+	// Keep a connection per concurrent request alive for reuse
+	transport.MaxIdleConnsPerHost = max(cfg.parallel, http.DefaultMaxIdleConnsPerHost)
+	return newClientCertRoundTripper(transport, collectionConfig)
+}
+
+// newHTTPClient creates a client for a single request, sharing the transport and the cookie jar of the run
+func (cfg Config) newHTTPClient(settings *_RequestSettings) *http.Client {
+	return &http.Client{
+		Transport:     cfg.transport,
+		Jar:           cfg.cookieJar,
+		CheckRedirect: settings.checkRedirect(cfg),
+		Timeout:       settings.getTimeout(cfg),
+	}
+}
+
+// _HandlerRoundTripper serves the requests by an http.Handler in-process, without a server
+type _HandlerRoundTripper struct {
+	handler http.Handler
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *_HandlerRoundTripper) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	// Handlers expect the fields of server requests
+	serverReq := req.Clone(req.Context())
+	serverReq.RequestURI = req.URL.RequestURI()
+	serverReq.RemoteAddr = "127.0.0.1:0"
+	if serverReq.Host == "" {
+		serverReq.Host = req.URL.Host
+	}
+	if serverReq.Body == nil {
+		serverReq.Body = http.NoBody
+	}
+
+	defer func() {
+		// Like http.Server, a panic fails the request only
+		if recovered := recover(); recovered != nil {
+			resp, err = nil, fmt.Errorf("handler panicked: %v", recovered)
+		}
+	}()
+	recorder := httptest.NewRecorder()
+	r.handler.ServeHTTP(recorder, serverReq)
+	resp = recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
@@ -0,0 +1,163 @@
+package brurunner
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrInvalidIterationData = errors.New("invalid iteration data")
+
+// IterationOptions configures running a file or collection multiple times
+type IterationOptions struct {
+	// CSV file with a header row, or JSON file with an array of objects.
+	// Every row is an iteration and its columns are available as variables.
+	DataFilePath string
+	// Number of iterations, defaults to the number of rows of the data file, or 1 without a data file.
+	// The rows are reused from the start if there are more iterations than rows.
+	Iterations int
+}
+
+// iterationCount returns the number of iterations to run
+func (cfg Config) iterationCount() int {
+	if cfg.iterationOptions.Iterations > 0 {
+		return cfg.iterationOptions.Iterations
+	}
+	return max(len(cfg.iterationData), 1)
+}
+
+// RunIterations runs the Bru file, or the collection if cfg is a directory, once per iteration.
+// Results of every iteration are tagged with the iteration index, starting at 1,
+// unless there is a single iteration without a data file.
+func RunIterations(ctx context.Context, cfg Config) []*Result {
+	count := cfg.iterationCount()
+	if count == 1 && len(cfg.iterationData) == 0 {
+		return runOnce(ctx, cfg)
+	}
+
+	results := make([]*Result, 0, count)
+	for i := range count {
+		if ctx.Err() != nil {
+			log.Warn().
+				Int("iteration", i+1).
+				Msg("run cancelled, skipping the remaining iterations")
+			break
+		}
+
+		iterationCfg := cfg
+		iterationCfg.iteration = i + 1
+		// The variables set by the requests of an iteration don't leak into the next one, e.g. over its data row
+		iterationCfg.runtimeVariables = newRuntimeVariables()
+		if len(cfg.iterationData) > 0 {
+			iterationCfg.iterationVariables = cfg.iterationData[i%len(cfg.iterationData)]
+		}
+		if cfg.printer != nil {
+			if err := cfg.printer.PrintTitle(fmt.Sprintf("Iteration %d/%d", i+1, count)); err != nil {
+				log.Warn().
+					Err(err).
+					Msg("could not print title")
+			}
+		}
+		results = append(results, runOnce(ctx, iterationCfg)...)
+	}
+	return results
+}
+
+func runOnce(ctx context.Context, cfg Config) []*Result {
+	if dirExists(cfg.bruFilePath) {
+		return RunCollection(ctx, cfg)
+	}
+	return []*Result{Run(ctx, cfg)}
+}
+
+// loadIterationData reads the rows of a CSV or JSON file based on its extension
+func loadIterationData(filePath string) ([]map[string]string, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read iteration data: %w", err)
+	}
+
+	var rows []map[string]string
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv":
+		rows, err = parseCSVIterationData(data)
+	case ".json":
+		rows, err = parseJSONIterationData(data)
+	default:
+		return nil, fmt.Errorf("%w: unsupported file type '%s', expected .csv or .json", ErrInvalidIterationData, filePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s': %w", ErrInvalidIterationData, filePath, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: '%s' has no rows", ErrInvalidIterationData, filePath)
+	}
+
+	log.Debug().
+		Str("filePath", filePath).
+		Int("rows", len(rows)).
+		Msg("loaded iteration data")
+	return rows, nil
+}
+
+func parseCSVIterationData(data []byte) ([]map[string]string, error) {
+	// Spreadsheets often export CSV files with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not parse CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[strings.TrimSpace(column)] = record[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseJSONIterationData(data []byte) ([]map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var objects []map[string]any
+	if err := decoder.Decode(&objects); err != nil {
+		return nil, fmt.Errorf("could not parse JSON, expected an array of objects: %w", err)
+	}
+
+	rows := make([]map[string]string, 0, len(objects))
+	for _, object := range objects {
+		row := make(map[string]string, len(object))
+		for key, value := range object {
+			switch v := value.(type) {
+			case string:
+				row[key] = v
+			case nil:
+				row[key] = ""
+			default:
+				// Numbers, booleans, arrays and objects are used as JSON
+				encoded, err := json.Marshal(v)
+				if err != nil {
+					return nil, fmt.Errorf("could not encode value of '%s': %w", key, err)
+				}
+				row[key] = string(encoded)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,87 @@
+package brurunner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func TestLoadIterationData(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	testCases := []struct {
+		name     string
+		fileName string
+		content  string
+		expected []map[string]string
+	}{
+		{
+			name:     "csv",
+			fileName: "users.csv",
+			content:  "\xef\xbb\xbfid, name\n1,\"Doe, John\"\n2,Jane\n",
+			expected: []map[string]string{{"id": "1", "name": "Doe, John"}, {"id": "2", "name": "Jane"}},
+		},
+		{
+			name:     "json",
+			fileName: "users.json",
+			content:  `[{"id": 1, "name": "John", "admin": true, "tags": ["a"], "email": null}]`,
+			expected: []map[string]string{{"id": "1", "name": "John", "admin": "true", "tags": `["a"]`, "email": ""}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			filePath := filepath.Join(dir, testCase.fileName)
+			require.NoError(t, os.WriteFile(filePath, []byte(testCase.content), 0o600))
+			rows, err := loadIterationData(filePath)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, rows)
+		})
+	}
+
+	for fileName, content := range map[string]string{"empty.csv": "id,name\n", "empty.json": "[]", "users.txt": "id\n1\n"} {
+		filePath := filepath.Join(dir, fileName)
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+		_, err := loadIterationData(filePath)
+		require.ErrorIs(t, err, ErrInvalidIterationData, fileName)
+	}
+}
+
+func TestRunIterations(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	bruFilePath := filepath.Join(dir, "user.bru")
+	// The variable set by an iteration does not override the data row of the next one
+	writeRequest(t, bruFilePath, 1, server.URL+"/users/{{id}}", "\nvars:post-response {\n  id: res.body\n}\n")
+	dataFilePath := filepath.Join(dir, "users.csv")
+	writeFile(t, dataFilePath, "id\n1\n2\n")
+	outputFilePath := filepath.Join(dir, "out.txt")
+
+	cfg, err := NewConfig(bruFilePath, true, outputFilePath, "", false, nil,
+		WithIterations(IterationOptions{DataFilePath: dataFilePath, Iterations: 3}))
+	require.NoError(t, err)
+	results := RunIterations(t.Context(), *cfg)
+
+	require.Len(t, results, 3)
+	for i, expectedPath := range []string{"/users/1", "/users/2", "/users/1"} {
+		require.NoError(t, results[i].Err)
+		require.Equal(t, i+1, results[i].Iteration)
+		require.Equal(t, server.URL+expectedPath, results[i].Request.URL)
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("out-%d.txt", i+1)))
+		require.NoError(t, err)
+		require.Equal(t, expectedPath, string(data))
+	}
+}
@@ -0,0 +1,159 @@
+package brurunner
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+const _viaProxyHeader = "X-Via-Proxy"
+
+// newProxyServer returns a stand-in for a forward proxy supporting plain HTTP requests and CONNECT tunnels
+func newProxyServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Authorization") == "" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		if r.Method == http.MethodConnect {
+			tunnel(t, w, r)
+			return
+		}
+
+		resp, err := http.DefaultTransport.RoundTrip(&http.Request{Method: r.Method, URL: r.URL, Header: r.Header, Body: r.Body})
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.Header().Set(_viaProxyHeader, "true")
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func tunnel(t *testing.T, w http.ResponseWriter, r *http.Request) {
+	t.Helper()
+	upstream, err := net.Dial("tcp", r.Host)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	conn, _, err := http.NewResponseController(w).Hijack()
+	require.NoError(t, err)
+	go func() {
+		defer upstream.Close()
+		_, _ = io.Copy(upstream, conn)
+	}()
+	go func() {
+		defer conn.Close()
+		_, _ = io.Copy(conn, upstream)
+	}()
+}
+
+func TestRun_Proxy(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(target.Close)
+	proxy := newProxyServer(t)
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	proxyURL.User = url.UserPassword("user", "pass")
+
+	dir := t.TempDir()
+	collectionDir := filepath.Join(dir, "collection")
+	bypassDir := filepath.Join(dir, "bypass")
+	require.NoError(t, os.Mkdir(collectionDir, 0o750))
+	require.NoError(t, os.Mkdir(bypassDir, 0o750))
+	brunoJSON := `{"proxy": {"enabled": true, "protocol": "http", "hostname": "127.0.0.1", "port": ` + proxyURL.Port() +
+		`, "auth": {"enabled": true, "username": "user", "password": "pass"}, "bypassProxy": "%s"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(collectionDir, "bruno.json"), []byte(fmt.Sprintf(brunoJSON, "example.com")), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(bypassDir, "bruno.json"), []byte(fmt.Sprintf(brunoJSON, "*")), 0o600))
+
+	tests := []struct {
+		name         string
+		bruFilePath  string
+		proxyOptions ProxyOptions
+		viaProxy     bool
+	}{
+		{"none", writeBruFile(t, dir, target.URL), ProxyOptions{}, false},
+		{"flag", writeBruFile(t, dir, target.URL), ProxyOptions{ProxyURL: proxyURL.String()}, true},
+		{"flag with no-proxy", writeBruFile(t, dir, target.URL), ProxyOptions{ProxyURL: proxyURL.String(), NoProxy: "127.0.0.0/8"}, false},
+		{"bruno.json", writeBruFile(t, collectionDir, target.URL), ProxyOptions{}, true},
+		{"bruno.json with bypass", writeBruFile(t, bypassDir, target.URL), ProxyOptions{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := NewConfig(tt.bruFilePath, false, "", "", false, nil, WithProxy(tt.proxyOptions), WithFailOnErrorStatus(true))
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.viaProxy, result.Response.Headers.Get(_viaProxyHeader) == "true")
+		})
+	}
+}
+
+func TestRun_ProxyConnect(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	target := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(target.Close)
+	proxy := newProxyServer(t)
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	bruFilePath := writeBruFile(t, t.TempDir(), target.URL)
+	cfg, err := NewConfig(bruFilePath, false, "", "", false, nil,
+		WithProxy(ProxyOptions{ProxyURL: proxyURL.String()}), WithTLS(TLSOptions{Insecure: true}))
+	require.NoError(t, err)
+	// The proxy rejects the CONNECT request without credentials
+	require.ErrorIs(t, Run(t.Context(), *cfg).Err, ErrTransport)
+
+	proxyURL.User = url.UserPassword("user", "pass")
+	cfg, err = NewConfig(bruFilePath, false, "", "", false, nil,
+		WithProxy(ProxyOptions{ProxyURL: proxyURL.String()}), WithTLS(TLSOptions{Insecure: true}), WithFailOnErrorStatus(true))
+	require.NoError(t, err)
+	require.NoError(t, Run(t.Context(), *cfg).Err)
+}
+
+func TestProxySelector_isBypassed(t *testing.T) {
+	t.Parallel()
+	selector := _ProxySelector{noProxy: splitNoProxy("example.com, .internal, *.corp.net, 10.0.0.0/8, localhost:8080")}
+	tests := map[string]bool{
+		"http://example.com/":       true,
+		"http://api.example.com/":   true,
+		"http://notexample.com/":    false,
+		"http://svc.internal/":      true,
+		"http://a.corp.net/":        true,
+		"http://10.1.2.3/":          true,
+		"http://11.1.2.3/":          false,
+		"http://localhost:8080/":    true,
+		"http://localhost:9090/":    false,
+		"https://api.example.org/x": false,
+	}
+	for rawURL, bypassed := range tests {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		require.Equal(t, bypassed, selector.isBypassed(u), rawURL)
+	}
+}
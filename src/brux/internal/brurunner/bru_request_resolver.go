@@ -0,0 +1,41 @@
+package brurunner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrNotARequest = errors.New("not a request")
+
+// ResolvedRequest is the request of a Bru file with the variables replaced
+type ResolvedRequest struct {
+	Method  string
+	URL     string
+	Headers http.Header
+	// nil if there is no body
+	Body []byte
+}
+
+// Resolve returns the request of the Bru file of cfg with the variables replaced, the same way Run does,
+// without sending it
+func Resolve(cfg Config) (*ResolvedRequest, error) {
+	bruFile, err := cfg.getBruFile()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not get bru file: %w", ErrParse, err)
+	}
+	if !bruFile.IsRequest() {
+		return nil, fmt.Errorf("%w: '%s'", ErrNotARequest, cfg.bruFilePath)
+	}
+
+	prepared, err := prepareRequest(bruFile)
+	if err != nil {
+		return nil, err
+	}
+	return &ResolvedRequest{
+		Method:  prepared.method,
+		URL:     prepared.url,
+		Headers: prepared.headers,
+		Body:    prepared.body,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package brurunner
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+func TestResolve_RedactedSecrets(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"name": "test"}`)
+	writeFile(t, filepath.Join(dir, "environments", "dev.bru"),
+		"vars {\n  host: https://example.com\n  password: hunter2\n}\n\nvars:secret [\n  password\n]\n")
+	writeFile(t, filepath.Join(dir, ".env"), "API_KEY=s3cr3t\n")
+	bruFilePath := filepath.Join(dir, "login.bru")
+	writeRequest(t, bruFilePath, 1, "{{host}}/login?key={{process.env.API_KEY}}",
+		"\nheaders {\n  X-Password: {{password}}\n}\n")
+
+	for _, redactSecrets := range []bool{true, false} {
+		cfg, err := NewConfig(bruFilePath, false, "", "dev", false, []string{"BRUX_TEST_*"}, WithRedactedSecrets(redactSecrets))
+		require.NoError(t, err)
+		request, err := Resolve(*cfg)
+		require.NoError(t, err)
+		if redactSecrets {
+			require.Equal(t, "https://example.com/login?key=<redacted:API_KEY>", request.URL)
+			require.Equal(t, "<redacted:password>", request.Headers.Get("X-Password"))
+		} else {
+			require.Equal(t, "https://example.com/login?key=s3cr3t", request.URL)
+			require.Equal(t, "hunter2", request.Headers.Get("X-Password"))
+		}
+	}
+}
+
+func TestResolve_RedactedSecrets_Undefined(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"name": "test"}`)
+	writeFile(t, filepath.Join(dir, "environments", "dev.bru"),
+		"vars {\n  host: https://example.com\n}\n\nvars:secret [\n  token\n]\n")
+	bruFilePath := filepath.Join(dir, "me.bru")
+	writeRequest(t, bruFilePath, 1, "{{host}}/me", "\nheaders {\n  Authorization: Bearer {{token}}\n}\n")
+
+	// The undefined secret fails the same way with and without redaction, the non-secret host is not redacted
+	for _, redactSecrets := range []bool{true, false} {
+		cfg, err := NewConfig(bruFilePath, false, "", "dev", false, []string{"BRUX_TEST_*"}, WithRedactedSecrets(redactSecrets))
+		require.NoError(t, err)
+		_, err = Resolve(*cfg)
+		require.ErrorIs(t, err, bruparser.ErrTemplateVariablesFound)
+	}
+
+	writeRequest(t, bruFilePath, 1, "{{host}}/me", "")
+	cfg, err := NewConfig(bruFilePath, false, "", "dev", false, []string{"BRUX_TEST_*"}, WithRedactedSecrets(true))
+	require.NoError(t, err)
+	request, err := Resolve(*cfg)
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/me", request.URL)
+}
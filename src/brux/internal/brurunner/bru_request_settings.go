@@ -0,0 +1,152 @@
+package brurunner
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+const (
+	_defaultTimeout      = 300 * time.Second
+	_defaultMaxRedirects = 10
+)
+
+var (
+	ErrInvalidSetting   = errors.New("invalid setting")
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+// _RequestSettings are the per-request settings from the "settings" section of a Bru file.
+// Unset values are nil and fall back to the global config.
+type _RequestSettings struct {
+	timeout         *time.Duration
+	followRedirects *bool
+	maxRedirects    *int
+
+	maxRetries         *int
+	retryOn            []string
+	retryUnsafeMethods *bool
+
+	// JSON Schema file relative to the Bru file, empty to disable the schema of WithSchema
+	schema *string
+}
+
+func getRequestSettings(bruFile *bruparser.BruFile) (*_RequestSettings, error) {
+	settings := &_RequestSettings{}
+	for key, value := range bruFile.Settings() {
+		switch key {
+		case "timeout":
+			timeout, err := parseTimeout(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: timeout: %w", ErrInvalidSetting, err)
+			}
+			settings.timeout = &timeout
+		case "followRedirects":
+			followRedirects, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: followRedirects: %w", ErrInvalidSetting, err)
+			}
+			settings.followRedirects = &followRedirects
+		case "maxRedirects":
+			maxRedirects, err := strconv.Atoi(value)
+			if err != nil || maxRedirects < 0 {
+				return nil, fmt.Errorf("%w: maxRedirects: '%s' is not a non-negative number", ErrInvalidSetting, value)
+			}
+			settings.maxRedirects = &maxRedirects
+		case "maxRetries":
+			maxRetries, err := strconv.Atoi(value)
+			if err != nil || maxRetries < 0 {
+				return nil, fmt.Errorf("%w: maxRetries: '%s' is not a non-negative number", ErrInvalidSetting, value)
+			}
+			settings.maxRetries = &maxRetries
+		case "retryOn":
+			settings.retryOn = strings.Split(value, ",")
+			if _, err := newRetryPolicy(RetryOptions{RetryOn: settings.retryOn}); err != nil {
+				return nil, fmt.Errorf("%w: retryOn: %w", ErrInvalidSetting, err)
+			}
+		case "retryUnsafeMethods":
+			retryUnsafeMethods, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: retryUnsafeMethods: %w", ErrInvalidSetting, err)
+			}
+			settings.retryUnsafeMethods = &retryUnsafeMethods
+		case "schema":
+			settings.schema = &value
+		default:
+			// Ignore settings like "encodeUrl" that only matter to Bruno
+			continue
+		}
+	}
+	return settings, nil
+}
+
+// parseTimeout parses a timeout like "5s" or a number of milliseconds like "5000", as used by Bruno
+func parseTimeout(value string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse '%s': %w", value, err)
+	}
+	return timeout, nil
+}
+
+// getTimeout returns the per-request timeout if set, and the global timeout otherwise
+func (s _RequestSettings) getTimeout(cfg Config) time.Duration {
+	if s.timeout != nil {
+		return *s.timeout
+	}
+	if cfg.timeout > 0 {
+		return cfg.timeout
+	}
+	return _defaultTimeout
+}
+
+// getRetryPolicy returns the global retry policy with the per-request overrides applied
+func (s _RequestSettings) getRetryPolicy(cfg Config) (*_RetryPolicy, error) {
+	options := cfg.retryOptions
+	if s.maxRetries != nil {
+		options.MaxRetries = *s.maxRetries
+	}
+	if s.retryOn != nil {
+		options.RetryOn = s.retryOn
+	}
+	if s.retryUnsafeMethods != nil {
+		options.RetryUnsafeMethods = *s.retryUnsafeMethods
+	}
+	return newRetryPolicy(options)
+}
+
+// checkRedirect implements http.Client.CheckRedirect as per the per-request and the global redirect settings
+func (s _RequestSettings) checkRedirect(cfg Config) func(req *http.Request, via []*http.Request) error {
+	followRedirects := !cfg.noFollowRedirects
+	if s.followRedirects != nil {
+		followRedirects = *s.followRedirects
+	}
+	maxRedirects := _defaultMaxRedirects
+	if s.maxRedirects != nil {
+		maxRedirects = *s.maxRedirects
+	}
+
+	return func(req *http.Request, via []*http.Request) error {
+		if !followRedirects {
+			return http.ErrUseLastResponse
+		}
+		if len(via) > maxRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, maxRedirects)
+		}
+		log.Debug().
+			Str("url", req.URL.String()).
+			Int("redirects", len(via)).
+			Msg("following redirect")
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package brurunner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func TestParseTimeout(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]time.Duration{
+		"5000":  5 * time.Second,
+		"0":     0,
+		"1.5s":  1500 * time.Millisecond,
+		"250ms": 250 * time.Millisecond,
+		"2m":    2 * time.Minute,
+	}
+	for value, expected := range testCases {
+		timeout, err := parseTimeout(value)
+		require.NoError(t, err, value)
+		require.Equal(t, expected, timeout, value)
+	}
+	for _, value := range []string{"", "5 s", "abc", "1.5"} {
+		_, err := parseTimeout(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestRequestSettings_GetTimeout(t *testing.T) {
+	t.Parallel()
+	setting := 2 * time.Second
+	// The setting of the Bru file takes precedence over the flag, which takes precedence over the default
+	require.Equal(t, setting, _RequestSettings{timeout: &setting}.getTimeout(Config{timeout: time.Minute}))
+	require.Equal(t, time.Minute, _RequestSettings{}.getTimeout(Config{timeout: time.Minute}))
+	require.Equal(t, _defaultTimeout, _RequestSettings{}.getTimeout(Config{}))
+}
+
+func TestRun_Timeout(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	testCases := []struct {
+		name     string
+		settings string
+		timeout  time.Duration
+		timedOut bool
+	}{
+		{name: "setting in milliseconds", settings: "\nsettings {\n  timeout: 50\n}\n", timeout: time.Minute, timedOut: true},
+		{name: "setting as a duration", settings: "\nsettings {\n  timeout: 50ms\n}\n", timeout: time.Minute, timedOut: true},
+		{name: "flag", timeout: 50 * time.Millisecond, timedOut: true},
+		{name: "setting over flag", settings: "\nsettings {\n  timeout: 5s\n}\n", timeout: 50 * time.Millisecond},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			bruFilePath := filepath.Join(t.TempDir(), "get.bru")
+			writeRequest(t, bruFilePath, 1, server.URL, tt.settings)
+			cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithTimeout(tt.timeout))
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+			if tt.timedOut {
+				require.ErrorIs(t, result.Err, ErrTransport)
+				require.Less(t, result.Timings.Total, time.Second)
+			} else {
+				require.NoError(t, result.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package brurunner
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// _RequestTrace records the timestamps of the phases of a request via httptrace
+type _RequestTrace struct {
+	mu sync.Mutex
+
+	dnsStart          time.Time
+	dnsDone           time.Time
+	connectStart      time.Time
+	connectDone       time.Time
+	tlsStart          time.Time
+	tlsDone           time.Time
+	wroteRequest      time.Time
+	firstResponseByte time.Time
+}
+
+func (t *_RequestTrace) withContext(ctx context.Context) context.Context {
+	record := func(field *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*field = time.Now()
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&t.dnsDone) },
+		ConnectStart:         func(string, string) { record(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { record(&t.connectDone) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&t.wroteRequest) },
+		GotFirstResponseByte: func() { record(&t.firstResponseByte) },
+	})
+}
+
+// timings computes the duration of each phase, phases that did not happen (e.g. due to connection reuse) are zero
+func (t *_RequestTrace) timings(start time.Time, end time.Time) Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Timings{
+		Start:           start,
+		Total:           end.Sub(start),
+		DNSLookup:       since(t.dnsStart, t.dnsDone),
+		TCPConnection:   since(t.connectStart, t.connectDone),
+		TLSHandshake:    since(t.tlsStart, t.tlsDone),
+		TimeToFirstByte: since(t.wroteRequest, t.firstResponseByte),
+		ContentTransfer: since(t.firstResponseByte, end),
+	}
+}
+
+func since(start time.Time, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
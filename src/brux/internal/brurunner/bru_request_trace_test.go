@@ -0,0 +1,42 @@
+package brurunner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func TestRun_Timings(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+
+	bruFilePath := filepath.Join(t.TempDir(), "get.bru")
+	// The host name, rather than the IP address of the server, is resolved via DNS
+	writeRequest(t, bruFilePath, 1, strings.Replace(server.URL, "127.0.0.1", "localhost", 1), "")
+	cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithTLS(TLSOptions{Insecure: true}))
+	require.NoError(t, err)
+	result := Run(t.Context(), *cfg)
+	require.NoError(t, result.Err)
+
+	timings := result.Timings
+	require.False(t, timings.Start.IsZero())
+	require.Positive(t, timings.DNSLookup)
+	require.Positive(t, timings.TCPConnection)
+	require.Positive(t, timings.TLSHandshake)
+	require.GreaterOrEqual(t, timings.TimeToFirstByte, 20*time.Millisecond)
+	require.GreaterOrEqual(t, timings.ContentTransfer, time.Duration(0))
+	require.GreaterOrEqual(t, timings.Total,
+		timings.DNSLookup+timings.TCPConnection+timings.TLSHandshake+timings.TimeToFirstByte)
+}
@@ -0,0 +1,175 @@
+package brurunner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// RetryOnTimeout retries requests that timed out
+	RetryOnTimeout = "timeout"
+	// RetryOnConnection retries requests whose connection was refused, reset or closed early
+	RetryOnConnection = "connection"
+	// RetryOnDNS retries requests whose host could not be resolved
+	RetryOnDNS = "dns"
+
+	_defaultInitialBackoff = 500 * time.Millisecond
+	_defaultMaxBackoff     = 30 * time.Second
+	// Upper bound of the delay requested by the server via Retry-After
+	_maxRetryAfter = 2 * time.Minute
+)
+
+// DefaultRetryOn are the status codes and network errors retried by default
+var DefaultRetryOn = []string{"408", "429", "502", "503", "504", RetryOnTimeout, RetryOnConnection}
+
+var ErrInvalidRetryOn = errors.New("invalid retry condition")
+
+// RetryOptions configures retries of all requests, requests can override them via the "settings" section.
+// Only safe methods, like GET, are retried unless RetryUnsafeMethods is set.
+type RetryOptions struct {
+	// Zero disables retries
+	MaxRetries int
+	// Status codes like "503" and network errors (RetryOnTimeout, RetryOnConnection, RetryOnDNS) to retry,
+	// defaults to DefaultRetryOn
+	RetryOn []string
+	// Retry methods like POST, which might not be idempotent
+	RetryUnsafeMethods bool
+	// Delay before the first retry, doubled on each retry, defaults to 500ms
+	InitialBackoff time.Duration
+	// Upper bound of the delay between retries, defaults to 30s
+	MaxBackoff time.Duration
+}
+
+// _RetryPolicy decides whether and when to retry a request
+type _RetryPolicy struct {
+	maxRetries         int
+	statusCodes        map[int]bool
+	networkErrors      map[string]bool
+	retryUnsafeMethods bool
+	initialBackoff     time.Duration
+	maxBackoff         time.Duration
+}
+
+func newRetryPolicy(options RetryOptions) (*_RetryPolicy, error) {
+	policy := &_RetryPolicy{
+		maxRetries:         options.MaxRetries,
+		statusCodes:        make(map[int]bool),
+		networkErrors:      make(map[string]bool),
+		retryUnsafeMethods: options.RetryUnsafeMethods,
+		initialBackoff:     options.InitialBackoff,
+		maxBackoff:         options.MaxBackoff,
+	}
+	if policy.initialBackoff <= 0 {
+		policy.initialBackoff = _defaultInitialBackoff
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = _defaultMaxBackoff
+	}
+
+	retryOn := options.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = DefaultRetryOn
+	}
+	for _, condition := range retryOn {
+		condition = strings.TrimSpace(condition)
+		switch condition {
+		case RetryOnTimeout, RetryOnConnection, RetryOnDNS:
+			policy.networkErrors[condition] = true
+		default:
+			statusCode, err := strconv.Atoi(condition)
+			if err != nil || statusCode < 100 || statusCode > 599 {
+				return nil, fmt.Errorf("%w: '%s'", ErrInvalidRetryOn, condition)
+			}
+			policy.statusCodes[statusCode] = true
+		}
+	}
+	return policy, nil
+}
+
+// shouldRetry returns whether to retry after the given attempt, and the delay before the retry
+func (p _RetryPolicy) shouldRetry(ctx context.Context, method string, attempt int, resp *ResponseInfo, err error) (bool, time.Duration) {
+	if attempt > p.maxRetries || ctx.Err() != nil {
+		return false, 0
+	}
+	if !p.retryUnsafeMethods && !isSafeMethod(method) {
+		return false, 0
+	}
+
+	switch {
+	case err != nil && !p.networkErrors[classifyNetworkError(err)]:
+		return false, 0
+	case err == nil && (resp == nil || !p.statusCodes[resp.StatusCode]):
+		return false, 0
+	}
+
+	delay := p.backoff(attempt)
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Headers.Get("Retry-After")); ok {
+			delay = max(delay, min(retryAfter, _maxRetryAfter))
+		}
+	}
+	return true, delay
+}
+
+// backoff returns the exponential backoff with jitter before the retry following the given attempt
+func (p _RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.initialBackoff
+	for range attempt - 1 {
+		backoff *= 2
+		if backoff >= p.maxBackoff {
+			backoff = p.maxBackoff
+			break
+		}
+	}
+	// "Equal jitter", half of the backoff is random so that concurrent clients don't retry in lockstep
+	//nolint:gosec // Jitter does not need a cryptographically secure random number
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// parseRetryAfter parses the Retry-After header, either a number of seconds or an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// classifyNetworkError maps err to one of RetryOnTimeout, RetryOnConnection or RetryOnDNS, empty if it is neither
+func classifyNetworkError(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var opErr *net.OpError
+	switch {
+	case errors.As(err, &dnsErr):
+		return RetryOnDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return RetryOnTimeout
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.As(err, &opErr):
+		return RetryOnConnection
+	default:
+		return ""
+	}
+}
+
+// isSafeMethod returns true for methods that don't modify the server state
+// Ref: https://developer.mozilla.org/en-US/docs/Glossary/Safe/HTTP
+func isSafeMethod(method string) bool {
+	return slices.Contains([]string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace}, method)
+}
@@ -0,0 +1,97 @@
+package brurunner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+// newFlakyServer fails the first "failures" requests with 503
+func newFlakyServer(t *testing.T, failures int32) *httptest.Server {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun_Retries(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	retryOptions := RetryOptions{MaxRetries: 3, InitialBackoff: time.Millisecond}
+
+	tests := []struct {
+		name       string
+		method     string
+		settings   string
+		opts       RetryOptions
+		attempts   int
+		statusCode int
+	}{
+		{"no retries", "get", "", RetryOptions{}, 1, http.StatusServiceUnavailable},
+		{"retries", "get", "", retryOptions, 3, http.StatusOK},
+		{"too few retries", "get", "", RetryOptions{MaxRetries: 1, InitialBackoff: time.Millisecond}, 2, http.StatusServiceUnavailable},
+		{"unsafe method", "post", "", retryOptions, 1, http.StatusServiceUnavailable},
+		{"unsafe method opted in", "post", "retryUnsafeMethods: true", retryOptions, 3, http.StatusOK},
+		{"status not retried", "get", "retryOn: 502,timeout", retryOptions, 1, http.StatusServiceUnavailable},
+		{"per-request retries", "get", "maxRetries: 5", RetryOptions{InitialBackoff: time.Millisecond}, 3, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := newFlakyServer(t, 2)
+			bruFilePath := filepath.Join(t.TempDir(), "flaky.bru")
+			require.NoError(t, os.WriteFile(bruFilePath,
+				[]byte(tt.method+" {\n  url: "+server.URL+"\n}\n\nsettings {\n  "+tt.settings+"\n}\n"), 0o600))
+			cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithRetries(tt.opts))
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+			require.NoError(t, result.Err)
+			require.Len(t, result.Attempts, tt.attempts)
+			require.Equal(t, tt.statusCode, result.Response.StatusCode)
+		})
+	}
+}
+
+func TestRun_RetriesConnectionErrors(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	bruFilePath := writeBruFile(t, t.TempDir(), server.URL)
+	cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithRetries(RetryOptions{MaxRetries: 2, InitialBackoff: time.Millisecond}))
+	require.NoError(t, err)
+	result := Run(t.Context(), *cfg)
+	require.ErrorIs(t, result.Err, ErrTransport)
+	require.Len(t, result.Attempts, 3)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+	delay, ok := parseRetryAfter("120")
+	require.True(t, ok)
+	require.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	require.True(t, ok)
+	require.Greater(t, delay, 59*time.Minute)
+
+	_, ok = parseRetryAfter("soon")
+	require.False(t, ok)
+}
@@ -0,0 +1,218 @@
+package brurunner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// _Report is the JSON representation of the results of a run
+type _Report struct {
+	Results []_ReportResult `json:"results"`
+}
+
+type _ReportResult struct {
+	FilePath   string             `json:"filePath"`
+	Iteration  int                `json:"iteration,omitempty"`
+	Passed     bool               `json:"passed"`
+	Error      string             `json:"error,omitempty"`
+	Request    _ReportRequest     `json:"request"`
+	Response   *_ReportResponse   `json:"response,omitempty"`
+	Timings    _ReportTimings     `json:"timings"`
+	Attempts   []_ReportAttempt   `json:"attempts,omitempty"`
+	Assertions []_ReportAssertion `json:"assertions,omitempty"`
+}
+
+type _ReportAttempt struct {
+	Number       int            `json:"number"`
+	StatusCode   int            `json:"statusCode,omitempty"`
+	Error        string         `json:"error,omitempty"`
+	Timings      _ReportTimings `json:"timings"`
+	RetryDelayMs float64        `json:"retryDelayMs,omitempty"`
+}
+
+type _ReportRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+}
+
+type _ReportResponse struct {
+	Status     string      `json:"status"`
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Size       int         `json:"size"`
+	// Omitted for binary bodies
+	Body string `json:"body,omitempty"`
+}
+
+// _ReportTimings are in milliseconds
+type _ReportTimings struct {
+	Start           time.Time `json:"start"`
+	Total           float64   `json:"totalMs"`
+	DNSLookup       float64   `json:"dnsLookupMs"`
+	TCPConnection   float64   `json:"tcpConnectionMs"`
+	TLSHandshake    float64   `json:"tlsHandshakeMs"`
+	TimeToFirstByte float64   `json:"timeToFirstByteMs"`
+	ContentTransfer float64   `json:"contentTransferMs"`
+}
+
+type _ReportAssertion struct {
+	Assertion string `json:"assertion"`
+	Passed    bool   `json:"passed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// WriteReport writes the results as a JSON report to w, with the values of the secret request headers like
+// Authorization redacted
+func WriteReport(w io.Writer, results []*Result) error {
+	report := _Report{Results: make([]_ReportResult, 0, len(results))}
+	for _, result := range results {
+		report.Results = append(report.Results, newReportResult(result))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("could not write report: %w", err)
+	}
+	return nil
+}
+
+// ReadReport reads the results from a JSON report written by WriteReport.
+// The attempts and assertions are not read, and errors are read as plain errors without their kind.
+func ReadReport(r io.Reader) ([]*Result, error) {
+	var report _Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("could not read report: %w", err)
+	}
+
+	results := make([]*Result, 0, len(report.Results))
+	for _, reportResult := range report.Results {
+		result := &Result{
+			FilePath:  reportResult.FilePath,
+			Iteration: reportResult.Iteration,
+			Request: RequestInfo{
+				Method:  reportResult.Request.Method,
+				URL:     reportResult.Request.URL,
+				Headers: reportResult.Request.Headers,
+			},
+			Timings: reportResult.Timings.timings(),
+		}
+		if reportResult.Error != "" {
+			result.Err = errors.New(reportResult.Error)
+		}
+		if reportResult.Response != nil {
+			result.Response = &ResponseInfo{
+				Status:     reportResult.Response.Status,
+				StatusCode: reportResult.Response.StatusCode,
+				Headers:    reportResult.Response.Headers,
+				Body:       []byte(reportResult.Response.Body),
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func newReportResult(result *Result) _ReportResult {
+	reportResult := _ReportResult{
+		FilePath:  result.FilePath,
+		Iteration: result.Iteration,
+		Passed:    result.Passed(),
+		Request: _ReportRequest{
+			Method:  result.Request.Method,
+			URL:     result.Request.URL,
+			Headers: redactHeaders(result.Request.Headers),
+		},
+		Timings: newReportTimings(result.Timings),
+	}
+	if result.Err != nil {
+		reportResult.Error = result.Err.Error()
+	}
+	if result.Response != nil {
+		reportResult.Response = &_ReportResponse{
+			Status:     result.Response.Status,
+			StatusCode: result.Response.StatusCode,
+			Headers:    result.Response.Headers,
+			Size:       len(result.Response.Body),
+		}
+		if utf8.Valid(result.Response.Body) {
+			reportResult.Response.Body = string(result.Response.Body)
+		}
+	}
+	for _, attempt := range result.Attempts {
+		reportAttempt := _ReportAttempt{
+			Number:       attempt.Number,
+			StatusCode:   attempt.StatusCode,
+			Timings:      newReportTimings(attempt.Timings),
+			RetryDelayMs: toMilliseconds(attempt.RetryDelay),
+		}
+		if attempt.Err != nil {
+			reportAttempt.Error = attempt.Err.Error()
+		}
+		reportResult.Attempts = append(reportResult.Attempts, reportAttempt)
+	}
+	for _, assertion := range result.Assertions {
+		reportAssertion := _ReportAssertion{
+			Assertion: assertion.String(),
+			Passed:    assertion.Passed,
+		}
+		if assertion.Err != nil {
+			reportAssertion.Error = assertion.Err.Error()
+		}
+		reportResult.Assertions = append(reportResult.Assertions, reportAssertion)
+	}
+	return reportResult
+}
+
+// redactHeaders returns a copy of the headers with the values of the secret headers replaced by "<redacted>"
+func redactHeaders(headers http.Header) http.Header {
+	if headers == nil {
+		return nil
+	}
+	redacted := headers.Clone()
+	for name, values := range redacted {
+		if IsSecretHeader(name) {
+			redacted[name] = slices.Repeat([]string{"<redacted>"}, len(values))
+		}
+	}
+	return redacted
+}
+
+func newReportTimings(timings Timings) _ReportTimings {
+	return _ReportTimings{
+		Start:           timings.Start,
+		Total:           toMilliseconds(timings.Total),
+		DNSLookup:       toMilliseconds(timings.DNSLookup),
+		TCPConnection:   toMilliseconds(timings.TCPConnection),
+		TLSHandshake:    toMilliseconds(timings.TLSHandshake),
+		TimeToFirstByte: toMilliseconds(timings.TimeToFirstByte),
+		ContentTransfer: toMilliseconds(timings.ContentTransfer),
+	}
+}
+
+func (t _ReportTimings) timings() Timings {
+	return Timings{
+		Start:           t.Start,
+		Total:           fromMilliseconds(t.Total),
+		DNSLookup:       fromMilliseconds(t.DNSLookup),
+		TCPConnection:   fromMilliseconds(t.TCPConnection),
+		TLSHandshake:    fromMilliseconds(t.TLSHandshake),
+		TimeToFirstByte: fromMilliseconds(t.TimeToFirstByte),
+		ContentTransfer: fromMilliseconds(t.ContentTransfer),
+	}
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func fromMilliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,79 @@
+package brurunner
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
+)
+
+func TestWriteReport_ReadReport(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	timings := Timings{
+		Start:           start,
+		Total:           120 * time.Millisecond,
+		DNSLookup:       1500 * time.Microsecond,
+		TCPConnection:   2 * time.Millisecond,
+		TLSHandshake:    10 * time.Millisecond,
+		TimeToFirstByte: 100 * time.Millisecond,
+		ContentTransfer: 6500 * time.Microsecond,
+	}
+	results := []*Result{
+		{
+			FilePath:  "users/get.bru",
+			Iteration: 2,
+			Request: RequestInfo{
+				Method:  http.MethodGet,
+				URL:     "https://example.com/users/1",
+				Headers: http.Header{"Accept": {"application/json"}, "Authorization": {"Bearer secret"}},
+			},
+			Response: &ResponseInfo{
+				Status:     "200 OK",
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{"Content-Type": {"application/json"}},
+				Body:       []byte(`{"id": 1}`),
+			},
+			Timings:    timings,
+			Attempts:   []Attempt{{Number: 1, StatusCode: http.StatusOK, Timings: timings}},
+			Assertions: []bruexpr.AssertionResult{{Expression: "res.status", Operator: "eq", Expected: "200", Passed: true}},
+		},
+		{
+			FilePath: "users/delete.bru",
+			Request:  RequestInfo{Method: http.MethodDelete, URL: "https://example.com/users/1"},
+			Timings:  Timings{Start: start},
+			Err:      fmt.Errorf("%w: connection refused", ErrTransport),
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteReport(&buf, results))
+	require.Contains(t, buf.String(), `"timeToFirstByteMs": 100`)
+	require.Contains(t, buf.String(), `"assertion": "res.status: eq 200"`)
+	require.NotContains(t, buf.String(), "secret")
+
+	read, err := ReadReport(&buf)
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+
+	// The attempts and assertions are not read back
+	expected := *results[0]
+	expected.Attempts = nil
+	expected.Assertions = nil
+	expected.Request.Headers = http.Header{"Accept": {"application/json"}, "Authorization": {"<redacted>"}}
+	require.Equal(t, expected, *read[0])
+
+	require.Equal(t, results[1].FilePath, read[1].FilePath)
+	require.Equal(t, results[1].Request, read[1].Request)
+	require.Nil(t, read[1].Response)
+	require.EqualError(t, read[1].Err, "transport error: connection refused")
+	require.NotErrorIs(t, read[1].Err, ErrTransport)
+
+	_, err = ReadReport(bytes.NewBufferString("{"))
+	require.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package brurunner
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
+	"github.com/ashishb/brux/src/brux/internal/bruprinter"
+)
+
+var (
+	// ErrParse is returned if the Bru file, its environment or its variables could not be parsed, or if its
+	// post-response variables could not be evaluated
+	ErrParse = errors.New("parse error")
+	// ErrTransport is returned if the request could not be sent or the response could not be read
+	ErrTransport = errors.New("transport error")
+	// ErrUnexpectedStatus is returned for non-2xx responses if enabled via WithFailOnErrorStatus
+	ErrUnexpectedStatus = errors.New("unexpected status")
+	// ErrAssertionFailed is returned if any of the assertions in the "assert" section failed
+	ErrAssertionFailed = errors.New("assertion failed")
+)
+
+// Headers whose values are redacted in reports and snippets, as they usually contain credentials
+var _secretHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key", "X-Auth-Token"}
+
+// IsSecretHeader returns true for the headers whose values usually contain credentials, e.g. Authorization
+func IsSecretHeader(name string) bool {
+	return slices.Contains(_secretHeaders, http.CanonicalHeaderKey(name))
+}
+
+// Result is the outcome of running a single Bru file
+type Result struct {
+	FilePath string
+	// Index of the iteration starting at 1, zero if not iterating
+	Iteration int
+	Request   RequestInfo
+	// nil if no response was received
+	Response *ResponseInfo
+	Timings  Timings
+	// Every attempt to send the request, more than one if the request was retried
+	Attempts   []Attempt
+	Assertions []bruexpr.AssertionResult
+	// nil if the run succeeded, wraps one of ErrParse, ErrTransport, ErrUnexpectedStatus or ErrAssertionFailed otherwise
+	Err error
+}
+
+// Attempt is a single attempt to send the request
+type Attempt struct {
+	// Starts at 1
+	Number int
+	// Zero if no response was received
+	StatusCode int
+	Timings    Timings
+	Err        error
+	// Delay before the next attempt, zero for the last attempt
+	RetryDelay time.Duration
+}
+
+// RequestInfo describes the request that was sent
+type RequestInfo struct {
+	Method  string
+	URL     string
+	Headers http.Header
+	// nil if there is no body
+	Body []byte
+}
+
+// ResponseInfo describes the response that was received
+type ResponseInfo struct {
+	Proto      string
+	Status     string
+	StatusCode int
+	Headers    http.Header
+	Body       []byte
+}
+
+// Timings records when the request started and how long each phase took.
+// Phases that did not happen, e.g. DNS lookup for a reused connection, are zero.
+type Timings struct {
+	Start           time.Time
+	Total           time.Duration
+	DNSLookup       time.Duration
+	TCPConnection   time.Duration
+	TLSHandshake    time.Duration
+	TimeToFirstByte time.Duration
+	ContentTransfer time.Duration
+}
+
+// Phases returns the non-zero phases in the order they happen
+func (t Timings) Phases() []bruprinter.Phase {
+	phases := []bruprinter.Phase{
+		{Name: "DNS", Duration: t.DNSLookup},
+		{Name: "Connect", Duration: t.TCPConnection},
+		{Name: "TLS", Duration: t.TLSHandshake},
+		{Name: "TTFB", Duration: t.TimeToFirstByte},
+		{Name: "Transfer", Duration: t.ContentTransfer},
+	}
+	return lo.Filter(phases, func(phase bruprinter.Phase, _ int) bool {
+		return phase.Duration > 0
+	})
+}
+
+// Passed returns true if the run succeeded
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// FailedAssertions returns the assertions that failed or could not be evaluated
+func (r Result) FailedAssertions() []bruexpr.AssertionResult {
+	failed := make([]bruexpr.AssertionResult, 0)
+	for _, assertion := range r.Assertions {
+		if !assertion.Passed {
+			failed = append(failed, assertion)
+		}
+	}
+	return failed
+}
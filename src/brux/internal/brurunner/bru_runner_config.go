@@ -7,20 +7,30 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/hashicorp/go-envparse"
 	"github.com/rs/zerolog/log"
 
+	"github.com/ashishb/brux/src/brux/internal/brucontract"
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
 	"github.com/ashishb/brux/src/brux/internal/bruparser"
+	"github.com/ashishb/brux/src/brux/internal/bruprinter"
 )
 
-const _BrunoEnvironmentsDirName = "environments"
+const (
+	_BrunoEnvironmentsDirName = "environments"
+	// Writes the output to stdout instead of a file
+	_stdoutFilePath = "-"
+)
 
 type Config struct {
 	bruFilePath     string
@@ -30,25 +40,296 @@ type Config struct {
 	outputFilePath string
 
 	prettyPrint bool
+
+	// Glob patterns (e.g. "API_*") of process environment variables usable as "{{process.env.X}}".
+	// All process environment variables are usable if empty.
+	processEnvAllowList []string
+
+	// Prints the response, nil to disable printing
+	printer *bruprinter.Printer
+
+	// Treat non-2xx responses as failures
+	failOnErrorStatus bool
+
+	// Timeout of each request unless overridden via the "settings" section, zero for the default
+	timeout time.Duration
+
+	tlsOptions   TLSOptions
+	proxyOptions ProxyOptions
+	// Don't follow redirects unless enabled via "followRedirects" in the "settings" section
+	noFollowRedirects bool
+	// Persists the cookies to this file between runs if set
+	cookieJarFilePath string
+	retryOptions      RetryOptions
+	// Maximum number of concurrent requests when running a collection
+	parallel         int
+	iterationOptions IterationOptions
+	snapshotOptions  SnapshotOptions
+	cassetteOptions  CassetteOptions
+	// Sends the requests instead of the network transport if set, e.g. to an http.Handler in-process
+	customTransport http.RoundTripper
+	// Override the variables of the environment and the ".env" file
+	variables map[string]string
+	// JSON Schema file that the response bodies are validated against, unless overridden via the "settings" section
+	schemaFilePath string
+	// OpenAPI spec that the requests and responses are checked against
+	openAPISpecFilePath string
+	// Printed and saved instead of the body if set, e.g. "$.items[*].id", ".items[0].id" or "res.headers.etag"
+	filter string
+
+	// Replace the values of secret variables with placeholders, e.g. when exporting requests
+	redactSecrets bool
+
+	// Rows of the iteration data file, loaded in NewConfig
+	iterationData []map[string]string
+	// Index of the current iteration starting at 1, zero if not iterating
+	iteration int
+	// Columns of the row of the current iteration
+	iterationVariables map[string]string
+
+	// Compiled in NewConfig
+	snapshotRules *_SnapshotRules
+	// Compiled schema of schemaFilePath, nil if there is none
+	schema *_ResponseSchema
+	// Loaded spec of openAPISpecFilePath, nil if there is none
+	openAPISpec *brucontract.Spec
+
+	// Shared by all the requests, created in NewConfig
+	transport        http.RoundTripper
+	cookieJar        *_CookieJar
+	runtimeVariables *_RuntimeVariables
+}
+
+// Option configures optional behavior of Config
+type Option func(*Config)
+
+// WithFailOnErrorStatus treats non-2xx responses as failures
+func WithFailOnErrorStatus(failOnErrorStatus bool) Option {
+	return func(cfg *Config) {
+		cfg.failOnErrorStatus = failOnErrorStatus
+	}
+}
+
+// WithTimeout sets the timeout of each request, requests can override it via "timeout" in the "settings" section
+func WithTimeout(timeout time.Duration) Option {
+	return func(cfg *Config) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithTLS configures TLS of all the requests
+func WithTLS(tlsOptions TLSOptions) Option {
+	return func(cfg *Config) {
+		cfg.tlsOptions = tlsOptions
+	}
+}
+
+// WithProxy configures the proxy of all the requests
+func WithProxy(proxyOptions ProxyOptions) Option {
+	return func(cfg *Config) {
+		cfg.proxyOptions = proxyOptions
+	}
+}
+
+// WithNoFollowRedirects disables following redirects, requests can enable it via "followRedirects" in the
+// "settings" section
+func WithNoFollowRedirects(noFollowRedirects bool) Option {
+	return func(cfg *Config) {
+		cfg.noFollowRedirects = noFollowRedirects
+	}
+}
+
+// WithCookieJarFile loads the cookies from the file, if it exists, and saves them back after every request
+func WithCookieJarFile(cookieJarFilePath string) Option {
+	return func(cfg *Config) {
+		cfg.cookieJarFilePath = cookieJarFilePath
+	}
+}
+
+// WithRetries retries failed requests, requests can override it via the "settings" section
+func WithRetries(retryOptions RetryOptions) Option {
+	return func(cfg *Config) {
+		cfg.retryOptions = retryOptions
+	}
+}
+
+// WithParallel runs up to parallel requests concurrently when running a collection
+func WithParallel(parallel int) Option {
+	return func(cfg *Config) {
+		cfg.parallel = parallel
+	}
+}
+
+// WithIterations runs the file or collection once per row of the data file, or the given number of times
+func WithIterations(iterationOptions IterationOptions) Option {
+	return func(cfg *Config) {
+		cfg.iterationOptions = iterationOptions
+	}
+}
+
+// WithSnapshots compares every response to its snapshot in the snapshot dir, and writes the missing snapshots
+func WithSnapshots(snapshotOptions SnapshotOptions) Option {
+	return func(cfg *Config) {
+		cfg.snapshotOptions = snapshotOptions
+	}
+}
+
+// WithCassettes records the responses to cassettes, or replays them instead of sending the requests
+func WithCassettes(cassetteOptions CassetteOptions) Option {
+	return func(cfg *Config) {
+		cfg.cassetteOptions = cassetteOptions
+	}
+}
+
+// WithTransport sends the requests via the transport instead of the network, the TLS and proxy options are ignored
+func WithTransport(transport http.RoundTripper) Option {
+	return func(cfg *Config) {
+		cfg.customTransport = transport
+	}
+}
+
+// WithHandler sends the requests to the handler in-process instead of the network, e.g. in Go tests
+func WithHandler(handler http.Handler) Option {
+	return WithTransport(&_HandlerRoundTripper{handler: handler})
+}
+
+// WithVariables sets variables overriding the ones of the environment and the ".env" file, e.g. "baseUrl"
+func WithVariables(variables map[string]string) Option {
+	return func(cfg *Config) {
+		cfg.variables = variables
+	}
+}
+
+// WithSchema validates the JSON body of every response against the JSON Schema file, requests can override it via
+// "schema" in the "settings" section
+func WithSchema(schemaFilePath string) Option {
+	return func(cfg *Config) {
+		cfg.schemaFilePath = schemaFilePath
+	}
+}
+
+// WithOpenAPI checks every response against the operation of its request in the OpenAPI 3 or Swagger 2 spec: the
+// status is documented, the required headers are present and the JSON body matches the schema
+func WithOpenAPI(specFilePath string) Option {
+	return func(cfg *Config) {
+		cfg.openAPISpecFilePath = specFilePath
+	}
+}
+
+// WithFilter prints and saves the result of the filter instead of the body of every response. The filter is a
+// JSONPath or jq path like "$.items[*].id" or ".items[0].id", or an expression of the "assert" section like
+// "res.body.items[0].id"
+func WithFilter(filter string) Option {
+	return func(cfg *Config) {
+		cfg.filter = filter
+	}
+}
+
+// WithRedactedSecrets replaces the values of the secret variables with placeholders like "<redacted:apiKey>".
+// Secret variables are the ones in the "vars:secret" section of the environment, the ".env" file and the process
+// environment allowed by the allow-list. Only defined variables are redacted, undefined ones fail as usual.
+func WithRedactedSecrets(redactSecrets bool) Option {
+	return func(cfg *Config) {
+		cfg.redactSecrets = redactSecrets
+	}
+}
+
+// WithPrinter prints every response using printer
+func WithPrinter(printer *bruprinter.Printer) Option {
+	return func(cfg *Config) {
+		cfg.printer = printer
+	}
 }
 
 var ErrEmptyBruFilePath = errors.New("empty bru file path")
 
-func NewConfig(bruFilePath string, saveOutput bool, outputFilePath string, envName string, prettyPrint bool) (*Config, error) {
+func NewConfig(bruFilePath string, saveOutput bool, outputFilePath string, envName string, prettyPrint bool,
+	processEnvAllowList []string, opts ...Option,
+) (*Config, error) {
 	if bruFilePath == "" {
 		return nil, ErrEmptyBruFilePath
 	}
-	if !fileExists(bruFilePath) {
+	for _, pattern := range processEnvAllowList {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid process env allow-list pattern '%s': %w", pattern, err)
+		}
+	}
+	if !fileExists(bruFilePath) && !dirExists(bruFilePath) {
 		return nil, fmt.Errorf("file does not exist '%s': %w", bruFilePath, os.ErrNotExist)
 	}
 
-	return &Config{
+	cfg := &Config{
 		bruFilePath:     bruFilePath,
 		saveOutput:      saveOutput,
 		outputFilePath:  outputFilePath,
 		environmentName: envName,
 		prettyPrint:     prettyPrint,
-	}, nil
+
+		processEnvAllowList: processEnvAllowList,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if err := cfg.tlsOptions.validate(); err != nil {
+		return nil, err
+	}
+	if _, err := newRetryPolicy(cfg.retryOptions); err != nil {
+		return nil, err
+	}
+	if err := cfg.cassetteOptions.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.iterationOptions.Iterations < 0 {
+		return nil, fmt.Errorf("%w: iterations must not be negative", ErrInvalidIterationData)
+	}
+	if cfg.iterationOptions.DataFilePath != "" {
+		iterationData, err := loadIterationData(cfg.iterationOptions.DataFilePath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.iterationData = iterationData
+	}
+	if err := bruexpr.ValidateQuery(cfg.filter); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+	snapshotRules, err := newSnapshotRules(cfg.snapshotOptions)
+	if err != nil {
+		return nil, err
+	}
+	cfg.snapshotRules = snapshotRules
+	if cfg.schemaFilePath != "" {
+		if cfg.schema, err = compileSchema(cfg.schemaFilePath); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.openAPISpecFilePath != "" {
+		if cfg.openAPISpec, err = brucontract.LoadSpec(cfg.openAPISpecFilePath); err != nil {
+			return nil, err
+		}
+	}
+
+	transport := cfg.customTransport
+	if transport == nil {
+		if transport, err = cfg.newTransport(); err != nil {
+			return nil, fmt.Errorf("could not create transport: %w", err)
+		}
+	}
+	cfg.transport = newCassetteRoundTripper(cfg.cassetteOptions, transport)
+
+	cookieJar, err := newCookieJar(cfg.cookieJarFilePath)
+	if err != nil {
+		return nil, err
+	}
+	cfg.cookieJar = cookieJar
+	cfg.runtimeVariables = newRuntimeVariables()
+	return cfg, nil
+}
+
+// ForFile returns the config of another Bru file of the same collection, sharing the transport, the cookies and the
+// variables set by the earlier requests, e.g. to run the requests of a collection one at a time
+func (cfg Config) ForFile(bruFilePath string) Config {
+	cfg.bruFilePath = bruFilePath
+	return cfg
 }
 
 func (cfg Config) getBruFile() (*bruparser.BruFile, error) {
@@ -73,6 +354,15 @@ func (cfg Config) getBruFile() (*bruparser.BruFile, error) {
 	}
 
 	bruFile.SetVariables(variables)
+	processEnv := cfg.getProcessEnvVariables()
+	if cfg.redactSecrets {
+		for k := range processEnv {
+			processEnv[k] = redactedValue(k)
+		}
+	}
+	bruFile.SetProcessEnv(processEnv)
+	bruFile.SetDir(filepath.Dir(cfg.bruFilePath))
+	bruFile.SetRuntimeVariables(cfg.runtimeVariables.snapshot())
 	log.Info().
 		Str("file", cfg.bruFilePath).
 		Any("bruFile", bruFile).
@@ -80,6 +370,24 @@ func (cfg Config) getBruFile() (*bruparser.BruFile, error) {
 	return bruFile, nil
 }
 
+// filterOutput returns the result of the filter as text, or the body if there is no filter
+func (cfg Config) filterOutput(resp bruexpr.Response) ([]byte, error) {
+	if cfg.filter == "" {
+		return resp.Body, nil
+	}
+
+	// Validated in NewConfig
+	value, err := resp.Query(cfg.filter)
+	if err != nil {
+		return nil, fmt.Errorf("could not filter response: %w", err)
+	}
+	output, err := bruexpr.FormatValue(value)
+	if err != nil {
+		return nil, fmt.Errorf("could not filter response: %w", err)
+	}
+	return []byte(output), nil
+}
+
 func (cfg Config) maybeSaveOutput(data []byte) error {
 	if !cfg.saveOutput {
 		return nil
@@ -92,12 +400,26 @@ func (cfg Config) maybeSaveOutput(data []byte) error {
 		fileName = strings.Trim(fileName, "-")
 		extension := mimetype.Detect(data).Extension()
 		suffix := getHash(data) + extension
-		cfg.outputFilePath = path.Join(
-			os.TempDir(), strings.Join([]string{"bru", "output", fileName, cfg.environmentName, suffix}, "-"))
+		parts := []string{"bru", "output", fileName, cfg.environmentName}
+		if cfg.iteration > 0 {
+			parts = append(parts, "iteration", strconv.Itoa(cfg.iteration))
+		}
+		cfg.outputFilePath = path.Join(os.TempDir(), strings.Join(append(parts, suffix), "-"))
+	} else if cfg.iteration > 0 && cfg.outputFilePath != _stdoutFilePath {
+		// e.g. "out.json" becomes "out-2.json" for the second iteration
+		extension := path.Ext(cfg.outputFilePath)
+		cfg.outputFilePath = fmt.Sprintf("%s-%d%s",
+			strings.TrimSuffix(cfg.outputFilePath, extension), cfg.iteration, extension)
 	}
 	if cfg.prettyPrint {
 		data = maybePrettyPrint(data)
 	}
+	if cfg.outputFilePath == _stdoutFilePath {
+		if _, err := os.Stdout.Write(data); err != nil {
+			return fmt.Errorf("could not write to stdout: %w", err)
+		}
+		return nil
+	}
 	if err := os.WriteFile(cfg.outputFilePath, data, 0o600); err != nil {
 		return fmt.Errorf("could not write file: %w", err)
 	}
@@ -109,7 +431,7 @@ func (cfg Config) maybeSaveOutput(data []byte) error {
 }
 
 func (cfg Config) getVariables() (map[string]string, error) {
-	var1, err := cfg.getVariablesFromBruEnvironment()
+	environment, err := cfg.getBruEnvironment()
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +441,12 @@ func (cfg Config) getVariables() (map[string]string, error) {
 		return nil, err
 	}
 
+	var1 := make(map[string]string)
+	secretVars := make([]string, 0)
+	if environment != nil {
+		var1 = environment.Variables()
+		secretVars = environment.SecretVariables()
+	}
 	variables := make(map[string]string, len(var1)+len(var2))
 	for k, v := range var1 {
 		variables[k] = v
@@ -126,43 +454,112 @@ func (cfg Config) getVariables() (map[string]string, error) {
 	for k, v := range var2 {
 		variables[k] = v
 	}
+	if cfg.redactSecrets {
+		// The ".env" file is meant for secrets
+		for k := range var2 {
+			variables[k] = redactedValue(k)
+		}
+		// Undefined secret variables are left undefined, so that the request fails to resolve like without redaction
+		for _, k := range secretVars {
+			if _, ok := variables[k]; ok {
+				variables[k] = redactedValue(k)
+			}
+		}
+	}
+	for k, v := range cfg.variables {
+		variables[k] = v
+	}
+	for k, v := range cfg.iterationVariables {
+		variables[k] = v
+	}
 	return variables, nil
 }
 
-func (cfg Config) getVariablesFromBruEnvironment() (map[string]string, error) {
+// getProcessEnvVariables returns the process environment variables permitted by the allow-list
+func (cfg Config) getProcessEnvVariables() map[string]string {
+	variables := make(map[string]string)
+	for _, kv := range os.Environ() {
+		k, v, found := strings.Cut(kv, "=")
+		if !found || !cfg.isProcessEnvAllowed(k) {
+			continue
+		}
+		variables[k] = v
+	}
+	log.Debug().
+		Int("variables", len(variables)).
+		Strs("allowList", cfg.processEnvAllowList).
+		Msg("Loading process environment variables")
+	return variables
+}
+
+func (cfg Config) isProcessEnvAllowed(key string) bool {
+	if len(cfg.processEnvAllowList) == 0 {
+		return true
+	}
+	for _, pattern := range cfg.processEnvAllowList {
+		// Patterns are validated in NewConfig
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// getBruEnvironment returns the environment file selected via environmentName, nil if there is none
+func (cfg Config) getBruEnvironment() (*bruparser.BruFile, error) {
+	filePath, err := cfg.environmentFilePath()
+	if err != nil || filePath == "" {
+		return nil, err
+	}
+	return getEnvironmentFromFile(filePath)
+}
+
+// environmentFilePath returns the path of the environment file selected via environmentName, empty if there is none
+func (cfg Config) environmentFilePath() (string, error) {
 	if cfg.environmentName == "" {
-		return make(map[string]string), nil
+		return "", nil
 	}
 
-	parentDir := path.Dir(cfg.bruFilePath)
+	parentDir, err := filepath.Abs(cfg.searchDir())
+	if err != nil {
+		return "", fmt.Errorf("could not get absolute path of '%s': %w", cfg.bruFilePath, err)
+	}
 	for {
-		envDir := path.Join(parentDir, _BrunoEnvironmentsDirName)
-		if !dirExists(envDir) {
-			parentDir = path.Dir(parentDir)
-			if parentDir == "/" {
-				break
-			}
-			continue
-		}
-		file := path.Join(envDir, cfg.environmentName+".bru")
+		file := path.Join(parentDir, _BrunoEnvironmentsDirName, cfg.environmentName+".bru")
 		if fileExists(file) {
-			return getVariablesFromFile(file)
+			return file, nil
 		}
 
 		if isBrunoCollectionRootDir(parentDir) {
 			log.Warn().
 				Str("dir", parentDir).
 				Str("envName", cfg.environmentName).
-				Msg("reached top of bruno collection dir but 'environments' dir not found")
+				Msg("reached top of bruno collection dir but environment file not found")
+			break
+		}
+		if path.Dir(parentDir) == parentDir {
 			break
 		}
+		parentDir = path.Dir(parentDir)
 	}
 
-	return make(map[string]string), nil
+	return "", nil
 }
 
 func (cfg Config) getVariablesFromEnvFile() (map[string]string, error) {
-	parentDir := path.Dir(cfg.bruFilePath)
+	envFile, err := cfg.envFilePath()
+	if err != nil {
+		return nil, err
+	}
+	if envFile == "" {
+		return make(map[string]string), nil
+	}
+	return getVariablesFromEnvFile(envFile)
+}
+
+// envFilePath returns the path of the nearest ".env" file up to the root of the collection, empty if there is none
+func (cfg Config) envFilePath() (string, error) {
+	parentDir := cfg.searchDir()
 	for {
 		log.Debug().
 			Str("dir", parentDir).
@@ -170,7 +567,7 @@ func (cfg Config) getVariablesFromEnvFile() (map[string]string, error) {
 			Msg("searching for '.env' file")
 		envFile := path.Join(parentDir, ".env")
 		if fileExists(envFile) {
-			return getVariablesFromEnvFile(envFile)
+			return envFile, nil
 		}
 		if isBrunoCollectionRootDir(parentDir) {
 			log.Info().
@@ -181,18 +578,30 @@ func (cfg Config) getVariablesFromEnvFile() (map[string]string, error) {
 		}
 		currentDir, err := filepath.Abs(parentDir)
 		if err != nil {
-			return nil, fmt.Errorf("could not get absolute path of '%s': %w", parentDir, err)
+			return "", fmt.Errorf("could not get absolute path of '%s': %w", parentDir, err)
 		}
 		parentDir = path.Dir(currentDir)
 		if parentDir == "/" {
 			break
 		}
 	}
-	return make(map[string]string), nil
+	return "", nil
+}
+
+// searchDir returns the directory to start searching for the collection config, environments and ".env" from
+func (cfg Config) searchDir() string {
+	if dirExists(cfg.bruFilePath) {
+		return cfg.bruFilePath
+	}
+	return path.Dir(cfg.bruFilePath)
 }
 
 func isBrunoCollectionRootDir(parentDir string) bool {
-	return fileExists(path.Join(parentDir, "bruno.json"))
+	return fileExists(path.Join(parentDir, _BrunoCollectionConfigFileName))
+}
+
+func redactedValue(name string) string {
+	return "<redacted:" + name + ">"
 }
 
 func maybePrettyPrint(data []byte) []byte {
@@ -215,7 +624,7 @@ func maybePrettyPrint(data []byte) []byte {
 	return data
 }
 
-func getVariablesFromFile(filePath string) (map[string]string, error) {
+func getEnvironmentFromFile(filePath string) (*bruparser.BruFile, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("could not open file: %w", err)
@@ -229,8 +638,9 @@ func getVariablesFromFile(filePath string) (map[string]string, error) {
 	log.Info().
 		Str("file", filePath).
 		Any("variables", bruFile.Variables()).
+		Strs("secretVariables", bruFile.SecretVariables()).
 		Msg("Loading environment variables")
-	return bruFile.Variables(), nil
+	return bruFile, nil
 }
 
 func getVariablesFromEnvFile(filePath string) (map[string]string, error) {
@@ -0,0 +1,120 @@
+package brurunner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func TestRun_Filter(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "abc")
+		_, _ = w.Write([]byte(`{"items": [{"id": 7, "name": "foo"}, {"id": 8, "name": "bar"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	testCases := map[string]string{
+		"":                         `{"items": [{"id": 7, "name": "foo"}, {"id": 8, "name": "bar"}]}`,
+		"$.items[*].id":            `[7,8]`,
+		".items[0].name":           `foo`,
+		"res.body.items[-1]":       `{"id":8,"name":"bar"}`,
+		"res.headers.x-request-id": `abc`,
+		".missing":                 `null`,
+	}
+	for filter, expected := range testCases {
+		t.Run(filter, func(t *testing.T) {
+			t.Parallel()
+			bruFilePath := filepath.Join(t.TempDir(), "get.bru")
+			writeRequest(t, bruFilePath, 1, server.URL, "\nassert {\n  res.body.items[*].id: length 2\n}\n")
+			outputFilePath := filepath.Join(dir, filepath.Base(t.Name())+".out")
+			cfg, err := NewConfig(bruFilePath, true, outputFilePath, "", false, nil, WithFilter(filter))
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+			require.NoError(t, result.Err)
+			require.Len(t, result.Assertions, 1)
+
+			output, err := os.ReadFile(outputFilePath)
+			require.NoError(t, err)
+			require.Equal(t, expected, string(output))
+		})
+	}
+}
+
+func TestNewConfig_InvalidFilter(t *testing.T) {
+	t.Parallel()
+	bruFilePath := filepath.Join(t.TempDir(), "get.bru")
+	writeRequest(t, bruFilePath, 1, "http://localhost", "")
+	_, err := NewConfig(bruFilePath, false, "", "", false, nil, WithFilter(".items[x]"))
+	require.Error(t, err)
+	_, err = NewConfig(bruFilePath, false, "", "", false, nil, WithFilter("res.foo"))
+	require.Error(t, err)
+}
+
+type _RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f _RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRun_HandlerAndTransport(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	dir := t.TempDir()
+	bruFilePath := filepath.Join(dir, "get.bru")
+	writeRequest(t, bruFilePath, 1, "{{baseUrl}}/users/1", "")
+	variables := WithVariables(map[string]string{"baseUrl": "http://api.test"})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("boom")
+		}
+		_, _ = w.Write([]byte(r.Host + " " + r.RequestURI))
+	})
+	cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, variables, WithHandler(handler))
+	require.NoError(t, err)
+	result := Run(t.Context(), *cfg)
+	require.NoError(t, result.Err)
+	require.Equal(t, "api.test /users/1", string(result.Response.Body))
+
+	writeRequest(t, bruFilePath, 1, "{{baseUrl}}/panic", "")
+	result = Run(t.Context(), *cfg)
+	require.ErrorIs(t, result.Err, ErrTransport)
+	require.ErrorContains(t, result.Err, "handler panicked: boom")
+
+	transport := _RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusTeapot, Body: http.NoBody, Request: req}, nil
+	})
+	cfg, err = NewConfig(bruFilePath, false, "", "", false, nil, variables, WithTransport(transport))
+	require.NoError(t, err)
+	result = Run(t.Context(), *cfg)
+	require.NoError(t, result.Err)
+	require.Equal(t, http.StatusTeapot, result.Response.StatusCode)
+}
+
+func TestRun_InvalidPostResponseVariable(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	t.Cleanup(server.Close)
+
+	bruFilePath := filepath.Join(t.TempDir(), "get.bru")
+	writeRequest(t, bruFilePath, 1, server.URL, "\nvars:post-response {\n  id: res.foo\n}\n")
+	cfg, err := NewConfig(bruFilePath, false, "", "", false, nil)
+	require.NoError(t, err)
+	result := Run(t.Context(), *cfg)
+	// No assertion ran, so the run does not fail as an assertion failure
+	require.ErrorIs(t, result.Err, ErrParse)
+	require.NotErrorIs(t, result.Err, ErrAssertionFailed)
+	require.Empty(t, result.Assertions)
+}
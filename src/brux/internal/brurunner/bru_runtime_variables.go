@@ -0,0 +1,62 @@
+package brurunner
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+// _RuntimeVariables are the variables set by the requests of a run via "vars:post-response",
+// visible to all the subsequent requests of the run
+type _RuntimeVariables struct {
+	mu   sync.Mutex
+	vars map[string]string
+}
+
+func newRuntimeVariables() *_RuntimeVariables {
+	return &_RuntimeVariables{vars: make(map[string]string)}
+}
+
+// snapshot returns a copy of the current variables
+func (r *_RuntimeVariables) snapshot() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return maps.Clone(r.vars)
+}
+
+// setFromResponse evaluates the "vars:post-response" expressions against the response and stores the results
+func (r *_RuntimeVariables) setFromResponse(postResponseVars []bruparser.KeyValue, resp bruexpr.Response) error {
+	for _, kv := range postResponseVars {
+		value, err := evaluatePostResponseVariable(kv.Value, resp)
+		if err != nil {
+			return fmt.Errorf("could not evaluate post-response variable '%s': %w", kv.Key, err)
+		}
+
+		r.mu.Lock()
+		r.vars[kv.Key] = value
+		r.mu.Unlock()
+		log.Debug().
+			Str("key", kv.Key).
+			Msg("runtime variable set")
+	}
+	return nil
+}
+
+// evaluatePostResponseVariable evaluates expressions like "res.body.token", other values are used as is
+func evaluatePostResponseVariable(expr string, resp bruexpr.Response) (string, error) {
+	if !strings.HasPrefix(expr, "res.") {
+		return expr, nil
+	}
+
+	value, err := resp.Resolve(expr)
+	if err != nil {
+		return "", err
+	}
+	return bruexpr.FormatValue(value)
+}
@@ -0,0 +1,102 @@
+package brurunner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
+)
+
+// Operator of the assertion results of the schema validation, e.g. "res.body: matchesSchema user.schema.json"
+const _schemaOperator = "matchesSchema"
+
+// _ResponseSchema is a compiled JSON Schema file that the response bodies are validated against
+type _ResponseSchema struct {
+	// As passed via WithSchema or the "schema" setting
+	filePath string
+	schema   *jsonschema.Schema
+}
+
+// compileSchema compiles the JSON Schema file, draft 2020-12 unless it declares another draft like draft-07 via
+// "$schema"
+func compileSchema(filePath string) (*_ResponseSchema, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path of '%s': %w", filePath, err)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	schema, err := compiler.Compile(absFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not compile schema '%s': %w", filePath, err)
+	}
+	return &_ResponseSchema{filePath: filePath, schema: schema}, nil
+}
+
+// getSchema returns the schema of the "schema" setting, relative to the Bru file, or the one of WithSchema, nil if
+// there is neither
+func (s _RequestSettings) getSchema(cfg Config) (*_ResponseSchema, error) {
+	if s.schema == nil {
+		return cfg.schema, nil
+	}
+	if *s.schema == "" {
+		// Disables the schema of WithSchema
+		return nil, nil
+	}
+
+	filePath := *s.schema
+	if !filepath.IsAbs(filePath) {
+		filePath = filepath.Join(filepath.Dir(cfg.bruFilePath), filePath)
+	}
+	schema, err := compileSchema(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schema: %w", ErrInvalidSetting, err)
+	}
+	schema.filePath = *s.schema
+	return schema, nil
+}
+
+// validate validates the JSON body and returns an assertion result per violation, reported by its JSON pointer,
+// or a single passed result if the body is valid
+func (s _ResponseSchema) validate(body []byte) []bruexpr.AssertionResult {
+	newResult := func(err error) bruexpr.AssertionResult {
+		return bruexpr.AssertionResult{
+			Expression: "res.body",
+			Operator:   _schemaOperator,
+			Expected:   s.filePath,
+			Passed:     err == nil,
+			Err:        err,
+		}
+	}
+
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []bruexpr.AssertionResult{newResult(fmt.Errorf("body is not JSON: %w", err))}
+	}
+	err = s.schema.Validate(value)
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return []bruexpr.AssertionResult{newResult(err)}
+	}
+
+	results := make([]bruexpr.AssertionResult, 0)
+	for _, unit := range validationErr.BasicOutput().Errors {
+		if unit.Error == nil {
+			continue
+		}
+		pointer := unit.InstanceLocation
+		if pointer == "" {
+			pointer = "/"
+		}
+		results = append(results, newResult(fmt.Errorf("at '%s': %s", pointer, unit.Error)))
+	}
+	if len(results) == 0 {
+		results = append(results, newResult(validationErr))
+	}
+	return results
+}
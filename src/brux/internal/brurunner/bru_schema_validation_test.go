@@ -0,0 +1,181 @@
+package brurunner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+const _testUserSchema = `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["id", "name"],
+  "properties": {
+    "id": {"type": "integer"},
+    "name": {"type": "string"},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+func TestRun_Schema(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/valid":
+			_, _ = w.Write([]byte(`{"id": 1, "name": "Ada", "tags": ["admin"]}`))
+		case "/invalid":
+			_, _ = w.Write([]byte(`{"id": "1", "tags": ["admin", 2]}`))
+		default:
+			_, _ = w.Write([]byte(`not json`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "schemas", "user.json"), _testUserSchema)
+	writeFile(t, filepath.Join(dir, "schemas", "list.json"), `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "array"
+}`)
+	testCases := []struct {
+		name     string
+		settings string
+		urlPath  string
+		expected []string
+	}{
+		{
+			name:     "valid",
+			urlPath:  "/valid",
+			expected: []string{"res.body: matchesSchema " + filepath.Join(dir, "schemas", "user.json")},
+		},
+		{
+			name:    "invalid",
+			urlPath: "/invalid",
+			expected: []string{
+				"res.body: matchesSchema " + filepath.Join(dir, "schemas", "user.json") + " (at '/': missing property 'name')",
+				"res.body: matchesSchema " + filepath.Join(dir, "schemas", "user.json") + " (at '/id': got string, want integer)",
+				"res.body: matchesSchema " + filepath.Join(dir, "schemas", "user.json") + " (at '/tags/1': got number, want string)",
+			},
+		},
+		{
+			name:     "setting",
+			settings: "\nsettings {\n  schema: schemas/list.json\n}\n",
+			urlPath:  "/valid",
+			expected: []string{"res.body: matchesSchema schemas/list.json (at '/': got object, want array)"},
+		},
+		{
+			name:     "disabled",
+			settings: "\nsettings {\n  schema: \n}\n",
+			urlPath:  "/invalid",
+		},
+		{
+			name:     "not json",
+			urlPath:  "/text",
+			expected: []string{"res.body: matchesSchema " + filepath.Join(dir, "schemas", "user.json") + " (body is not JSON: invalid character 'o' in literal null (expecting 'u'))"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			bruFilePath := filepath.Join(dir, testCase.name+".bru")
+			writeRequest(t, bruFilePath, 1, server.URL+testCase.urlPath, testCase.settings)
+			cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithSchema(filepath.Join(dir, "schemas", "user.json")))
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+
+			assertions := make([]string, 0)
+			for _, assertion := range result.Assertions {
+				assertions = append(assertions, assertion.String())
+			}
+			require.ElementsMatch(t, testCase.expected, assertions)
+			if len(result.FailedAssertions()) > 0 {
+				require.ErrorIs(t, result.Err, ErrAssertionFailed)
+			} else {
+				require.NoError(t, result.Err)
+			}
+		})
+	}
+}
+
+func TestNewConfig_InvalidSchema(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	bruFilePath := filepath.Join(dir, "get.bru")
+	writeRequest(t, bruFilePath, 1, "http://localhost", "")
+	schemaFilePath := filepath.Join(dir, "schema.json")
+	writeFile(t, schemaFilePath, `{"type": 1}`)
+	_, err := NewConfig(bruFilePath, false, "", "", false, nil, WithSchema(schemaFilePath))
+	require.Error(t, err)
+	_, err = NewConfig(bruFilePath, false, "", "", false, nil, WithSchema(filepath.Join(dir, "missing.json")))
+	require.Error(t, err)
+}
+
+func TestRun_OpenAPI(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/1":
+			_, _ = w.Write([]byte(`{"id": 1, "name": "Ada"}`))
+		case "/users/2":
+			_, _ = w.Write([]byte(`{"id": "2"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	specFilePath := filepath.Join(dir, "openapi.yaml")
+	writeFile(t, specFilePath, `openapi: 3.1.0
+info:
+  title: Users API
+  version: 1.0.0
+paths:
+  /users/{id}:
+    get:
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id, name]
+                properties:
+                  id:
+                    type: integer
+`)
+	testCases := map[string][]string{
+		"/users/1": {"res: conformsTo GET /users/{id}"},
+		"/users/2": {
+			"res.body: conformsTo GET /users/{id} (at '/': missing property 'name')",
+			"res.body: conformsTo GET /users/{id} (at '/id': got string, want integer)",
+		},
+		"/users/3": {"res.status: conformsTo GET /users/{id} (status 404 is not documented)"},
+	}
+	for urlPath, expected := range testCases {
+		t.Run(urlPath, func(t *testing.T) {
+			t.Parallel()
+			bruFilePath := filepath.Join(dir, filepath.Base(urlPath)+".bru")
+			writeRequest(t, bruFilePath, 1, server.URL+urlPath, "")
+			cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithOpenAPI(specFilePath))
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+
+			assertions := make([]string, 0)
+			for _, assertion := range result.Assertions {
+				assertions = append(assertions, assertion.String())
+			}
+			require.ElementsMatch(t, expected, assertions)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package brurunner
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/rs/zerolog/log"
+
+	"github.com/ashishb/brux/src/brux/internal/jsonquery"
+)
+
+// ErrSnapshotMismatch is returned, wrapped in ErrAssertionFailed, if the response does not match its snapshot
+var ErrSnapshotMismatch = errors.New("snapshot mismatch")
+
+const (
+	_snapshotFileSuffix = ".snap.json"
+	// Replaces the values of the ignored fields and headers in the snapshots
+	_ignoredValue = "<ignored>"
+)
+
+// Headers that change on every response, their values are always ignored
+var _defaultIgnoredHeaders = []string{"Age", "Content-Length", "Date", "ETag", "Expires", "Last-Modified", "Set-Cookie"}
+
+// SnapshotOptions configures comparing the responses to snapshots saved by an earlier run
+type SnapshotOptions struct {
+	// Directory of the snapshots, disabled if empty.
+	// The snapshot of a request is named after its path in the collection, e.g. "users/get-user.snap.json".
+	Dir string
+	// Overwrite the snapshots that don't match instead of failing
+	Update bool
+	// JSONPaths of the fields of JSON bodies whose values are ignored, e.g. "$.createdAt" or "$.items[*].id"
+	IgnorePaths []string
+	// Regular expressions matching the names of the headers whose values are ignored, e.g. "^X-Request-"
+	IgnoreHeaders []string
+}
+
+// _Snapshot is the JSON representation of a response saved as a snapshot
+type _Snapshot struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// The decoded body for JSON bodies, the body for other text bodies
+	Body any `json:"body,omitempty"`
+	// SHA-256 of binary bodies
+	BodySHA256 string `json:"bodySha256,omitempty"`
+}
+
+// _SnapshotRules are the compiled ignore rules of SnapshotOptions
+type _SnapshotRules struct {
+	ignorePaths   []string
+	ignoreHeaders []*regexp.Regexp
+}
+
+func newSnapshotRules(snapshotOptions SnapshotOptions) (*_SnapshotRules, error) {
+	rules := &_SnapshotRules{ignorePaths: snapshotOptions.IgnorePaths}
+	for _, path := range snapshotOptions.IgnorePaths {
+		if _, err := jsonquery.Replace(nil, path, nil); err != nil {
+			return nil, fmt.Errorf("invalid snapshot ignore path: %w", err)
+		}
+	}
+	for _, header := range _defaultIgnoredHeaders {
+		rules.ignoreHeaders = append(rules.ignoreHeaders, regexp.MustCompile("(?i)^"+regexp.QuoteMeta(header)+"$"))
+	}
+	for _, pattern := range snapshotOptions.IgnoreHeaders {
+		regex, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snapshot ignore header '%s': %w", pattern, err)
+		}
+		rules.ignoreHeaders = append(rules.ignoreHeaders, regex)
+	}
+	return rules, nil
+}
+
+// newSnapshot returns the snapshot of the response with the values of the ignored fields and headers replaced
+func (r _SnapshotRules) newSnapshot(resp *ResponseInfo) *_Snapshot {
+	snapshot := &_Snapshot{Status: resp.StatusCode, Headers: make(map[string]string, len(resp.Headers))}
+	for name, values := range resp.Headers {
+		snapshot.Headers[name] = strings.Join(values, ", ")
+		for _, regex := range r.ignoreHeaders {
+			if regex.MatchString(name) {
+				snapshot.Headers[name] = _ignoredValue
+				break
+			}
+		}
+	}
+
+	switch {
+	case len(resp.Body) == 0:
+	case json.Valid(resp.Body):
+		// Numbers are kept as they are, e.g. large IDs
+		body, _ := jsonquery.Decode(resp.Body)
+		for _, path := range r.ignorePaths {
+			// Paths are validated in newSnapshotRules
+			_, _ = jsonquery.Replace(body, path, _ignoredValue)
+		}
+		snapshot.Body = body
+	case utf8.Valid(resp.Body):
+		snapshot.Body = string(resp.Body)
+	default:
+		hash := sha256.Sum256(resp.Body)
+		snapshot.BodySHA256 = hex.EncodeToString(hash[:])
+	}
+	return snapshot
+}
+
+// checkSnapshot compares the response to its snapshot, which is written if it does not exist yet, or overwritten if
+// it does not match and the snapshots are updated
+func (cfg Config) checkSnapshot(resp *ResponseInfo) error {
+	if cfg.snapshotOptions.Dir == "" {
+		return nil
+	}
+
+	filePath, err := cfg.snapshotFilePath()
+	if err != nil {
+		return err
+	}
+	actual, err := marshalSnapshot(cfg.snapshotRules.newSnapshot(resp))
+	if err != nil {
+		return err
+	}
+
+	expected, err := os.ReadFile(filePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		log.Info().
+			Str("snapshotFilePath", filePath).
+			Msg("snapshot written")
+		return writeSnapshot(filePath, actual)
+	case err != nil:
+		return fmt.Errorf("could not read snapshot: %w", err)
+	case bytes.Equal(expected, actual):
+		return nil
+	case cfg.snapshotOptions.Update:
+		log.Info().
+			Str("snapshotFilePath", filePath).
+			Msg("snapshot updated")
+		return writeSnapshot(filePath, actual)
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(actual)),
+		FromFile: filePath,
+		ToFile:   "response",
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("could not diff snapshot: %w", err)
+	}
+	if cfg.printer != nil {
+		if err := cfg.printer.PrintDiff("Snapshot "+filePath+" does not match", diff); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %w: '%s', pass --update-snapshots to update it", ErrAssertionFailed, ErrSnapshotMismatch, filePath)
+}
+
+// snapshotFilePath returns the path of the snapshot of the Bru file, named after its path in the collection, and the
+// iteration if iterating, e.g. "users/get-user.2.snap.json" for the second iteration
+func (cfg Config) snapshotFilePath() (string, error) {
+	collectionConfig, err := getCollectionConfig(cfg.searchDir())
+	if err != nil {
+		return "", err
+	}
+
+	name := filepath.Base(cfg.bruFilePath)
+	if collectionConfig != nil {
+		bruFilePath, err := filepath.Abs(cfg.bruFilePath)
+		if err != nil {
+			return "", fmt.Errorf("could not get absolute path of '%s': %w", cfg.bruFilePath, err)
+		}
+		if name, err = filepath.Rel(collectionConfig.rootDir, bruFilePath); err != nil {
+			return "", fmt.Errorf("could not get path of '%s' in the collection: %w", cfg.bruFilePath, err)
+		}
+	}
+	return filepath.Join(cfg.snapshotOptions.Dir, snapshotFileName(name, cfg.iteration)), nil
+}
+
+// snapshotFileName returns the name of the snapshot of the Bru file at the path in the collection, e.g.
+// "users/get-user.2.snap.json" of "users/get-user.bru" for the second iteration
+func snapshotFileName(bruFilePath string, iteration int) string {
+	name := strings.TrimSuffix(bruFilePath, filepath.Ext(bruFilePath))
+	if iteration > 0 {
+		name += "." + strconv.Itoa(iteration)
+	}
+	return name + _snapshotFileSuffix
+}
+
+// SnapshotResponse is a response saved as a snapshot, e.g. to be served by a mock server
+type SnapshotResponse struct {
+	StatusCode int
+	// Without the ignored headers
+	Headers http.Header
+	// Re-encoded for JSON bodies, nil for binary bodies as only their hash is saved
+	Body []byte
+}
+
+// ReadSnapshot reads the snapshot of the Bru file at the path in the collection, e.g. "users/get-user.bru", from
+// the snapshot dir, nil if there is none
+func ReadSnapshot(snapshotDir string, bruFilePath string) (*SnapshotResponse, error) {
+	filePath := filepath.Join(snapshotDir, snapshotFileName(bruFilePath, 0))
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read snapshot: %w", err)
+	}
+
+	var snapshot _Snapshot
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Numbers are kept as they are, e.g. large IDs
+	decoder.UseNumber()
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("could not decode snapshot '%s': %w", filePath, err)
+	}
+	resp := &SnapshotResponse{StatusCode: snapshot.Status, Headers: make(http.Header, len(snapshot.Headers))}
+	for name, value := range snapshot.Headers {
+		if value != _ignoredValue {
+			resp.Headers.Set(name, value)
+		}
+	}
+	switch body := snapshot.Body.(type) {
+	case nil:
+	case string:
+		resp.Body = []byte(body)
+	default:
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(body); err != nil {
+			return nil, fmt.Errorf("could not encode body of snapshot '%s': %w", filePath, err)
+		}
+		resp.Body = buf.Bytes()
+	}
+	return resp, nil
+}
+
+func marshalSnapshot(snapshot *_Snapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(snapshot); err != nil {
+		return nil, fmt.Errorf("could not marshal snapshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeSnapshot(filePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o750); err != nil {
+		return fmt.Errorf("could not create snapshot dir: %w", err)
+	}
+	if err := os.WriteFile(filePath, data, 0o600); err != nil {
+		return fmt.Errorf("could not write snapshot: %w", err)
+	}
+	return nil
+}
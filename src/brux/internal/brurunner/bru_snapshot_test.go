@@ -0,0 +1,99 @@
+package brurunner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func TestRun_Snapshots(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	var requests atomic.Int32
+	var name atomic.Value
+	name.Store("Ada")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", fmt.Sprint(n))
+		_, _ = fmt.Fprintf(w, `{"id": 12345678901234567890, "name": %q, "items": [{"createdAt": %d}, {"createdAt": %d}]}`,
+			name.Load(), n, n)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version": "1", "name": "test"}`)
+	bruFilePath := filepath.Join(dir, "users", "get-user.bru")
+	writeRequest(t, bruFilePath, 1, server.URL+"/users/1", "")
+	snapshotDir := filepath.Join(dir, "__snapshots__")
+	snapshotFilePath := filepath.Join(snapshotDir, "users", "get-user.snap.json")
+	run := func(update bool) *Result {
+		cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithSnapshots(SnapshotOptions{
+			Dir:           snapshotDir,
+			Update:        update,
+			IgnorePaths:   []string{"$.items[*].createdAt"},
+			IgnoreHeaders: []string{"^x-request-"},
+		}))
+		require.NoError(t, err)
+		return Run(t.Context(), *cfg)
+	}
+
+	// The first run writes the snapshot, the next one matches it despite the ignored fields and headers
+	require.NoError(t, run(false).Err)
+	data, err := os.ReadFile(snapshotFilePath)
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "status": 200,
+  "headers": {
+    "Content-Length": "<ignored>",
+    "Content-Type": "application/json",
+    "Date": "<ignored>",
+    "X-Request-Id": "<ignored>"
+  },
+  "body": {
+    "id": 12345678901234567890,
+    "items": [
+      {
+        "createdAt": "<ignored>"
+      },
+      {
+        "createdAt": "<ignored>"
+      }
+    ],
+    "name": "Ada"
+  }
+}
+`, string(data))
+	require.NoError(t, run(false).Err)
+
+	name.Store("Bob")
+	result := run(false)
+	require.ErrorIs(t, result.Err, ErrSnapshotMismatch)
+	require.ErrorIs(t, result.Err, ErrAssertionFailed)
+
+	require.NoError(t, run(true).Err)
+	require.NoError(t, run(false).Err)
+	data, err = os.ReadFile(snapshotFilePath)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"name": "Bob"`)
+}
+
+func TestNewConfig_InvalidSnapshotOptions(t *testing.T) {
+	t.Parallel()
+	bruFilePath := filepath.Join(t.TempDir(), "get.bru")
+	writeRequest(t, bruFilePath, 1, "http://localhost", "")
+	_, err := NewConfig(bruFilePath, false, "", "", false, nil,
+		WithSnapshots(SnapshotOptions{Dir: "snapshots", IgnorePaths: []string{"items[0"}}))
+	require.Error(t, err)
+	_, err = NewConfig(bruFilePath, false, "", "", false, nil,
+		WithSnapshots(SnapshotOptions{Dir: "snapshots", IgnoreHeaders: []string{"("}}))
+	require.Error(t, err)
+}
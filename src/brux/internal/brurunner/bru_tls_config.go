@@ -0,0 +1,201 @@
+package brurunner
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+var (
+	ErrInvalidTLSVersion = errors.New("invalid TLS version")
+	ErrInvalidCACert     = errors.New("no certificates found in CA cert file")
+)
+
+// TLSOptions configures TLS for all requests
+type TLSOptions struct {
+	// PEM file of additional CA certificates to trust
+	CACertFilePath string
+	// Client certificate, either a PEM file along with KeyFilePath or a PKCS#12 (.p12/.pfx) file
+	CertFilePath string
+	KeyFilePath  string
+	// Passphrase of the PKCS#12 file
+	CertPassphrase string
+	// Skip verification of the server certificate
+	Insecure bool
+	// Minimum TLS version, e.g. "1.2", defaults to Go's default
+	MinVersion string
+}
+
+var _tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func (o TLSOptions) validate() error {
+	if _, ok := _tlsVersions[o.MinVersion]; o.MinVersion != "" && !ok {
+		return fmt.Errorf("%w: '%s'", ErrInvalidTLSVersion, o.MinVersion)
+	}
+	return nil
+}
+
+// newTLSConfig creates the TLS config shared by all requests
+func (o TLSOptions) newTLSConfig() (*tls.Config, error) {
+	//nolint:gosec // Users explicitly opt into insecure mode, and TLS 1.0/1.1 for legacy servers
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: o.Insecure,
+		MinVersion:         _tlsVersions[o.MinVersion],
+	}
+
+	if o.CACertFilePath != "" {
+		rootCAs, err := loadCACerts(o.CACertFilePath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if o.CertFilePath != "" {
+		cert, err := loadClientCert(o.CertFilePath, o.KeyFilePath, o.CertPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+	return tlsConfig, nil
+}
+
+// loadCACerts returns the system cert pool along with the certificates in caCertFilePath
+func loadCACerts(caCertFilePath string) (*x509.CertPool, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Msg("could not load system cert pool")
+		rootCAs = x509.NewCertPool()
+	}
+
+	data, err := os.ReadFile(caCertFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA cert file: %w", err)
+	}
+	if !rootCAs.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("%w: '%s'", ErrInvalidCACert, caCertFilePath)
+	}
+	return rootCAs, nil
+}
+
+// loadClientCert loads a PKCS#12 file if certFilePath ends with ".p12" or ".pfx" and a PEM cert and key otherwise
+func loadClientCert(certFilePath string, keyFilePath string, passphrase string) (*tls.Certificate, error) {
+	switch strings.ToLower(filepath.Ext(certFilePath)) {
+	case ".p12", ".pfx":
+		return loadPKCS12Cert(certFilePath, passphrase)
+	default:
+		cert, err := tls.LoadX509KeyPair(certFilePath, keyFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client cert '%s': %w", certFilePath, err)
+		}
+		return &cert, nil
+	}
+}
+
+func loadPKCS12Cert(pfxFilePath string, passphrase string) (*tls.Certificate, error) {
+	data, err := os.ReadFile(pfxFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read PKCS#12 file: %w", err)
+	}
+
+	key, cert, caCerts, err := pkcs12.DecodeChain(data, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode PKCS#12 file '%s': %w", pfxFilePath, err)
+	}
+
+	tlsCert := &tls.Certificate{
+		Certificate: [][]byte{cert.Raw},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}
+	for _, caCert := range caCerts {
+		tlsCert.Certificate = append(tlsCert.Certificate, caCert.Raw)
+	}
+	return tlsCert, nil
+}
+
+// _ClientCertRoundTripper uses the transport with the client certificate of the request's domain, as configured in
+// "clientCertificates" of bruno.json, and the default transport for all other domains
+type _ClientCertRoundTripper struct {
+	defaultTransport *http.Transport
+	domains          []_DomainTransport
+}
+
+type _DomainTransport struct {
+	domain    string
+	transport *http.Transport
+}
+
+func newClientCertRoundTripper(defaultTransport *http.Transport, collectionConfig *_CollectionConfig) (http.RoundTripper, error) {
+	if collectionConfig == nil || !collectionConfig.ClientCertificates.Enabled || len(collectionConfig.ClientCertificates.Certs) == 0 {
+		return defaultTransport, nil
+	}
+
+	roundTripper := &_ClientCertRoundTripper{defaultTransport: defaultTransport}
+	for _, certConfig := range collectionConfig.ClientCertificates.Certs {
+		var cert *tls.Certificate
+		var err error
+		if certConfig.Type == "pfx" {
+			cert, err = loadPKCS12Cert(collectionConfig.resolvePath(certConfig.PfxFilePath), certConfig.Passphrase)
+		} else {
+			cert, err = loadClientCert(collectionConfig.resolvePath(certConfig.CertFilePath),
+				collectionConfig.resolvePath(certConfig.KeyFilePath), certConfig.Passphrase)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not load client cert of domain '%s': %w", certConfig.Domain, err)
+		}
+
+		transport := defaultTransport.Clone()
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*cert}
+		roundTripper.domains = append(roundTripper.domains, _DomainTransport{
+			domain:    strings.ToLower(certConfig.Domain),
+			transport: transport,
+		})
+		log.Debug().
+			Str("domain", certConfig.Domain).
+			Msg("loaded client certificate")
+	}
+	return roundTripper, nil
+}
+
+func (r *_ClientCertRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Host)
+	hostname := strings.ToLower(req.URL.Hostname())
+	for _, domain := range r.domains {
+		if matchesDomain(domain.domain, hostname) || matchesDomain(domain.domain, host) {
+			return domain.transport.RoundTrip(req)
+		}
+	}
+	return r.defaultTransport.RoundTrip(req)
+}
+
+// CloseIdleConnections closes the idle connections of all the transports
+func (r *_ClientCertRoundTripper) CloseIdleConnections() {
+	r.defaultTransport.CloseIdleConnections()
+	for _, domain := range r.domains {
+		domain.transport.CloseIdleConnections()
+	}
+}
+
+// matchesDomain matches host against a domain pattern with optional wildcards like "*.example.com"
+func matchesDomain(pattern string, host string) bool {
+	matched, err := path.Match(pattern, host)
+	return err == nil && matched
+}
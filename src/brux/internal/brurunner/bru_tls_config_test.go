@@ -0,0 +1,146 @@
+package brurunner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func TestRun_TLS(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	bruFilePath := writeBruFile(t, dir, server.URL)
+	caCertFilePath := filepath.Join(dir, "ca.pem")
+	writePEM(t, caCertFilePath, "CERTIFICATE", server.Certificate().Raw)
+
+	tests := []struct {
+		name       string
+		tlsOptions TLSOptions
+		err        error
+	}{
+		{"untrusted", TLSOptions{}, ErrTransport},
+		{"cacert", TLSOptions{CACertFilePath: caCertFilePath}, nil},
+		{"insecure", TLSOptions{Insecure: true}, nil},
+		{"min version", TLSOptions{CACertFilePath: caCertFilePath, MinVersion: "1.3"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := NewConfig(bruFilePath, false, "", "", false, nil, WithTLS(tt.tlsOptions))
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+			if tt.err == nil {
+				require.NoError(t, result.Err)
+			} else {
+				require.ErrorIs(t, result.Err, tt.err)
+			}
+		})
+	}
+
+	_, err := NewConfig(bruFilePath, false, "", "", false, nil, WithTLS(TLSOptions{MinVersion: "2.0"}))
+	require.ErrorIs(t, err, ErrInvalidTLSVersion)
+}
+
+func TestRun_ClientCertificates(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	certFilePath, keyFilePath := writeClientCert(t, dir)
+	collectionDir := filepath.Join(dir, "collection")
+	require.NoError(t, os.Mkdir(collectionDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(collectionDir, "bruno.json"), []byte(`{
+  "name": "test",
+  "clientCertificates": {
+    "enabled": true,
+    "certs": [{"domain": "127.0.0.*", "type": "cert", "certFilePath": "../client.pem", "keyFilePath": "../client.key"}]
+  }
+}`), 0o600))
+
+	tests := []struct {
+		name        string
+		bruFilePath string
+		tlsOptions  TLSOptions
+		statusCode  int
+	}{
+		{"none", writeBruFile(t, dir, server.URL), TLSOptions{Insecure: true}, http.StatusUnauthorized},
+		{"bruno.json", writeBruFile(t, collectionDir, server.URL), TLSOptions{Insecure: true}, http.StatusOK},
+		{"flags", writeBruFile(t, dir, server.URL), TLSOptions{Insecure: true, CertFilePath: certFilePath, KeyFilePath: keyFilePath}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := NewConfig(tt.bruFilePath, false, "", "", false, nil, WithTLS(tt.tlsOptions))
+			require.NoError(t, err)
+			result := Run(t.Context(), *cfg)
+			require.NoError(t, result.Err)
+			require.Equal(t, tt.statusCode, result.Response.StatusCode)
+		})
+	}
+}
+
+func writeBruFile(t *testing.T, dir string, url string) string {
+	t.Helper()
+	bruFilePath := filepath.Join(dir, "request.bru")
+	require.NoError(t, os.WriteFile(bruFilePath, []byte("get {\n  url: "+url+"\n}\n"), 0o600))
+	return bruFilePath
+}
+
+func writeClientCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "brux"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFilePath := filepath.Join(dir, "client.pem")
+	keyFilePath := filepath.Join(dir, "client.key")
+	writePEM(t, certFilePath, "CERTIFICATE", cert)
+	writePEM(t, keyFilePath, "EC PRIVATE KEY", keyBytes)
+	return certFilePath, keyFilePath
+}
+
+func writePEM(t *testing.T, filePath string, blockType string, data []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filePath, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o600))
+}
@@ -0,0 +1,140 @@
+package brurunner
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ResponseDiff is the change of the response of a Bru file since the previous run
+type ResponseDiff struct {
+	FilePath string
+	// Index of the iteration starting at 1, zero if not iterating
+	Iteration int
+	// Unified diff of the responses as saved in snapshots, with a line of context
+	Diff string
+}
+
+// Files of the folders up to the collection root that affect the requests of the folders
+var _collectionConfigFileNames = []string{_folderFileName, _collectionFileName, ".env", _BrunoCollectionConfigFileName}
+
+// WatchPaths returns the paths whose changes affect the run: the Bru file, or the directory of the folder or
+// collection, the "folder.bru", "collection.bru", ".env" and "bruno.json" files of the directories up to the
+// collection root, including the ones that don't exist yet, the environment file and the iteration data, schema and
+// OpenAPI files if set. Only the directory of a folder or collection is meant to be watched recursively.
+func (cfg Config) WatchPaths() ([]string, error) {
+	paths := []string{cfg.bruFilePath}
+	dirs, err := cfg.collectionDirs()
+	if err != nil {
+		return nil, err
+	}
+	if dirExists(cfg.bruFilePath) {
+		// The files of the folder or collection itself are watched via its directory
+		dirs = dirs[1:]
+	}
+	for _, dir := range dirs {
+		for _, fileName := range _collectionConfigFileNames {
+			paths = append(paths, filepath.Join(dir, fileName))
+		}
+	}
+	envFilePath, err := cfg.envFilePath()
+	if err != nil {
+		return nil, err
+	}
+	environmentFilePath, err := cfg.environmentFilePath()
+	if err != nil {
+		return nil, err
+	}
+	for _, filePath := range []string{
+		envFilePath, environmentFilePath, cfg.iterationOptions.DataFilePath, cfg.schemaFilePath, cfg.openAPISpecFilePath,
+	} {
+		if filePath != "" && !slices.Contains(paths, filePath) {
+			paths = append(paths, filePath)
+		}
+	}
+	return paths, nil
+}
+
+// collectionDirs returns the absolute paths of the directory of the Bru file, or of the folder or collection, and of
+// its parent directories up to the collection root, only the first one if it is not part of a collection
+func (cfg Config) collectionDirs() ([]string, error) {
+	dir, err := filepath.Abs(cfg.searchDir())
+	if err != nil {
+		return nil, fmt.Errorf("could not get absolute path of '%s': %w", cfg.bruFilePath, err)
+	}
+	dirs := []string{dir}
+	for !isBrunoCollectionRootDir(dir) {
+		parentDir := filepath.Dir(dir)
+		if parentDir == dir {
+			return dirs[:1], nil
+		}
+		dir = parentDir
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// DiffResponses compares the response of every result to the one of the same Bru file and iteration in the previous
+// results, e.g. of the previous run in watch mode, and returns the ones that changed.
+// Like in snapshots, the values of the headers that change on every response, e.g. Date, and of the ignored fields
+// and headers are left out. Results without a response are compared by their error.
+func (cfg Config) DiffResponses(previous []*Result, current []*Result) ([]ResponseDiff, error) {
+	previousResults := make(map[string]*Result, len(previous))
+	for _, result := range previous {
+		previousResults[resultKey(result)] = result
+	}
+
+	diffs := make([]ResponseDiff, 0)
+	for _, result := range current {
+		previousResult, ok := previousResults[resultKey(result)]
+		if !ok {
+			continue
+		}
+		before, err := cfg.responseText(previousResult)
+		if err != nil {
+			return nil, err
+		}
+		after, err := cfg.responseText(result)
+		if err != nil {
+			return nil, err
+		}
+		if before == after {
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(before),
+			B:        difflib.SplitLines(after),
+			FromFile: "previous",
+			ToFile:   "current",
+			Context:  1,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not diff responses: %w", err)
+		}
+		diffs = append(diffs, ResponseDiff{FilePath: result.FilePath, Iteration: result.Iteration, Diff: diff})
+	}
+	return diffs, nil
+}
+
+// responseText returns the response of the result as saved in snapshots, or its error if there is no response
+func (cfg Config) responseText(result *Result) (string, error) {
+	if result.Response == nil {
+		if result.Err == nil {
+			return "", nil
+		}
+		return "error: " + result.Err.Error() + "\n", nil
+	}
+	data, err := marshalSnapshot(cfg.snapshotRules.newSnapshot(result.Response))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func resultKey(result *Result) string {
+	return result.FilePath + "#" + strconv.Itoa(result.Iteration)
+}
@@ -0,0 +1,117 @@
+package brurunner
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_WatchPaths(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version": "1", "name": "API", "type": "collection"}`)
+	writeFile(t, filepath.Join(dir, ".env"), "TOKEN=a\n")
+	writeFile(t, filepath.Join(dir, "environments", "local.bru"), "vars {\n  baseUrl: http://localhost\n}\n")
+	writeFile(t, filepath.Join(dir, "schema.json"), `{"type": "object"}`)
+	bruFilePath := filepath.Join(dir, "users", "get.bru")
+	writeRequest(t, bruFilePath, 1, "{{baseUrl}}/users", "")
+
+	cfg, err := NewConfig(bruFilePath, false, "", "local", false, nil, WithSchema(filepath.Join(dir, "schema.json")))
+	require.NoError(t, err)
+	paths, err := cfg.WatchPaths()
+	require.NoError(t, err)
+	// The files of the folders up to the collection root are watched whether they exist or not, but not the folders
+	require.Equal(t, []string{
+		bruFilePath,
+		filepath.Join(dir, "users", "folder.bru"),
+		filepath.Join(dir, "users", "collection.bru"),
+		filepath.Join(dir, "users", ".env"),
+		filepath.Join(dir, "users", "bruno.json"),
+		filepath.Join(dir, "folder.bru"),
+		filepath.Join(dir, "collection.bru"),
+		filepath.Join(dir, ".env"),
+		filepath.Join(dir, "bruno.json"),
+		filepath.Join(dir, "environments", "local.bru"),
+		filepath.Join(dir, "schema.json"),
+	}, paths)
+
+	// The folder itself is watched as a whole
+	cfg, err = NewConfig(filepath.Join(dir, "users"), false, "", "", false, nil)
+	require.NoError(t, err)
+	paths, err = cfg.WatchPaths()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(dir, "users"),
+		filepath.Join(dir, "folder.bru"),
+		filepath.Join(dir, "collection.bru"),
+		filepath.Join(dir, ".env"),
+		filepath.Join(dir, "bruno.json"),
+	}, paths)
+}
+
+func TestConfig_WatchPaths_NoCollection(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	bruFilePath := filepath.Join(dir, "get.bru")
+	writeRequest(t, bruFilePath, 1, "http://localhost", "")
+
+	cfg, err := NewConfig(bruFilePath, false, "", "", false, nil)
+	require.NoError(t, err)
+	paths, err := cfg.WatchPaths()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		bruFilePath,
+		filepath.Join(dir, "folder.bru"),
+		filepath.Join(dir, "collection.bru"),
+		filepath.Join(dir, ".env"),
+		filepath.Join(dir, "bruno.json"),
+	}, paths)
+}
+
+func TestConfig_DiffResponses(t *testing.T) {
+	t.Parallel()
+	bruFilePath := filepath.Join(t.TempDir(), "get.bru")
+	writeRequest(t, bruFilePath, 1, "http://localhost", "")
+	cfg, err := NewConfig(bruFilePath, false, "", "", false, nil)
+	require.NoError(t, err)
+
+	newResult := func(filePath string, date string, body string) *Result {
+		return &Result{
+			FilePath: filePath,
+			Response: &ResponseInfo{
+				StatusCode: http.StatusOK,
+				Headers:    http.Header{"Date": {date}, "Content-Type": {"application/json"}},
+				Body:       []byte(body),
+			},
+		}
+	}
+	previous := []*Result{
+		newResult("a.bru", "Mon, 01 Jan 2024 00:00:00 GMT", `{"id": 1, "name": "Ada", "role": "admin"}`),
+		newResult("b.bru", "Mon, 01 Jan 2024 00:00:00 GMT", `{"id": 2}`),
+		{FilePath: "c.bru", Err: errors.New("connection refused")},
+	}
+	current := []*Result{
+		newResult("a.bru", "Tue, 02 Jan 2024 00:00:00 GMT", `{"id": 1, "name": "Grace", "role": "admin"}`),
+		newResult("b.bru", "Tue, 02 Jan 2024 00:00:00 GMT", `{"id": 2}`),
+		newResult("c.bru", "Tue, 02 Jan 2024 00:00:00 GMT", `{}`),
+		newResult("new.bru", "Tue, 02 Jan 2024 00:00:00 GMT", `{}`),
+	}
+
+	diffs, err := cfg.DiffResponses(previous, current)
+	require.NoError(t, err)
+	require.Len(t, diffs, 2)
+	require.Equal(t, "a.bru", diffs[0].FilePath)
+	require.Equal(t, `--- previous
++++ current
+@@ -8,3 +8,3 @@
+     "id": 1,
+-    "name": "Ada",
++    "name": "Grace",
+     "role": "admin"
+`, diffs[0].Diff)
+	require.Equal(t, "c.bru", diffs[1].FilePath)
+	require.Contains(t, diffs[1].Diff, "-error: connection refused\n")
+}
@@ -0,0 +1,169 @@
+package brutui
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+const (
+	_environmentsDir    = "environments"
+	_folderFileName     = "folder.bru"
+	_collectionFileName = "collection.bru"
+)
+
+// _TreeNode is a folder or a request of the collection tree
+type _TreeNode struct {
+	name string
+	// Path of the folder or the Bru file
+	path string
+	// e.g. "GET", empty for folders
+	method   string
+	seq      int
+	depth    int
+	children []*_TreeNode
+	// Folders are collapsed until expanded
+	expanded bool
+}
+
+func (n *_TreeNode) isFolder() bool {
+	return n.method == ""
+}
+
+// readTree reads the folders and requests of the collection directory, sorted by seq and then path like brux run
+func readTree(dir string) (*_TreeNode, error) {
+	root, err := readFolder(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	// The name of "." is the one of the current directory
+	if absDir, err := filepath.Abs(dir); err == nil {
+		root.name = filepath.Base(absDir)
+	}
+	root.expanded = true
+	return root, nil
+}
+
+func readFolder(dir string, depth int) (*_TreeNode, error) {
+	folder := &_TreeNode{name: filepath.Base(dir), path: dir, depth: depth}
+	document, err := bruparser.ReadDocumentFile(filepath.Join(dir, _folderFileName))
+	if err != nil {
+		return nil, err
+	}
+	if document != nil {
+		meta, _ := document.Section("meta")
+		folder.name = cmp.Or(meta.Value("name"), folder.name)
+		folder.seq, _ = strconv.Atoi(meta.Value("seq"))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory '%s': %w", dir, err)
+	}
+	requests := make([]*_TreeNode, 0)
+	folders := make([]*_TreeNode, 0)
+	for _, entry := range entries {
+		entryPath := filepath.Join(dir, entry.Name())
+		switch {
+		case entry.IsDir() && !bruparser.IsIgnoredDir(entry.Name()):
+			subFolder, err := readFolder(entryPath, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			folders = append(folders, subFolder)
+		case !entry.IsDir() && filepath.Ext(entry.Name()) == ".bru" &&
+			entry.Name() != _folderFileName && entry.Name() != _collectionFileName:
+			request, err := readRequest(entryPath, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if request != nil {
+				requests = append(requests, request)
+			}
+		}
+	}
+
+	sortNodes(requests)
+	sortNodes(folders)
+	// Requests of a folder run before its sub-folders
+	folder.children = slices.Concat(requests, folders)
+	return folder, nil
+}
+
+// readRequest returns nil if the Bru file is not a request
+func readRequest(filePath string, depth int) (*_TreeNode, error) {
+	document, err := bruparser.ReadDocumentFile(filePath)
+	if err != nil || document == nil {
+		return nil, err
+	}
+	request, ok := document.Method()
+	if !ok {
+		return nil, nil
+	}
+
+	meta, _ := document.Section("meta")
+	seq, _ := strconv.Atoi(meta.Value("seq"))
+	return &_TreeNode{
+		name:   cmp.Or(meta.Value("name"), strings.TrimSuffix(filepath.Base(filePath), ".bru")),
+		path:   filePath,
+		method: strings.ToUpper(request.Name),
+		seq:    seq,
+		depth:  depth,
+	}, nil
+}
+
+// visibleNodes returns the children of the expanded folders in the order they are displayed
+func (n *_TreeNode) visibleNodes() []*_TreeNode {
+	nodes := make([]*_TreeNode, 0)
+	for _, child := range n.children {
+		nodes = append(nodes, child)
+		if child.isFolder() && child.expanded {
+			nodes = append(nodes, child.visibleNodes()...)
+		}
+	}
+	return nodes
+}
+
+// parent returns the folder containing the node, nil if it is a child of the root
+func (n *_TreeNode) parent(node *_TreeNode) *_TreeNode {
+	for _, child := range n.children {
+		if child == node {
+			return nil
+		}
+		if child.isFolder() {
+			if slices.Contains(child.children, node) {
+				return child
+			}
+			if parent := child.parent(node); parent != nil {
+				return parent
+			}
+		}
+	}
+	return nil
+}
+
+// readEnvironments returns the names of the environments of the collection, sorted
+func readEnvironments(dir string) ([]string, error) {
+	filePaths, err := filepath.Glob(filepath.Join(dir, _environmentsDir, "*.bru"))
+	if err != nil {
+		return nil, fmt.Errorf("could not list environments: %w", err)
+	}
+	names := make([]string, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		names = append(names, strings.TrimSuffix(filepath.Base(filePath), ".bru"))
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func sortNodes(nodes []*_TreeNode) {
+	slices.SortStableFunc(nodes, func(a, b *_TreeNode) int {
+		return cmp.Or(cmp.Compare(a.seq, b.seq), strings.Compare(a.path, b.path))
+	})
+}
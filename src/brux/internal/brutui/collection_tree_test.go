@@ -0,0 +1,60 @@
+package brutui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTree(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version": "1", "name": "API", "type": "collection"}`)
+	writeFile(t, filepath.Join(dir, "collection.bru"), "headers {\n  Accept: application/json\n}\n")
+	writeFile(t, filepath.Join(dir, "environments", "local.bru"), "vars {\n  baseUrl: http://localhost\n}\n")
+	writeRequest(t, filepath.Join(dir, "health.bru"), 2, "get", "{{baseUrl}}/health")
+	writeRequest(t, filepath.Join(dir, "login.bru"), 1, "post", "{{baseUrl}}/login")
+	writeFile(t, filepath.Join(dir, "users", "folder.bru"), "meta {\n  name: Users\n  seq: 2\n}\n")
+	writeRequest(t, filepath.Join(dir, "users", "get.bru"), 1, "get", "{{baseUrl}}/users/1")
+	writeRequest(t, filepath.Join(dir, "posts", "list.bru"), 1, "get", "{{baseUrl}}/posts")
+	writeFile(t, filepath.Join(dir, "posts", "notes.bru"), "docs {\n  Not a request\n}\n")
+
+	tree, err := readTree(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"POST login.bru", "GET health.bru", "posts", "Users"}, nodeNames(tree.visibleNodes()))
+
+	users := tree.visibleNodes()[3]
+	users.expanded = true
+	require.Equal(t, []string{"POST login.bru", "GET health.bru", "posts", "Users", "GET get.bru"}, nodeNames(tree.visibleNodes()))
+	require.Equal(t, users, tree.parent(tree.visibleNodes()[4]))
+	require.Nil(t, tree.parent(users))
+
+	environments, err := readEnvironments(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{"local"}, environments)
+}
+
+// nodeNames returns the names of the nodes, prefixed by the method for requests
+func nodeNames(nodes []*_TreeNode) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, strings.TrimSpace(node.method+" "+node.name))
+	}
+	return names
+}
+
+func writeRequest(t *testing.T, filePath string, seq int, method string, url string) {
+	t.Helper()
+	writeFile(t, filePath, fmt.Sprintf("meta {\n  name: %s\n  type: http\n  seq: %d\n}\n\n%s {\n  url: %s\n}\n",
+		filepath.Base(filePath), seq, method, url))
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
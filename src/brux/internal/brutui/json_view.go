@@ -0,0 +1,161 @@
+package brutui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var errTrailingData = errors.New("trailing data after JSON value")
+
+// _JSONNode is a value of a JSON body, displayed as lines whose objects and arrays can be folded.
+// Unlike a decoded map, it keeps the keys in the order of the body.
+type _JSONNode struct {
+	// Encoded key of the members of objects, e.g. `"name"`, empty otherwise
+	key string
+	// Encoded value of scalars, e.g. `"Ada"` or `42`
+	scalar string
+	// "{" or "[" for objects and arrays, empty for scalars
+	open     string
+	children []*_JSONNode
+	folded   bool
+}
+
+// _Line is a displayed line, along with the object or array it opens if any, which can be folded
+type _Line struct {
+	text string
+	node *_JSONNode
+}
+
+// parseJSON parses the JSON body, numbers are kept as they are
+func parseJSON(data []byte) (*_JSONNode, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := parseValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errTrailingData
+	}
+	return node, nil
+}
+
+func parseValue(decoder *json.Decoder) (*_JSONNode, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &_JSONNode{}
+	switch value := token.(type) {
+	case json.Delim:
+		node.open = value.String()
+		for decoder.More() {
+			key := ""
+			if node.open == "{" {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key = encodeString(fmt.Sprint(keyToken))
+			}
+			child, err := parseValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			child.key = key
+			node.children = append(node.children, child)
+		}
+		// The closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		node.scalar = encodeString(value)
+	case json.Number:
+		node.scalar = value.String()
+	case bool:
+		node.scalar = strconv.FormatBool(value)
+	case nil:
+		node.scalar = "null"
+	}
+	return node, nil
+}
+
+// lines returns the lines of the value indented by two spaces, with the folded objects and arrays on a single line
+func (n *_JSONNode) lines() []_Line {
+	lines := make([]_Line, 0)
+	n.render(&lines, 0, true)
+	return lines
+}
+
+func (n *_JSONNode) render(lines *[]_Line, depth int, last bool) {
+	indent := strings.Repeat("  ", depth)
+	prefix := indent
+	if n.key != "" {
+		prefix += n.key + ": "
+	}
+	comma := ","
+	if last {
+		comma = ""
+	}
+	if n.open == "" {
+		*lines = append(*lines, _Line{text: prefix + n.scalar + comma})
+		return
+	}
+
+	closing := "}"
+	if n.open == "[" {
+		closing = "]"
+	}
+	switch {
+	case len(n.children) == 0:
+		*lines = append(*lines, _Line{text: prefix + n.open + closing + comma})
+	case n.folded:
+		*lines = append(*lines, _Line{text: prefix + n.open + "… " + n.summary() + closing + comma, node: n})
+	default:
+		*lines = append(*lines, _Line{text: prefix + n.open, node: n})
+		for i, child := range n.children {
+			child.render(lines, depth+1, i == len(n.children)-1)
+		}
+		*lines = append(*lines, _Line{text: indent + closing + comma})
+	}
+}
+
+// summary returns the number of keys or items of a folded object or array
+func (n *_JSONNode) summary() string {
+	unit := "item"
+	if n.open == "{" {
+		unit = "key"
+	}
+	if len(n.children) != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", len(n.children), unit)
+}
+
+// setFolded folds or unfolds the objects and arrays nested in the value, and the value itself unless it is the root
+func (n *_JSONNode) setFolded(folded bool, isRoot bool) {
+	if n.open == "" {
+		return
+	}
+	n.folded = folded && !isRoot
+	for _, child := range n.children {
+		child.setFolded(folded, false)
+	}
+}
+
+// encodeString encodes the string as JSON without escaping HTML characters like "<"
+func encodeString(str string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// Strings can always be encoded
+	_ = encoder.Encode(str)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,56 @@
+package brutui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON(t *testing.T) {
+	t.Parallel()
+	node, err := parseJSON([]byte(`{"name": "<Ada>", "id": 1.50, "tags": ["a", "b"], "address": {"city": "London"}, "empty": {}, "ok": true, "none": null}`))
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		`{`,
+		`  "name": "<Ada>",`,
+		`  "id": 1.50,`,
+		`  "tags": [`,
+		`    "a",`,
+		`    "b"`,
+		`  ],`,
+		`  "address": {`,
+		`    "city": "London"`,
+		`  },`,
+		`  "empty": {},`,
+		`  "ok": true,`,
+		`  "none": null`,
+		`}`,
+	}, lineTexts(node.lines()))
+
+	node.setFolded(true, true)
+	require.Equal(t, []string{
+		`{`,
+		`  "name": "<Ada>",`,
+		`  "id": 1.50,`,
+		`  "tags": [… 2 items],`,
+		`  "address": {… 1 key},`,
+		`  "empty": {},`,
+		`  "ok": true,`,
+		`  "none": null`,
+		`}`,
+	}, lineTexts(node.lines()))
+	require.Equal(t, node.children[2], node.lines()[3].node)
+
+	_, err = parseJSON([]byte(`{"a": 1} {"b": 2}`))
+	require.ErrorIs(t, err, errTrailingData)
+	_, err = parseJSON([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func lineTexts(lines []_Line) []string {
+	texts := make([]string, 0, len(lines))
+	for _, line := range lines {
+		texts = append(texts, line.text)
+	}
+	return texts
+}
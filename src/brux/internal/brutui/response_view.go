@@ -0,0 +1,181 @@
+package brutui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+// _ResponseView displays a result, with a cursor to fold the objects and arrays of JSON bodies, and a search
+type _ResponseView struct {
+	// Status, headers and assertions
+	header []string
+	// nil if the body is not JSON
+	body *_JSONNode
+	// Lines of the body if it is not JSON
+	text   []string
+	cursor int
+	// First displayed line
+	offset  int
+	query   string
+	matches []int
+}
+
+func newResponseView(result *brurunner.Result) *_ResponseView {
+	view := &_ResponseView{header: make([]string, 0)}
+	view.header = append(view.header, result.Request.Method+" "+result.Request.URL)
+	if result.Response == nil {
+		if result.Err != nil {
+			view.header = append(view.header, "Error: "+result.Err.Error())
+		}
+		return view
+	}
+
+	response := result.Response
+	view.header = append(view.header, fmt.Sprintf("%s  %s", response.Status, result.Timings.Total.Round(time.Millisecond)))
+	headerNames := make([]string, 0, len(response.Headers))
+	for name := range response.Headers {
+		headerNames = append(headerNames, name)
+	}
+	slices.Sort(headerNames)
+	for _, name := range headerNames {
+		for _, value := range response.Headers[name] {
+			view.header = append(view.header, name+": "+value)
+		}
+	}
+	if len(result.Assertions) > 0 {
+		view.header = append(view.header, "")
+		for _, assertion := range result.Assertions {
+			mark := "✓"
+			if !assertion.Passed {
+				mark = "✗"
+			}
+			view.header = append(view.header, mark+" "+assertion.String())
+		}
+	}
+	if result.Err != nil {
+		view.header = append(view.header, "", "Error: "+result.Err.Error())
+	}
+	view.header = append(view.header, "")
+
+	if body, err := parseJSON(response.Body); err == nil {
+		view.body = body
+	} else {
+		view.text = strings.Split(strings.TrimSuffix(string(response.Body), "\n"), "\n")
+	}
+	return view
+}
+
+func (v *_ResponseView) lines() []_Line {
+	lines := make([]_Line, 0, len(v.header)+len(v.text))
+	for _, text := range v.header {
+		lines = append(lines, _Line{text: text})
+	}
+	if v.body != nil {
+		return append(lines, v.body.lines()...)
+	}
+	for _, text := range v.text {
+		lines = append(lines, _Line{text: text})
+	}
+	return lines
+}
+
+// toggleFold folds or unfolds the object or array at the cursor
+func (v *_ResponseView) toggleFold() {
+	lines := v.lines()
+	if v.cursor < len(lines) && lines[v.cursor].node != nil {
+		node := lines[v.cursor].node
+		node.folded = !node.folded
+		v.updateMatches()
+	}
+}
+
+// setFolded folds or unfolds all the objects and arrays of the body
+func (v *_ResponseView) setFolded(folded bool) {
+	if v.body == nil {
+		return
+	}
+	v.body.setFolded(folded, true)
+	v.cursor = min(v.cursor, len(v.lines())-1)
+	v.updateMatches()
+}
+
+// search unfolds the body, so that all the matches are displayed, and moves the cursor to the next match
+func (v *_ResponseView) search(query string) {
+	v.query = query
+	if v.body != nil && query != "" {
+		v.body.setFolded(false, true)
+	}
+	v.updateMatches()
+	for _, index := range v.matches {
+		if index >= v.cursor {
+			v.cursor = index
+			return
+		}
+	}
+	if len(v.matches) > 0 {
+		v.cursor = v.matches[0]
+	}
+}
+
+// nextMatch moves the cursor to the next match, or to the previous one if backward, wrapping around
+func (v *_ResponseView) nextMatch(backward bool) {
+	if len(v.matches) == 0 {
+		return
+	}
+	next := v.matches[0]
+	if backward {
+		next = v.matches[len(v.matches)-1]
+		for i := len(v.matches) - 1; i >= 0; i-- {
+			if v.matches[i] < v.cursor {
+				next = v.matches[i]
+				break
+			}
+		}
+	} else {
+		for _, match := range v.matches {
+			if match > v.cursor {
+				next = match
+				break
+			}
+		}
+	}
+	v.cursor = next
+}
+
+func (v *_ResponseView) updateMatches() {
+	v.matches = v.matches[:0]
+	if v.query == "" {
+		return
+	}
+	query := strings.ToLower(v.query)
+	for i, line := range v.lines() {
+		if strings.Contains(strings.ToLower(line.text), query) {
+			v.matches = append(v.matches, i)
+		}
+	}
+}
+
+// moveCursor moves the cursor by delta lines, staying within the lines
+func (v *_ResponseView) moveCursor(delta int) {
+	v.cursor = max(0, min(v.cursor+delta, len(v.lines())-1))
+}
+
+// visibleLines returns the lines displayed in height rows, scrolling so that the cursor is displayed
+func (v *_ResponseView) visibleLines(height int) []_Line {
+	lines := v.lines()
+	if height <= 0 {
+		return nil
+	}
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+height {
+		v.offset = v.cursor - height + 1
+	}
+	v.offset = max(0, min(v.offset, len(lines)-height))
+	return lines[v.offset:min(len(lines), v.offset+height)]
+}
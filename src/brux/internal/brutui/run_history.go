@@ -0,0 +1,41 @@
+package brutui
+
+import (
+	"cmp"
+	"fmt"
+	"time"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+// Older runs are dropped from the history
+const _maxHistory = 100
+
+// _HistoryEntry is a past run of a request
+type _HistoryEntry struct {
+	time    time.Time
+	method  string
+	name    string
+	envName string
+	result  *brurunner.Result
+}
+
+func (e _HistoryEntry) String() string {
+	status := "error"
+	if e.result.Response != nil {
+		status = e.result.Response.Status
+	}
+	return fmt.Sprintf("%s  %s  %s  %s  %s  [%s]",
+		e.time.Format(time.TimeOnly),
+		e.method,
+		e.name,
+		status,
+		e.result.Timings.Total.Round(time.Millisecond),
+		cmp.Or(e.envName, "no environment"))
+}
+
+// addToHistory returns the history with the entry first, dropping the oldest entries beyond _maxHistory
+func addToHistory(history []_HistoryEntry, entry _HistoryEntry) []_HistoryEntry {
+	history = append([]_HistoryEntry{entry}, history...)
+	return history[:min(len(history), _maxHistory)]
+}
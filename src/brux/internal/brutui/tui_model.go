@@ -0,0 +1,535 @@
+package brutui
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+type _Pane int
+
+const (
+	_paneTree _Pane = iota
+	_paneResponse
+)
+
+type _Overlay int
+
+const (
+	_overlayNone _Overlay = iota
+	_overlayEnvironments
+	_overlayHistory
+	_overlaySearch
+)
+
+const _noEnvironment = "No environment"
+
+var (
+	_titleStyle          = lipgloss.NewStyle().Bold(true).Reverse(true)
+	_helpStyle           = lipgloss.NewStyle().Faint(true)
+	_cursorStyle         = lipgloss.NewStyle().Reverse(true)
+	_inactiveCursorStyle = lipgloss.NewStyle().Bold(true)
+	_matchStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	_errorStyle          = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	_methodStyles        = map[string]lipgloss.Style{
+		"GET":    lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		"POST":   lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		"PUT":    lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
+		"PATCH":  lipgloss.NewStyle().Foreground(lipgloss.Color("4")),
+		"DELETE": lipgloss.NewStyle().Foreground(lipgloss.Color("1")),
+	}
+)
+
+// _RunFinishedMsg is sent when a request run by the UI finishes
+type _RunFinishedMsg struct {
+	entry _HistoryEntry
+}
+
+// _Model is the state of the terminal UI
+type _Model struct {
+	// Cancelled on exit to cancel the running request
+	ctx  context.Context
+	dir  string
+	opts []brurunner.Option
+	tree *_TreeNode
+	// Index of the selected node in the visible nodes of the tree
+	cursor       int
+	treeOffset   int
+	environments []string
+	envName      string
+	// nil if the config of the environment could not be created, see cfgErr
+	cfg    *brurunner.Config
+	cfgErr error
+	// The selected request with its variables replaced, nil if a folder is selected or it could not be resolved
+	resolved   *brurunner.ResolvedRequest
+	resolveErr error
+	// nil until a request is run or picked from the history
+	response *_ResponseView
+	// Newest first
+	history []_HistoryEntry
+	// Path of the running request, empty if none is running
+	running     string
+	focus       _Pane
+	overlay     _Overlay
+	listCursor  int
+	searchInput textinput.Model
+	width       int
+	height      int
+}
+
+// Run opens the terminal UI of the collection directory until the user quits.
+// The options configure how the requests are sent, like for brux run.
+func Run(ctx context.Context, dir string, envName string, opts ...brurunner.Option) error {
+	model, err := newModel(ctx, dir, envName, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(ctx)).Run()
+	if err != nil && !errors.Is(err, tea.ErrProgramKilled) {
+		return fmt.Errorf("could not run terminal UI: %w", err)
+	}
+	return nil
+}
+
+func newModel(ctx context.Context, dir string, envName string, opts ...brurunner.Option) (*_Model, error) {
+	tree, err := readTree(dir)
+	if err != nil {
+		return nil, err
+	}
+	environments, err := readEnvironments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	searchInput := textinput.New()
+	searchInput.Prompt = "/"
+	model := &_Model{
+		ctx:          ctx,
+		dir:          dir,
+		opts:         opts,
+		tree:         tree,
+		environments: environments,
+		searchInput:  searchInput,
+	}
+	model.setEnvironment(envName)
+	return model, nil
+}
+
+func (m *_Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m *_Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	case _RunFinishedMsg:
+		m.running = ""
+		m.history = addToHistory(m.history, msg.entry)
+		m.response = newResponseView(msg.entry.result)
+	case tea.KeyMsg:
+		return m, m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *_Model) handleKey(msg tea.KeyMsg) tea.Cmd {
+	if msg.String() == "ctrl+c" {
+		return tea.Quit
+	}
+	switch m.overlay {
+	case _overlaySearch:
+		return m.handleSearchKey(msg)
+	case _overlayEnvironments, _overlayHistory:
+		m.handleListKey(msg)
+		return nil
+	case _overlayNone:
+	}
+
+	switch msg.String() {
+	case "q":
+		return tea.Quit
+	case "tab":
+		if m.focus == _paneTree && m.response != nil {
+			m.focus = _paneResponse
+		} else {
+			m.focus = _paneTree
+		}
+		return nil
+	case "e":
+		m.overlay = _overlayEnvironments
+		m.listCursor = slices.Index(m.environments, m.envName) + 1
+		return nil
+	case "H":
+		if len(m.history) > 0 {
+			m.overlay = _overlayHistory
+			m.listCursor = 0
+		}
+		return nil
+	case "/":
+		if m.response != nil {
+			m.focus = _paneResponse
+			m.overlay = _overlaySearch
+			m.searchInput.SetValue("")
+			return m.searchInput.Focus()
+		}
+		return nil
+	}
+	if m.focus == _paneResponse {
+		m.handleResponseKey(msg)
+		return nil
+	}
+	return m.handleTreeKey(msg)
+}
+
+func (m *_Model) handleTreeKey(msg tea.KeyMsg) tea.Cmd {
+	node := m.selectedNode()
+	if node == nil {
+		return nil
+	}
+	nodes := m.tree.visibleNodes()
+	switch msg.String() {
+	case "up", "k":
+		m.selectNode(m.cursor - 1)
+	case "down", "j":
+		m.selectNode(m.cursor + 1)
+	case "home", "g":
+		m.selectNode(0)
+	case "end", "G":
+		m.selectNode(len(nodes) - 1)
+	case "right", "l":
+		if node.isFolder() {
+			node.expanded = true
+		}
+	case "left", "h":
+		if node.isFolder() && node.expanded {
+			node.expanded = false
+		} else if parent := m.tree.parent(node); parent != nil {
+			m.selectNode(slices.Index(nodes, parent))
+		}
+	case "enter", " ":
+		if node.isFolder() {
+			node.expanded = !node.expanded
+			return nil
+		}
+		return m.run(node)
+	case "r":
+		return m.run(node)
+	}
+	return nil
+}
+
+func (m *_Model) handleResponseKey(msg tea.KeyMsg) {
+	if m.response == nil {
+		return
+	}
+	switch msg.String() {
+	case "esc":
+		m.focus = _paneTree
+	case "up", "k":
+		m.response.moveCursor(-1)
+	case "down", "j":
+		m.response.moveCursor(1)
+	case "pgup":
+		m.response.moveCursor(-m.bodyHeight())
+	case "pgdown":
+		m.response.moveCursor(m.bodyHeight())
+	case "home", "g":
+		m.response.moveCursor(-len(m.response.lines()))
+	case "end", "G":
+		m.response.moveCursor(len(m.response.lines()))
+	case "enter", " ":
+		m.response.toggleFold()
+	case "z":
+		m.response.setFolded(true)
+	case "Z":
+		m.response.setFolded(false)
+	case "n":
+		m.response.nextMatch(false)
+	case "N":
+		m.response.nextMatch(true)
+	}
+}
+
+func (m *_Model) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "enter":
+		m.response.search(m.searchInput.Value())
+		fallthrough
+	case "esc":
+		m.overlay = _overlayNone
+		m.searchInput.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return cmd
+}
+
+func (m *_Model) handleListKey(msg tea.KeyMsg) {
+	count := len(m.history)
+	if m.overlay == _overlayEnvironments {
+		count = len(m.environments) + 1
+	}
+	switch msg.String() {
+	case "esc", "q":
+		m.overlay = _overlayNone
+	case "up", "k":
+		m.listCursor = max(0, m.listCursor-1)
+	case "down", "j":
+		m.listCursor = min(count-1, m.listCursor+1)
+	case "enter", " ":
+		if m.overlay == _overlayEnvironments {
+			envName := ""
+			if m.listCursor > 0 {
+				envName = m.environments[m.listCursor-1]
+			}
+			m.setEnvironment(envName)
+		} else {
+			m.response = newResponseView(m.history[m.listCursor].result)
+			m.focus = _paneResponse
+		}
+		m.overlay = _overlayNone
+	}
+}
+
+// setEnvironment creates the config of the environment, the cookies and the variables set by the requests are reset
+func (m *_Model) setEnvironment(envName string) {
+	m.envName = envName
+	cfg, err := brurunner.NewConfig(m.dir, false, "", envName, false, nil, m.opts...)
+	m.cfg = cfg
+	m.cfgErr = err
+	m.resolveSelected()
+}
+
+func (m *_Model) selectNode(index int) {
+	nodes := m.tree.visibleNodes()
+	m.cursor = max(0, min(index, len(nodes)-1))
+	m.resolveSelected()
+}
+
+func (m *_Model) selectedNode() *_TreeNode {
+	nodes := m.tree.visibleNodes()
+	if len(nodes) == 0 {
+		return nil
+	}
+	m.cursor = min(m.cursor, len(nodes)-1)
+	return nodes[m.cursor]
+}
+
+// resolveSelected replaces the variables of the selected request, for the detail pane
+func (m *_Model) resolveSelected() {
+	m.resolved = nil
+	m.resolveErr = nil
+	node := m.selectedNode()
+	if node == nil || node.isFolder() || m.cfg == nil {
+		return
+	}
+	m.resolved, m.resolveErr = brurunner.Resolve(m.cfg.ForFile(node.path))
+}
+
+// run returns the command running the request, nil if a request is already running
+func (m *_Model) run(node *_TreeNode) tea.Cmd {
+	if m.running != "" || m.cfg == nil {
+		return nil
+	}
+	m.running = node.path
+	ctx := m.ctx
+	cfg := m.cfg.ForFile(node.path)
+	envName := m.envName
+	return func() tea.Msg {
+		result := brurunner.Run(ctx, cfg)
+		return _RunFinishedMsg{entry: _HistoryEntry{
+			time:    time.Now(),
+			method:  node.method,
+			name:    node.name,
+			envName: envName,
+			result:  result,
+		}}
+	}
+}
+
+// bodyHeight returns the number of rows between the title and the help line
+func (m *_Model) bodyHeight() int {
+	return max(1, m.height-2)
+}
+
+func (m *_Model) View() string {
+	if m.width == 0 || m.height == 0 {
+		return ""
+	}
+
+	title := fmt.Sprintf(" brux  %s  env: %s", m.tree.name, cmp.Or(m.envName, "none"))
+	if m.running != "" {
+		title += "  running " + m.running + "…"
+	}
+
+	treeWidth := max(20, m.width/3)
+	rightWidth := max(1, m.width-treeWidth-1)
+	treeLines := m.treeView(treeWidth)
+	var rightLines []string
+	switch m.overlay {
+	case _overlayEnvironments:
+		rightLines = m.listView("Environments", slices.Concat([]string{_noEnvironment}, m.environments), rightWidth)
+	case _overlayHistory:
+		entries := make([]string, 0, len(m.history))
+		for _, entry := range m.history {
+			entries = append(entries, entry.String())
+		}
+		rightLines = m.listView("History", entries, rightWidth)
+	case _overlayNone, _overlaySearch:
+		rightLines = m.detailView(rightWidth)
+	}
+
+	rows := make([]string, 0, m.height)
+	rows = append(rows, _titleStyle.Render(fit(title, m.width)))
+	for i := range m.bodyHeight() {
+		row := fit(lineAt(treeLines, i), treeWidth) + "│" + lineAt(rightLines, i)
+		rows = append(rows, row)
+	}
+	if m.overlay == _overlaySearch {
+		rows = append(rows, m.searchInput.View())
+	} else {
+		rows = append(rows, _helpStyle.Render(fit(m.help(), m.width)))
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (m *_Model) treeView(width int) []string {
+	nodes := m.tree.visibleNodes()
+	height := m.bodyHeight()
+	if m.cursor < m.treeOffset {
+		m.treeOffset = m.cursor
+	}
+	if m.cursor >= m.treeOffset+height {
+		m.treeOffset = m.cursor - height + 1
+	}
+
+	lines := make([]string, 0, height)
+	for i := m.treeOffset; i < min(len(nodes), m.treeOffset+height); i++ {
+		node := nodes[i]
+		indent := strings.Repeat("  ", node.depth-1)
+		var line string
+		if node.isFolder() {
+			marker := "▸ "
+			if node.expanded {
+				marker = "▾ "
+			}
+			line = fit(indent+marker+node.name, width)
+		} else {
+			method := fmt.Sprintf("%-6s", node.method)
+			line = indent + _methodStyles[node.method].Render(method) + " " +
+				fit(node.name, max(0, width-ansi.StringWidth(indent)-len(method)-1))
+		}
+		if i == m.cursor {
+			line = m.cursorStyle(_paneTree).Render(ansi.Strip(line))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// detailView returns the resolved request, followed by the response
+func (m *_Model) detailView(width int) []string {
+	lines := make([]string, 0)
+	node := m.selectedNode()
+	switch {
+	case m.cfgErr != nil:
+		lines = append(lines, _errorStyle.Render(fit("Error: "+m.cfgErr.Error(), width)))
+	case node == nil:
+		lines = append(lines, fit("No requests in "+m.dir, width))
+	case node.isFolder():
+		lines = append(lines, fit(node.path, width))
+	case m.resolveErr != nil:
+		lines = append(lines, _errorStyle.Render(fit("Error: "+m.resolveErr.Error(), width)))
+	case m.resolved != nil:
+		lines = append(lines, _methodStyles[m.resolved.Method].Render(m.resolved.Method)+" "+
+			fit(m.resolved.URL, max(0, width-len(m.resolved.Method)-1)))
+		headerNames := make([]string, 0, len(m.resolved.Headers))
+		for name := range m.resolved.Headers {
+			headerNames = append(headerNames, name)
+		}
+		slices.Sort(headerNames)
+		for _, name := range headerNames {
+			lines = append(lines, fit(name+": "+strings.Join(m.resolved.Headers[name], ", "), width))
+		}
+	}
+	// The response is given at least two thirds of the pane
+	lines = lines[:min(len(lines), max(1, m.bodyHeight()/3))]
+	lines = append(lines, strings.Repeat("─", width))
+
+	if m.response == nil {
+		if node != nil && !node.isFolder() {
+			lines = append(lines, _helpStyle.Render(fit("Press enter to run the request", width)))
+		}
+		return lines
+	}
+	for i, line := range m.response.visibleLines(m.bodyHeight() - len(lines)) {
+		text := fit(line.text, width)
+		switch {
+		case m.response.offset+i == m.response.cursor:
+			text = m.cursorStyle(_paneResponse).Render(text)
+		case slices.Contains(m.response.matches, m.response.offset+i):
+			text = _matchStyle.Render(text)
+		}
+		lines = append(lines, text)
+	}
+	return lines
+}
+
+func (m *_Model) listView(title string, items []string, width int) []string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(fit(title, width))}
+	height := m.bodyHeight() - 1
+	offset := max(0, m.listCursor-height+1)
+	for i := offset; i < min(len(items), offset+height); i++ {
+		line := fit(items[i], width)
+		if i == m.listCursor {
+			line = _cursorStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (m *_Model) help() string {
+	switch {
+	case m.overlay == _overlayEnvironments || m.overlay == _overlayHistory:
+		return "↑/↓ move  enter select  esc close"
+	case m.focus == _paneResponse:
+		return "↑/↓ move  enter fold  z/Z fold/unfold all  / search  n/N next/previous match  esc back  q quit"
+	default:
+		return "↑/↓ move  ←/→ collapse/expand  enter run  tab response  e environment  H history  q quit"
+	}
+}
+
+func (m *_Model) cursorStyle(pane _Pane) lipgloss.Style {
+	if m.focus == pane {
+		return _cursorStyle
+	}
+	return _inactiveCursorStyle
+}
+
+// fit truncates or pads the text to the width
+func fit(text string, width int) string {
+	text = ansi.Truncate(text, width, "…")
+	return text + strings.Repeat(" ", max(0, width-ansi.StringWidth(text)))
+}
+
+func lineAt(lines []string, index int) string {
+	if index < len(lines) {
+		return lines[index]
+	}
+	return ""
+}
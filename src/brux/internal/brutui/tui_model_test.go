@@ -0,0 +1,98 @@
+package brutui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func TestModel(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "name": "Ada", "address": {"city": "London"}}`))
+	}))
+	t.Cleanup(server.Close)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "bruno.json"), `{"version": "1", "name": "API", "type": "collection"}`)
+	writeFile(t, filepath.Join(dir, "environments", "local.bru"), "vars {\n  baseUrl: "+server.URL+"\n}\n")
+	writeFile(t, filepath.Join(dir, "environments", "staging.bru"), "vars {\n  baseUrl: https://staging.example.com\n}\n")
+	writeRequest(t, filepath.Join(dir, "users", "get.bru"), 1, "get", "{{baseUrl}}/users/1")
+
+	model, err := newModel(t.Context(), dir, "local")
+	require.NoError(t, err)
+	update(t, model, tea.WindowSizeMsg{Width: 120, Height: 30})
+	require.Nil(t, model.resolved)
+
+	// Expands the folder and selects its request
+	update(t, model, key("enter"), key("j"))
+	require.NotNil(t, model.resolved)
+	require.Equal(t, server.URL+"/users/1", model.resolved.URL)
+	require.Contains(t, model.View(), "GET "+server.URL+"/users/1")
+
+	cmd := update(t, model, key("enter"))
+	require.Equal(t, filepath.Join(dir, "users", "get.bru"), model.running)
+	update(t, model, cmd())
+	require.Empty(t, model.running)
+	require.Len(t, model.history, 1)
+	require.Contains(t, model.View(), `"name": "Ada",`)
+
+	// Folds the body
+	update(t, model, key("tab"), key("z"))
+	require.Equal(t, _paneResponse, model.focus)
+	require.Contains(t, model.View(), `"address": {… 1 key}`)
+
+	// Searching unfolds the body
+	update(t, model, key("/"), key("c"), key("i"), key("t"), key("y"), key("enter"))
+	require.Equal(t, _overlayNone, model.overlay)
+	require.Len(t, model.response.matches, 1)
+	lines := model.response.lines()
+	require.Equal(t, `    "city": "London"`, lines[model.response.cursor].text)
+
+	// Picks another environment
+	update(t, model, key("esc"), key("e"), key("j"), key("enter"))
+	require.Equal(t, "staging", model.envName)
+	require.Equal(t, "https://staging.example.com/users/1", model.resolved.URL)
+	update(t, model, key("e"), key("k"), key("k"), key("enter"))
+	require.Empty(t, model.envName)
+
+	// Shows a past run
+	model.response = nil
+	update(t, model, key("H"))
+	require.Equal(t, _overlayHistory, model.overlay)
+	require.Contains(t, model.View(), "GET  get.bru  200 OK")
+	update(t, model, key("enter"))
+	require.NotNil(t, model.response)
+	require.Equal(t, _paneResponse, model.focus)
+
+	require.NotNil(t, update(t, model, key("q")))
+}
+
+// update sends the messages to the model, returning the command of the last one
+func update(t *testing.T, model *_Model, msgs ...tea.Msg) tea.Cmd {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, msg := range msgs {
+		_, cmd = model.Update(msg)
+	}
+	return cmd
+}
+
+func key(name string) tea.KeyMsg {
+	switch name {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
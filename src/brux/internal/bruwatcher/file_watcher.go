@@ -0,0 +1,208 @@
+package bruwatcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+// Editors save a file via several events, e.g. truncate and write, or write to a temporary file and rename it.
+// The events within this delay of each other are reported as a single change.
+const _debounceDelay = 100 * time.Millisecond
+
+// Files of collections whose changes are reported by BruFiles, along with the Bru files
+var _collectionFileNames = []string{".env", "bruno.json"}
+
+// ErrWatcherClosed is returned by Wait once the watcher is closed
+var ErrWatcherClosed = errors.New("watcher closed")
+
+// Filter returns true for the files of a watched directory whose changes are reported
+type Filter func(path string) bool
+
+// BruFiles reports the changes of the Bru files, ".env" and "bruno.json" files, and ignores the other files of
+// collections like snapshots, which are written by the runs themselves
+func BruFiles(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasSuffix(name, ".bru") || slices.Contains(_collectionFileNames, name)
+}
+
+// AllFiles reports the changes of all the files except hidden and backup files, e.g. the swap files of editors
+func AllFiles(path string) bool {
+	name := filepath.Base(path)
+	return !strings.HasPrefix(name, ".") && !strings.HasSuffix(name, "~")
+}
+
+// Watcher reports the changes of files, and of the files of directories and their sub-directories
+type Watcher struct {
+	watcher *fsnotify.Watcher
+	// Absolute paths
+	files []string
+	dirs  []_WatchedDir
+}
+
+type _WatchedDir struct {
+	// Absolute path
+	path   string
+	filter Filter
+}
+
+// NewWatcher creates a watcher of no files, which are added via Add
+func NewWatcher() (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("could not create watcher: %w", err)
+	}
+	return &Watcher{watcher: watcher}, nil
+}
+
+// Add watches the file, or the files of the directory and its sub-directories matching the filter.
+// Files are watched via their parent directory, so that the ones replaced by editors on save and the ones created
+// later, e.g. a ".env" file, are watched as well.
+func (w *Watcher) Add(path string, filter Filter) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("could not get absolute path of '%s': %w", path, err)
+	}
+	if stat, err := os.Stat(absPath); err == nil && stat.IsDir() {
+		w.dirs = append(w.dirs, _WatchedDir{path: absPath, filter: filter})
+		return w.addDir(absPath)
+	}
+	w.files = append(w.files, absPath)
+	return w.add(filepath.Dir(absPath))
+}
+
+// Wait blocks until a watched file changes and returns the paths of the changed files
+func (w *Watcher) Wait(ctx context.Context) ([]string, error) {
+	changedPaths := make([]string, 0)
+	// Disabled until the first change
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-debounce:
+			return changedPaths, nil
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return nil, ErrWatcherClosed
+			}
+			log.Warn().
+				Err(err).
+				Msg("file watcher error")
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return nil, ErrWatcherClosed
+			}
+			paths := w.handle(event)
+			if len(paths) == 0 {
+				continue
+			}
+			for _, path := range paths {
+				if !slices.Contains(changedPaths, path) {
+					changedPaths = append(changedPaths, path)
+				}
+			}
+			debounce = time.After(_debounceDelay)
+		}
+	}
+}
+
+// Close stops watching
+func (w *Watcher) Close() error {
+	return w.watcher.Close()
+}
+
+// handle watches the directories created in the watched directories, and returns the paths of the watched files
+// changed by the event
+func (w *Watcher) handle(event fsnotify.Event) []string {
+	log.Trace().
+		Str("event", event.String()).
+		Msg("file event")
+	if event.Op == fsnotify.Chmod {
+		return nil
+	}
+	if slices.Contains(w.files, event.Name) {
+		return []string{event.Name}
+	}
+	dir := w.watchedDir(event.Name)
+	if dir == nil {
+		return nil
+	}
+
+	if event.Has(fsnotify.Create) {
+		if stat, err := os.Stat(event.Name); err == nil && stat.IsDir() {
+			if strings.HasPrefix(stat.Name(), ".") {
+				return nil
+			}
+			if err := w.addDir(event.Name); err != nil {
+				log.Warn().
+					Err(err).
+					Str("dir", event.Name).
+					Msg("could not watch dir")
+			}
+			// The files created before the directory was watched, e.g. by "git checkout"
+			return findFiles(event.Name, dir.filter)
+		}
+	}
+	if !dir.filter(event.Name) {
+		return nil
+	}
+	return []string{event.Name}
+}
+
+// watchedDir returns the watched directory containing the path, nil if there is none
+func (w *Watcher) watchedDir(path string) *_WatchedDir {
+	for i, dir := range w.dirs {
+		if strings.HasPrefix(path, dir.path+string(filepath.Separator)) {
+			return &w.dirs[i]
+		}
+	}
+	return nil
+}
+
+// addDir watches the directory and its sub-directories, except hidden ones like ".git"
+func (w *Watcher) addDir(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return w.add(path)
+	})
+}
+
+// findFiles returns the files of the directory and its sub-directories matching the filter
+func findFiles(dir string, filter Filter) []string {
+	paths := make([]string, 0)
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() && filter(path) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths
+}
+
+func (w *Watcher) add(dir string) error {
+	if err := w.watcher.Add(dir); err != nil {
+		return fmt.Errorf("could not watch '%s': %w", dir, err)
+	}
+	log.Debug().
+		Str("dir", dir).
+		Msg("watching dir")
+	return nil
+}
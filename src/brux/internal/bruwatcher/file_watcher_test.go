@@ -0,0 +1,107 @@
+package bruwatcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/logger"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+	logger.ConfigureLogging()
+	collectionDir := t.TempDir()
+	sourceDir := t.TempDir()
+	envFilePath := filepath.Join(t.TempDir(), ".env")
+	// Created later
+	folderFilePath := filepath.Join(t.TempDir(), "folder.bru")
+	writeFile(t, filepath.Join(collectionDir, "users", "get.bru"), "get {\n}\n")
+	writeFile(t, envFilePath, "TOKEN=a\n")
+
+	watcher, err := NewWatcher()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = watcher.Close()
+	})
+	require.NoError(t, watcher.Add(collectionDir, BruFiles))
+	require.NoError(t, watcher.Add(sourceDir, AllFiles))
+	require.NoError(t, watcher.Add(envFilePath, BruFiles))
+	require.NoError(t, watcher.Add(folderFilePath, BruFiles))
+
+	testCases := []struct {
+		name     string
+		write    []string
+		expected []string
+	}{
+		{
+			name:     "bru file",
+			write:    []string{filepath.Join(collectionDir, "users", "get.bru")},
+			expected: []string{filepath.Join(collectionDir, "users", "get.bru")},
+		},
+		{
+			name:     "new dir",
+			write:    []string{filepath.Join(collectionDir, "posts", "list.bru")},
+			expected: []string{filepath.Join(collectionDir, "posts", "list.bru")},
+		},
+		{
+			name: "ignored files",
+			write: []string{
+				filepath.Join(collectionDir, "users", "get.snap.json"),
+				filepath.Join(sourceDir, ".main.go.swp"),
+				filepath.Join(filepath.Dir(envFilePath), "other"),
+				filepath.Join(sourceDir, "main.go"),
+			},
+			expected: []string{filepath.Join(sourceDir, "main.go")},
+		},
+		{
+			name:     "file",
+			write:    []string{envFilePath},
+			expected: []string{envFilePath},
+		},
+		{
+			name: "new file",
+			// The other Bru files of the directory of a file are not watched
+			write:    []string{filepath.Join(filepath.Dir(folderFilePath), "get.bru"), folderFilePath},
+			expected: []string{folderFilePath},
+		},
+	}
+	// Not parallel, the changes of each test case are waited for in turn
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for _, filePath := range testCase.write {
+				writeFile(t, filePath, "changed\n")
+			}
+			ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+			defer cancel()
+			changedPaths, err := watcher.Wait(ctx)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expected, changedPaths)
+		})
+	}
+}
+
+func TestWatcher_Cancelled(t *testing.T) {
+	t.Parallel()
+	watcher, err := NewWatcher()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = watcher.Close()
+	})
+	require.NoError(t, watcher.Add(t.TempDir(), BruFiles))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+	_, err = watcher.Wait(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
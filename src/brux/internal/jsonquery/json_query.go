@@ -0,0 +1,283 @@
+package jsonquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPath = errors.New("invalid path")
+	ErrNotFound    = errors.New("path not found")
+)
+
+// Get returns the value at path in data, a decoded JSON document.
+// The path is a dot separated list of keys with optional array indices, optionally starting with "$" as in JSONPath
+// or "." as in jq.
+// Example: "items[0].id", "$.items[0].id", ".items[0].id" or `headers["content-type"]`
+// If the path contains wildcards like "items[*].id" or ".items[].id", or recursive descents like "$..id", it returns
+// the matching values as an array, which is empty if there are none.
+func Get(data any, path string) (any, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := query(data, segments)
+	if !isDefinite(segments) {
+		return values, nil
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: '%s'", ErrNotFound, path)
+	}
+	return values[0], nil
+}
+
+// Validate returns an error if the path is invalid
+func Validate(path string) error {
+	_, err := parsePath(path)
+	return err
+}
+
+// Key returns the key of a path of a single key, e.g. "content-type" of ".content-type" or `["content-type"]`, and
+// "" for the root
+func Key(path string) (string, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case len(segments) == 0:
+		return "", nil
+	case len(segments) > 1 || segments[0].isIndex || segments[0].isWildcard || segments[0].isRecursive:
+		return "", fmt.Errorf("%w: not a single key: '%s'", ErrInvalidPath, path)
+	default:
+		return segments[0].key, nil
+	}
+}
+
+func query(current any, segments []_Segment) []any {
+	if len(segments) == 0 {
+		return []any{current}
+	}
+
+	segment, rest := segments[0], segments[1:]
+	values := make([]any, 0)
+	switch {
+	case segment.isRecursive:
+		values = append(values, query(current, rest)...)
+		for _, child := range children(current) {
+			values = append(values, query(child, segments)...)
+		}
+	case segment.isWildcard:
+		for _, child := range children(current) {
+			values = append(values, query(child, rest)...)
+		}
+	default:
+		if next, ok := segment.apply(current); ok {
+			values = append(values, query(next, rest)...)
+		}
+	}
+	return values
+}
+
+// children returns the values of an object sorted by key, or the items of an array
+func children(value any) []any {
+	switch container := value.(type) {
+	case map[string]any:
+		keys := slices.Sorted(maps.Keys(container))
+		values := make([]any, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, container[key])
+		}
+		return values
+	case []any:
+		return container
+	default:
+		return nil
+	}
+}
+
+func isDefinite(segments []_Segment) bool {
+	return !slices.ContainsFunc(segments, func(segment _Segment) bool {
+		return segment.isWildcard || segment.isRecursive
+	})
+}
+
+// Replace replaces the values at path in data, a decoded JSON document, with value and returns how many were replaced.
+// Example: "$.items[*].id", "$.*.createdAt" or "$..password"
+func Replace(data any, path string, value any) (int, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(segments) == 0 {
+		return 0, fmt.Errorf("%w: the root can not be replaced: '%s'", ErrInvalidPath, path)
+	}
+	return replace(data, segments, value), nil
+}
+
+func replace(current any, segments []_Segment, value any) int {
+	segment, rest := segments[0], segments[1:]
+	count := 0
+	set := func(next any, setValue func()) {
+		if len(rest) == 0 {
+			setValue()
+			count++
+		} else {
+			count += replace(next, rest, value)
+		}
+	}
+
+	if segment.isRecursive {
+		count += replace(current, rest, value)
+		for _, child := range children(current) {
+			count += replace(child, segments, value)
+		}
+		return count
+	}
+
+	switch container := current.(type) {
+	case map[string]any:
+		for key, next := range container {
+			if segment.isWildcard || (!segment.isIndex && key == segment.key) {
+				set(next, func() { container[key] = value })
+			}
+		}
+	case []any:
+		for i, next := range container {
+			if segment.isWildcard || (segment.isIndex && (i == segment.index || i == segment.index+len(container))) {
+				set(next, func() { container[i] = value })
+			}
+		}
+	}
+	return count
+}
+
+// Decode decodes a JSON document preserving numbers as json.Number
+func Decode(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("could not decode JSON: %w", err)
+	}
+	return value, nil
+}
+
+// _Segment is a single step of a path, either a key, an array index, a wildcard or a recursive descent
+type _Segment struct {
+	key     string
+	index   int
+	isIndex bool
+	// Matches every key of an object or item of an array, e.g. "*" in "items[*].id" or "[]" in ".items[].id"
+	isWildcard bool
+	// Matches the value and all its descendants, e.g. ".." in "$..id"
+	isRecursive bool
+}
+
+func (s _Segment) apply(value any) (any, bool) {
+	if s.isIndex {
+		array, ok := value.([]any)
+		if !ok {
+			return nil, false
+		}
+		index := s.index
+		if index < 0 {
+			index += len(array)
+		}
+		if index < 0 || index >= len(array) {
+			return nil, false
+		}
+		return array[index], true
+	}
+
+	object, ok := value.(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	next, ok := object[s.key]
+	return next, ok
+}
+
+func parsePath(path string) ([]_Segment, error) {
+	segments := make([]_Segment, 0)
+	path = strings.TrimSpace(path)
+	// The root of JSONPath, e.g. "$.items[0]"
+	if path == "$" || strings.HasPrefix(path, "$.") || strings.HasPrefix(path, "$[") {
+		path = path[1:]
+	}
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			if strings.HasPrefix(path[i:], "..") {
+				segments = append(segments, _Segment{isRecursive: true})
+				i += 2
+			} else {
+				i++
+			}
+		case '|', ' ':
+			// Pipes of jq paths, e.g. ".items[] | .id" is the same as ".items[].id", other jq syntax like
+			// ".items | length" is not supported
+			end := i + len(path[i:]) - len(strings.TrimLeft(path[i:], "| "))
+			if strings.Count(path[i:end], "|") != 1 || end == len(path) || path[end] != '.' {
+				return nil, fmt.Errorf("%w: unsupported '%s' in '%s'", ErrInvalidPath, unsupportedToken(path, i, end), path)
+			}
+			i = end
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated '[' in '%s'", ErrInvalidPath, path)
+			}
+			segment, err := parseBracket(path[i+1 : i+end])
+			if err != nil {
+				return nil, fmt.Errorf("%w: '%s'", err, path)
+			}
+			segments = append(segments, segment)
+			i += end + 1
+		default:
+			end := strings.IndexAny(path[i:], ".[| ")
+			if end < 0 {
+				end = len(path) - i
+			}
+			key := path[i : i+end]
+			segments = append(segments, _Segment{key: key, isWildcard: key == "*"})
+			i += end
+		}
+	}
+	return segments, nil
+}
+
+// unsupportedToken returns the token of the path following the separators from start to end, e.g. "length" of
+// ".items | length", or the separators themselves if they are the unsupported part, e.g. "||" or a trailing "|"
+func unsupportedToken(path string, start int, end int) string {
+	separators := strings.TrimSpace(path[start:end])
+	if strings.Count(separators, "|") > 1 || end == len(path) {
+		return separators
+	}
+	tokenEnd := strings.IndexAny(path[end:], "| ")
+	if tokenEnd < 0 {
+		tokenEnd = len(path) - end
+	}
+	return path[end : end+tokenEnd]
+}
+
+func parseBracket(str string) (_Segment, error) {
+	str = strings.TrimSpace(str)
+	if len(str) >= 2 && (str[0] == '"' || str[0] == '\'') && str[len(str)-1] == str[0] {
+		return _Segment{key: str[1 : len(str)-1]}, nil
+	}
+	if str == "*" || str == "" {
+		return _Segment{isWildcard: true}, nil
+	}
+	index, err := strconv.Atoi(str)
+	if err != nil {
+		return _Segment{}, fmt.Errorf("%w: invalid index '%s'", ErrInvalidPath, str)
+	}
+	return _Segment{index: index, isIndex: true}, nil
+}
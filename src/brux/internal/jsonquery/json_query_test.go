@@ -0,0 +1,94 @@
+package jsonquery
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testDocument = `{
+  "items": [
+    {"id": 1, "name": "foo", "tags": [{"id": 10}]},
+    {"id": 2, "name": "bar"}
+  ],
+  "total": 2,
+  "content-type": "json"
+}`
+
+func TestGet(t *testing.T) {
+	t.Parallel()
+	data, err := Decode([]byte(_testDocument))
+	require.NoError(t, err)
+
+	testCases := map[string]string{
+		"":                      _testDocument,
+		"$":                     _testDocument,
+		".":                     _testDocument,
+		"total":                 `2`,
+		"items[0].id":           `1`,
+		"$.items[-1].name":      `"bar"`,
+		".items[1].name":        `"bar"`,
+		`["content-type"]`:      `"json"`,
+		`$['content-type']`:     `"json"`,
+		"items[*].id":           `[1, 2]`,
+		"$.items[*].name":       `["foo", "bar"]`,
+		".items[].id":           `[1, 2]`,
+		".items[] | .name":      `["foo", "bar"]`,
+		".items | .[0] | .name": `"foo"`,
+		"$..id":                 `[1, 10, 2]`,
+		"$.items[*].missing":    `[]`,
+	}
+	for path, expected := range testCases {
+		value, err := Get(data, path)
+		require.NoError(t, err, path)
+		actual, err := json.Marshal(value)
+		require.NoError(t, err, path)
+		require.JSONEq(t, expected, string(actual), path)
+	}
+
+	_, err = Get(data, "items[2].id")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = Get(data, "items[x]")
+	require.ErrorIs(t, err, ErrInvalidPath)
+	require.ErrorIs(t, Validate("items[0"), ErrInvalidPath)
+
+	// jq syntax other than pipes between paths is rejected, naming the unsupported part
+	for path, token := range map[string]string{
+		".items | length":       "'length'",
+		".items[] | select(.x)": "'select(.x)'",
+		".items .id":            "'.id'",
+		".items |":              "'|'",
+		".items || .id":         "'||'",
+	} {
+		err := Validate(path)
+		require.ErrorIs(t, err, ErrInvalidPath, path)
+		require.ErrorContains(t, err, "unsupported "+token, path)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	t.Parallel()
+	data, err := Decode([]byte(_testDocument))
+	require.NoError(t, err)
+
+	count, err := Replace(data, "$..id", "x")
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	count, err = Replace(data, "items[*].name", nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	_, err = Replace(data, "$", nil)
+	require.ErrorIs(t, err, ErrInvalidPath)
+
+	actual, err := json.Marshal(data)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+  "items": [
+    {"id": "x", "name": null, "tags": [{"id": "x"}]},
+    {"id": "x", "name": null}
+  ],
+  "total": 2,
+  "content-type": "json"
+}`, string(actual))
+}
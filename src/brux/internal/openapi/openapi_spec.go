@@ -0,0 +1,300 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidSpec = errors.New("invalid OpenAPI spec")
+
+// Depth of references to references that are followed, to stop at reference cycles
+const _maxRefDepth = 8
+
+// Server URLs like "https://{region}.example.com/v1", of which only the path is kept
+var _serverOriginRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]*`)
+
+// Spec is an OpenAPI 3 or Swagger 2 spec, both as typed fields and as the JSON document for JSON pointers and schemas.
+// Ref: https://spec.openapis.org/oas/v3.1.0 and https://swagger.io/specification/v2/
+type Spec struct {
+	OpenAPI    string                `json:"openapi"`
+	Swagger    string                `json:"swagger"`
+	Info       Info                  `json:"info"`
+	Servers    []Server              `json:"servers"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security"`
+
+	// Swagger 2
+	Host                string                     `json:"host"`
+	BasePath            string                     `json:"basePath"`
+	Schemes             []string                   `json:"schemes"`
+	Consumes            []string                   `json:"consumes"`
+	Definitions         map[string]*Schema         `json:"definitions"`
+	Parameters          map[string]*Parameter      `json:"parameters"`
+	SecurityDefinitions map[string]*SecurityScheme `json:"securityDefinitions"`
+
+	// The spec as decoded from JSON, numbers are json.Number
+	doc map[string]any
+}
+
+// PathOperation is an operation of the spec along with its path, method and JSON pointer
+type PathOperation struct {
+	// As in the spec, e.g. "/users/{id}"
+	Path string
+	// Lower case, e.g. "get"
+	Method string
+	// JSON pointer of the operation, e.g. "/paths/~1users~1{id}/get"
+	Pointer   string
+	PathItem  *PathItem
+	Operation *Operation
+}
+
+// Parse parses a spec in YAML or JSON
+func Parse(data []byte) (*Spec, error) {
+	var value any
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	// Round trip via JSON, so that the typed fields and the document are decoded the same way
+	data, err := json.Marshal(toJSONValue(value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+
+	spec := &Spec{}
+	if err := decodeJSON(data, spec); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSpec, err)
+	}
+	if err := decodeJSON(data, &spec.doc); err != nil || spec.doc == nil {
+		return nil, fmt.Errorf("%w: not an object", ErrInvalidSpec)
+	}
+	if spec.OpenAPI == "" && spec.Swagger == "" {
+		return nil, fmt.Errorf("%w: neither 'openapi' nor 'swagger' version found", ErrInvalidSpec)
+	}
+	return spec, nil
+}
+
+func (s *Spec) IsSwagger2() bool {
+	return s.Swagger != ""
+}
+
+// Doc returns the spec as decoded from JSON
+func (s *Spec) Doc() map[string]any {
+	return s.doc
+}
+
+// SecuritySchemes returns the security schemes by name, of the components or of the Swagger 2 definitions
+func (s *Spec) SecuritySchemes() map[string]*SecurityScheme {
+	if s.IsSwagger2() {
+		return s.SecurityDefinitions
+	}
+	return s.Components.SecuritySchemes
+}
+
+// BasePaths returns the paths of the servers, e.g. "/v1" of "https://example.com/v1", "" if there are none
+func (s *Spec) BasePaths() []string {
+	if s.IsSwagger2() {
+		return []string{s.BasePath}
+	}
+
+	basePaths := make([]string, 0)
+	for _, server := range s.Servers {
+		basePath := _serverOriginRegex.ReplaceAllString(server.URL, "")
+		if !slices.Contains(basePaths, basePath) {
+			basePaths = append(basePaths, basePath)
+		}
+	}
+	if len(basePaths) == 0 {
+		basePaths = append(basePaths, "")
+	}
+	return basePaths
+}
+
+// PathOperations returns the operations of the spec, sorted by path and then in the order of PathItem.Operations
+func (s *Spec) PathOperations() []PathOperation {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	operations := make([]PathOperation, 0)
+	for _, path := range paths {
+		pointer := "/paths/" + EscapePointer(path)
+		pathItem := s.Paths[path]
+		if pathItem != nil && pathItem.Ref != "" {
+			pointer = refPointer(pathItem.Ref)
+			pathItem = s.ResolvePathItem(pathItem)
+		}
+		if pathItem == nil {
+			continue
+		}
+		for _, methodOperation := range pathItem.Operations() {
+			operations = append(operations, PathOperation{
+				Path:      path,
+				Method:    methodOperation.Method,
+				Pointer:   pointer + "/" + methodOperation.Method,
+				PathItem:  pathItem,
+				Operation: methodOperation.Operation,
+			})
+		}
+	}
+	return operations
+}
+
+// ResolveSchema follows the references of the schema, nil if a reference can't be resolved
+func (s *Spec) ResolveSchema(schema *Schema) *Schema {
+	for i := 0; schema != nil && schema.Ref != "" && i < _maxRefDepth; i++ {
+		schema = resolveRef[Schema](s, schema.Ref)
+	}
+	return schema
+}
+
+// SchemaRef returns the schema of a reference like "#/components/schemas/User", which may itself be a reference
+func (s *Spec) SchemaRef(ref string) *Schema {
+	return resolveRef[Schema](s, ref)
+}
+
+// ResolveParameter follows the reference of the parameter, e.g. to "#/components/parameters/Limit"
+func (s *Spec) ResolveParameter(parameter *Parameter) *Parameter {
+	if parameter == nil || parameter.Ref == "" {
+		return parameter
+	}
+	return resolveRef[Parameter](s, parameter.Ref)
+}
+
+// ResolveRequestBody follows the reference of the request body, e.g. to "#/components/requestBodies/User"
+func (s *Spec) ResolveRequestBody(requestBody *RequestBody) *RequestBody {
+	if requestBody == nil || requestBody.Ref == "" {
+		return requestBody
+	}
+	return resolveRef[RequestBody](s, requestBody.Ref)
+}
+
+// ResolvePathItem follows the reference of the path item
+func (s *Spec) ResolvePathItem(pathItem *PathItem) *PathItem {
+	if pathItem == nil || pathItem.Ref == "" {
+		return pathItem
+	}
+	return resolveRef[PathItem](s, pathItem.Ref)
+}
+
+// Resolve returns the object of the document, following a local "$ref" like "#/components/responses/NotFound", and
+// the JSON pointer of the reference, empty if there is no reference
+func (s *Spec) Resolve(value any) (map[string]any, string) {
+	pointer := ""
+	for range _maxRefDepth {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, ""
+		}
+		ref, ok := object["$ref"].(string)
+		if !ok || !isLocalRef(ref) {
+			return object, pointer
+		}
+		pointer = refPointer(ref)
+		value = s.Lookup(pointer)
+	}
+	return nil, ""
+}
+
+// Lookup returns the value of the document at the JSON pointer, nil if there is none
+func (s *Spec) Lookup(pointer string) any {
+	var value any = s.doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[UnescapePointer(token)]
+	}
+	return value
+}
+
+// resolveRef decodes the value of the document at the local reference, nil if there is none
+func resolveRef[T any](s *Spec, ref string) *T {
+	if !isLocalRef(ref) {
+		log.Warn().
+			Str("ref", ref).
+			Msg("only local references are supported, ignoring it")
+		return nil
+	}
+	value := s.Lookup(refPointer(ref))
+	if value == nil {
+		log.Warn().
+			Str("ref", ref).
+			Msg("reference not found, ignoring it")
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	resolved := new(T)
+	if err := decodeJSON(data, resolved); err != nil {
+		log.Warn().
+			Err(err).
+			Str("ref", ref).
+			Msg("invalid reference, ignoring it")
+		return nil
+	}
+	return resolved
+}
+
+func isLocalRef(ref string) bool {
+	return strings.HasPrefix(ref, "#/")
+}
+
+// refPointer returns the JSON pointer of a local reference, e.g. "/components/schemas/User"
+func refPointer(ref string) string {
+	return strings.TrimPrefix(ref, "#")
+}
+
+func decodeJSON(data []byte, value any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Keeps large integers of examples as they are
+	decoder.UseNumber()
+	return decoder.Decode(value)
+}
+
+// toJSONValue converts the mappings with non-string keys decoded from YAML, e.g. "200:" of the responses, to maps
+// with string keys
+func toJSONValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			v[key] = toJSONValue(child)
+		}
+		return v
+	case map[any]any:
+		converted := make(map[string]any, len(v))
+		for key, child := range v {
+			converted[fmt.Sprint(key)] = toJSONValue(child)
+		}
+		return converted
+	case []any:
+		for i, child := range v {
+			v[i] = toJSONValue(child)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// EscapePointer escapes a token of a JSON pointer, e.g. "/users" becomes "~1users"
+func EscapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func UnescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
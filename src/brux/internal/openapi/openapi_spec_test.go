@@ -0,0 +1,112 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testSpec = `openapi: 3.1.0
+servers:
+  - url: https://api.example.com/v1
+  - url: http://localhost:8080/v1
+paths:
+  /users/{id}:
+    $ref: "#/components/pathItems/User"
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        $ref: "#/components/requestBodies/User"
+    get:
+      parameters:
+        - $ref: "#/components/parameters/Limit"
+      responses:
+        200:
+          description: OK
+components:
+  pathItems:
+    User:
+      get:
+        operationId: getUser
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      example: 12345678901234567890
+  requestBodies:
+    User:
+      content:
+        application/json:
+          schema:
+            $ref: "#/components/schemas/User"
+  schemas:
+    User:
+      $ref: "#/components/schemas/Person"
+    Person:
+      type: [object, "null"]
+      properties:
+        name:
+          type: string
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	spec, err := Parse([]byte(_testSpec))
+	require.NoError(t, err)
+	require.False(t, spec.IsSwagger2())
+	require.Equal(t, []string{"/v1"}, spec.BasePaths())
+
+	operations := spec.PathOperations()
+	require.Len(t, operations, 3)
+	require.Equal(t, "/users", operations[0].Path)
+	require.Equal(t, "get", operations[0].Method)
+	require.Equal(t, "/paths/~1users/get", operations[0].Pointer)
+	require.Equal(t, "post", operations[1].Method)
+	require.Equal(t, "/users/{id}", operations[2].Path)
+	require.Equal(t, "getUser", operations[2].Operation.OperationID)
+	require.Equal(t, "/components/pathItems/User/get", operations[2].Pointer)
+
+	parameter := spec.ResolveParameter(operations[0].Operation.Parameters[0])
+	require.Equal(t, "limit", parameter.Name)
+	require.Equal(t, json.Number("12345678901234567890"), parameter.Example)
+
+	requestBody := spec.ResolveRequestBody(operations[1].Operation.RequestBody)
+	schema := spec.ResolveSchema(requestBody.Content["application/json"].Schema)
+	require.Equal(t, SchemaType("object"), schema.Type)
+	require.Contains(t, schema.Properties, "name")
+
+	// The responses with integer status codes in YAML have string keys in the document
+	response, pointer := spec.Resolve(spec.Lookup("/paths/~1users/get/responses/200"))
+	require.Empty(t, pointer)
+	require.Equal(t, "OK", response["description"])
+	require.Nil(t, spec.SchemaRef("#/components/schemas/Missing"))
+	require.Nil(t, spec.SchemaRef("other.yaml#/User"))
+}
+
+func TestParse_Swagger2(t *testing.T) {
+	t.Parallel()
+	spec, err := Parse([]byte(`{
+  "swagger": "2.0",
+  "basePath": "/v2",
+  "securityDefinitions": {"basic": {"type": "basic"}},
+  "parameters": {"Id": {"name": "id", "in": "path", "required": true, "type": "string"}},
+  "paths": {"/pets/{id}": {"delete": {"parameters": [{"$ref": "#/parameters/Id"}]}}}
+}`))
+	require.NoError(t, err)
+	require.True(t, spec.IsSwagger2())
+	require.Equal(t, []string{"/v2"}, spec.BasePaths())
+	require.Equal(t, "basic", spec.SecuritySchemes()["basic"].Type)
+	operations := spec.PathOperations()
+	require.Len(t, operations, 1)
+	require.Equal(t, "id", spec.ResolveParameter(operations[0].Operation.Parameters[0]).Name)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+	for _, data := range []string{"info:\n  title: API\n", "- a\n- b\n", "openapi: [3\n"} {
+		_, err := Parse([]byte(data))
+		require.ErrorIs(t, err, ErrInvalidSpec, data)
+	}
+}
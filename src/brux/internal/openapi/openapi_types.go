@@ -0,0 +1,147 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Info struct {
+	Title string `json:"title"`
+}
+
+type Server struct {
+	URL         string                    `json:"url"`
+	Description string                    `json:"description"`
+	Variables   map[string]ServerVariable `json:"variables"`
+}
+
+type ServerVariable struct {
+	Default string `json:"default"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	Parameters      map[string]*Parameter      `json:"parameters"`
+	RequestBodies   map[string]*RequestBody    `json:"requestBodies"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type PathItem struct {
+	Ref        string       `json:"$ref"`
+	Parameters []*Parameter `json:"parameters"`
+	Get        *Operation   `json:"get"`
+	Put        *Operation   `json:"put"`
+	Post       *Operation   `json:"post"`
+	Delete     *Operation   `json:"delete"`
+	Options    *Operation   `json:"options"`
+	Head       *Operation   `json:"head"`
+	Patch      *Operation   `json:"patch"`
+	Trace      *Operation   `json:"trace"`
+}
+
+// MethodOperation is an operation of a path with its method, e.g. "get"
+type MethodOperation struct {
+	Method    string
+	Operation *Operation
+}
+
+// Operations returns the operations of the path in a fixed order
+func (p PathItem) Operations() []MethodOperation {
+	operations := make([]MethodOperation, 0)
+	for _, operation := range []MethodOperation{
+		{"get", p.Get}, {"post", p.Post}, {"put", p.Put}, {"patch", p.Patch}, {"delete", p.Delete},
+		{"head", p.Head}, {"options", p.Options}, {"trace", p.Trace},
+	} {
+		if operation.Operation != nil {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Tags        []string              `json:"tags"`
+	Parameters  []*Parameter          `json:"parameters"`
+	RequestBody *RequestBody          `json:"requestBody"`
+	Security    []map[string][]string `json:"security"`
+	// Swagger 2
+	Consumes []string `json:"consumes"`
+}
+
+type Parameter struct {
+	Ref      string  `json:"$ref"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Example  any     `json:"example"`
+	Schema   *Schema `json:"schema"`
+	// Swagger 2 parameters have the schema fields inline, except for "body" parameters
+	Type    string `json:"type"`
+	Default any    `json:"default"`
+	Enum    []any  `json:"enum"`
+}
+
+type RequestBody struct {
+	Ref     string                `json:"$ref"`
+	Content map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema   *Schema             `json:"schema"`
+	Example  any                 `json:"example"`
+	Examples map[string]*Example `json:"examples"`
+}
+
+type Example struct {
+	Value any `json:"value"`
+}
+
+type Schema struct {
+	Ref        string             `json:"$ref"`
+	Type       SchemaType         `json:"type"`
+	Format     string             `json:"format"`
+	Properties map[string]*Schema `json:"properties"`
+	Items      *Schema            `json:"items"`
+	AllOf      []*Schema          `json:"allOf"`
+	OneOf      []*Schema          `json:"oneOf"`
+	AnyOf      []*Schema          `json:"anyOf"`
+	Example    any                `json:"example"`
+	Examples   []any              `json:"examples"`
+	Default    any                `json:"default"`
+	Enum       []any              `json:"enum"`
+}
+
+// SchemaType is a type like "string", OpenAPI 3.1 allows a list like ["string", "null"] as well
+type SchemaType string
+
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var types []string
+	if err := json.Unmarshal(data, &types); err == nil {
+		for _, schemaType := range types {
+			if schemaType != "null" {
+				*t = SchemaType(schemaType)
+				return nil
+			}
+		}
+		return nil
+	}
+	var schemaType string
+	if err := json.Unmarshal(data, &schemaType); err != nil {
+		return fmt.Errorf("invalid schema type: %w", err)
+	}
+	*t = SchemaType(schemaType)
+	return nil
+}
+
+type SecurityScheme struct {
+	// "http", "apiKey", "oauth2" or "openIdConnect", and "basic" in Swagger 2
+	Type string `json:"type"`
+	// "basic" or "bearer" for "http"
+	Scheme string `json:"scheme"`
+	// "header", "query" or "cookie" for "apiKey"
+	In   string `json:"in"`
+	Name string `json:"name"`
+}
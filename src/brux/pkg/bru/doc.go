@@ -0,0 +1,32 @@
+// Package bru parses, creates and writes Bru files, the files of Bruno collections, and runs them.
+//
+// Parse and ParseFile read a Bru file into a File, whose fields are the sections of the file: Meta, Request,
+// Headers, Bodies, Auths, Vars, Assertions, Scripts and so on. NewRequest creates a File for a new request, and
+// File.Bytes and File.WriteFile write it in the Bru format.
+//
+//	file, err := bru.ParseFile("collection/users/get-user.bru")
+//	if err != nil {
+//		return err
+//	}
+//	file.Headers = append(file.Headers, bru.KeyValue{Key: "Accept", Value: "application/json"})
+//	return file.WriteFile("collection/users/get-user.bru")
+//
+// Run runs a Bru file, or the Bru files of a folder or collection, configured via functional options like
+// WithEnv, WithVariables and WithHandler.
+//
+//	results, err := bru.Run(ctx, "collection", bru.WithEnv("staging"))
+//
+// The runner logs via the global zerolog logger, e.g. zerolog.SetGlobalLevel(zerolog.WarnLevel) silences the debug
+// logs.
+//
+// # Compatibility
+//
+// This package follows semantic versioning from the v1 release of the module onwards: within a major version,
+// exported identifiers are neither removed nor changed in incompatible ways, and files that parse keep parsing
+// to the same values. Minor versions may add functions, options, struct fields and constants, so use keyed struct
+// literals, e.g. bru.KeyValue{Key: "a", Value: "b"}, and don't rely on the exact text of error messages; compare
+// errors with errors.Is against the exported sentinel errors instead.
+//
+// Only this package and bruxtest are covered. Everything under internal/, as well as the output and flags of the
+// brux CLI, may change in any release.
+package bru
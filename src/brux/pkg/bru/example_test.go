@@ -0,0 +1,102 @@
+package bru_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ashishb/brux/src/brux/pkg/bru"
+)
+
+func ExampleParse() {
+	file, err := bru.Parse(strings.NewReader(`meta {
+  name: Get user
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/users/1
+  body: none
+  auth: none
+}
+
+headers {
+  Accept: application/json
+}
+`))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(file.Meta.Name)
+	fmt.Println(file.Request.Method, file.Request.URL)
+	fmt.Println(file.Headers.Get("accept"))
+	// Output:
+	// Get user
+	// GET {{baseUrl}}/users/1
+	// application/json
+}
+
+func ExampleNewRequest() {
+	file := bru.NewRequest("Create user", "POST", "{{baseUrl}}/users")
+	file.Request.BodyMode = "json"
+	file.Bodies = []bru.Body{{Type: "json", Text: `{"name": "Ada"}`}}
+	file.Assertions = bru.Assertions{{Key: "res.status", Value: "eq 201"}}
+	fmt.Print(string(file.Bytes()))
+	// Output:
+	// meta {
+	//   name: Create user
+	//   type: http
+	//   seq: 1
+	// }
+	//
+	// post {
+	//   url: {{baseUrl}}/users
+	//   body: json
+	//   auth: none
+	// }
+	//
+	// body:json {
+	//   {"name": "Ada"}
+	// }
+	//
+	// assert {
+	//   res.status: eq 201
+	// }
+}
+
+func ExampleRun() {
+	dir, err := os.MkdirTemp("", "collection")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := bru.NewRequest("Health", "GET", "{{baseUrl}}/health")
+	file.Assertions = bru.Assertions{{Key: "res.body", Value: "eq ok"}}
+	if err := file.WriteFile(filepath.Join(dir, "health.bru")); err != nil {
+		panic(err)
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	results, err := bru.Run(context.Background(), dir,
+		bru.WithHandler(handler),
+		bru.WithVariables(map[string]string{"baseUrl": "http://api.test"}))
+	if err != nil {
+		panic(err)
+	}
+	for _, result := range results {
+		fmt.Println(filepath.Base(result.FilePath), result.StatusCode, result.Passed())
+		for _, assertion := range result.Assertions {
+			fmt.Println(assertion)
+		}
+	}
+	// Output:
+	// health.bru 200 true
+	// res.body: eq ok
+}
@@ -0,0 +1,187 @@
+package bru
+
+import (
+	"os"
+	"strings"
+)
+
+// File is a Bru file: a request, a folder or collection file, or an environment.
+// Sections that are empty are omitted when the file is written.
+type File struct {
+	// "meta" section, nil if there is none, e.g. for environments
+	Meta *Meta
+	// Request section like "get", nil for folder, collection and environment files
+	Request *Request
+	Headers Headers
+	// "auth:*" sections, e.g. the "auth:basic" one. Only the one of Request.AuthMode is used.
+	Auths []Auth
+	// "body:*" sections, e.g. the "body:json" one. Bruno keeps the ones of the other modes, only the one of
+	// Request.BodyMode is sent.
+	Bodies     []Body
+	Vars       Vars
+	Assertions Assertions
+	Scripts    Scripts
+	// Contents of the "docs" section, Markdown
+	Docs string
+	// "settings" section, e.g. "timeout" or "followRedirects"
+	Settings []KeyValue
+	// Sections not covered by the fields above, e.g. "mock", kept as is
+	Other []Section
+}
+
+// Meta is the "meta" section
+type Meta struct {
+	Name string
+	// Only "http" is supported, empty for folder and collection files
+	Type string
+	// Position of the request in its folder, starting at 1
+	Seq int
+}
+
+// Request is the request section, e.g. "get", along with the "params:query" and "params:path" sections
+type Request struct {
+	// Upper case, e.g. "GET"
+	Method string
+	// Can contain variables like "{{baseUrl}}" and path params like ":id"
+	URL string
+	// Mode of the body, e.g. "json" for the "body:json" section, or "none"
+	BodyMode string
+	// Mode of the auth, e.g. "bearer" for the "auth:bearer" section, "inherit" or "none"
+	AuthMode    string
+	QueryParams []KeyValue
+	// Replace the segments like ":id" of the URL path
+	PathParams []KeyValue
+}
+
+// Headers is the "headers" section
+type Headers []KeyValue
+
+// Get returns the value of the last enabled header with the name, ignoring the case, empty if there is none
+func (h Headers) Get(name string) string {
+	value := ""
+	for _, header := range h {
+		if !header.Disabled && strings.EqualFold(header.Key, name) {
+			value = header.Value
+		}
+	}
+	return value
+}
+
+// Body is a "body:*" section
+type Body struct {
+	// e.g. "json", "text", "xml", "graphql", "graphql:vars", "form-urlencoded" or "multipart-form"
+	Type string
+	// Contents of the text bodies like "json"
+	Text string
+	// Fields of the forms, "form-urlencoded" and "multipart-form"
+	Fields []KeyValue
+}
+
+// Auth is an "auth:*" section
+type Auth struct {
+	// e.g. "basic", "bearer", "digest", "apikey" or "oauth2"
+	Mode string
+	// e.g. "username" and "password" for "basic"
+	Values []KeyValue
+}
+
+// Value returns the value of the last enabled entry with the key, empty if there is none
+func (a Auth) Value(key string) string {
+	return value(a.Values, key)
+}
+
+// Vars are the "vars" sections
+type Vars struct {
+	// "vars" section, the variables of environments, folders and collections
+	Values []KeyValue
+	// "vars:secret" section, the names of the secret variables of an environment. The values are empty.
+	Secret []KeyValue
+	// "vars:pre-request" section
+	PreRequest []KeyValue
+	// "vars:post-response" section, expressions like "res.body.token" evaluated after the response is received
+	PostResponse []KeyValue
+}
+
+// Assertions is the "assert" section, e.g. key "res.status" and value "eq 200"
+type Assertions []KeyValue
+
+// Scripts are the JavaScript sections. brux doesn't run them, they are kept for Bruno.
+type Scripts struct {
+	// "script:pre-request" section
+	PreRequest string
+	// "script:post-response" section
+	PostResponse string
+	// "tests" section
+	Tests string
+}
+
+// KeyValue is an entry of a section like "headers"
+type KeyValue struct {
+	Key   string
+	Value string
+	// Entries prefixed with "~" in the Bru file are disabled
+	Disabled bool
+}
+
+// Section is a section of a Bru file, either a dictionary of entries, a list of names like "vars:secret" whose
+// values are empty, or a block of text like "mock:body"
+type Section struct {
+	Name    string
+	Entries []KeyValue
+	Text    string
+}
+
+// NewRequest creates a File for a request without a body or auth, e.g. NewRequest("Get user", "GET",
+// "{{baseUrl}}/users/:id")
+func NewRequest(name string, method string, url string) *File {
+	return &File{
+		Meta: &Meta{Name: name, Type: "http", Seq: 1},
+		Request: &Request{
+			Method:   strings.ToUpper(method),
+			URL:      url,
+			BodyMode: "none",
+			AuthMode: "none",
+		},
+	}
+}
+
+// Body returns the body of Request.BodyMode, false if there is none
+func (f File) Body() (Body, bool) {
+	if f.Request == nil {
+		return Body{}, false
+	}
+	for _, body := range f.Bodies {
+		if body.Type == f.Request.BodyMode {
+			return body, true
+		}
+	}
+	return Body{}, false
+}
+
+// Auth returns the auth of Request.AuthMode, false if there is none
+func (f File) Auth() (Auth, bool) {
+	if f.Request == nil {
+		return Auth{}, false
+	}
+	for _, auth := range f.Auths {
+		if auth.Mode == f.Request.AuthMode {
+			return auth, true
+		}
+	}
+	return Auth{}, false
+}
+
+// WriteFile writes the file in the Bru format
+func (f File) WriteFile(filePath string) error {
+	return os.WriteFile(filePath, f.Bytes(), 0o600)
+}
+
+func value(entries []KeyValue, key string) string {
+	value := ""
+	for _, entry := range entries {
+		if !entry.Disabled && entry.Key == key {
+			value = entry.Value
+		}
+	}
+	return value
+}
@@ -0,0 +1,192 @@
+package bru
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/ashishb/brux/src/brux/internal/bruparser"
+)
+
+// ErrInvalidFile is returned if a Bru file could not be parsed
+var ErrInvalidFile = errors.New("invalid Bru file")
+
+// Parse parses a Bru file. Sections unknown to File are kept in File.Other.
+func Parse(reader io.Reader) (*File, error) {
+	document, err := bruparser.ParseDocument(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	file := &File{}
+	for _, section := range document.Sections {
+		entries := fromKeyValues(section.Entries)
+		switch {
+		case section.Name == "meta":
+			file.Meta, err = parseMeta(section)
+			if err != nil {
+				return nil, err
+			}
+		case slices.Contains(bruparser.HTTPMethods, section.Name):
+			request := file.request()
+			request.Method = strings.ToUpper(section.Name)
+			request.URL = section.Value("url")
+			request.BodyMode = section.Value("body")
+			request.AuthMode = section.Value("auth")
+		case section.Name == "params:query":
+			file.request().QueryParams = entries
+		case section.Name == "params:path":
+			file.request().PathParams = entries
+		case section.Name == "headers":
+			file.Headers = entries
+		case strings.HasPrefix(section.Name, "auth:"):
+			file.Auths = append(file.Auths, Auth{Mode: strings.TrimPrefix(section.Name, "auth:"), Values: entries})
+		case strings.HasPrefix(section.Name, "body:"):
+			file.Bodies = append(file.Bodies, Body{
+				Type:   strings.TrimPrefix(section.Name, "body:"),
+				Text:   section.Text,
+				Fields: entries,
+			})
+		case section.Name == "vars":
+			file.Vars.Values = entries
+		case section.Name == "vars:secret":
+			file.Vars.Secret = entries
+		case section.Name == "vars:pre-request":
+			file.Vars.PreRequest = entries
+		case section.Name == "vars:post-response":
+			file.Vars.PostResponse = entries
+		case section.Name == "assert":
+			file.Assertions = entries
+		case section.Name == "script:pre-request":
+			file.Scripts.PreRequest = section.Text
+		case section.Name == "script:post-response":
+			file.Scripts.PostResponse = section.Text
+		case section.Name == "tests":
+			file.Scripts.Tests = section.Text
+		case section.Name == "docs":
+			file.Docs = section.Text
+		case section.Name == "settings":
+			file.Settings = entries
+		default:
+			file.Other = append(file.Other, Section{Name: section.Name, Entries: entries, Text: section.Text})
+		}
+	}
+	return file, nil
+}
+
+// ParseFile parses the Bru file at the path
+func ParseFile(filePath string) (*File, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	file, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return file, nil
+}
+
+// Bytes returns the file in the Bru format, with the sections in Bruno's order
+func (f File) Bytes() []byte {
+	document := &bruparser.Document{}
+	if f.Meta != nil {
+		section := bruparser.Section{Name: "meta"}
+		section.SetValue("name", f.Meta.Name)
+		if f.Meta.Type != "" {
+			section.SetValue("type", f.Meta.Type)
+		}
+		if f.Meta.Seq != 0 {
+			section.SetValue("seq", strconv.Itoa(f.Meta.Seq))
+		}
+		document.SetSection(section)
+	}
+	if f.Request != nil {
+		section := bruparser.Section{Name: strings.ToLower(f.Request.Method)}
+		section.SetValue("url", f.Request.URL)
+		section.SetValue("body", lo.CoalesceOrEmpty(f.Request.BodyMode, "none"))
+		section.SetValue("auth", lo.CoalesceOrEmpty(f.Request.AuthMode, "none"))
+		document.SetSection(section)
+		setEntries(document, "params:query", f.Request.QueryParams)
+		setEntries(document, "params:path", f.Request.PathParams)
+	}
+	setEntries(document, "headers", f.Headers)
+	for _, auth := range f.Auths {
+		setEntries(document, "auth:"+auth.Mode, auth.Values)
+	}
+	for _, body := range f.Bodies {
+		setSection(document, Section{Name: "body:" + body.Type, Entries: body.Fields, Text: body.Text})
+	}
+	setEntries(document, "vars", f.Vars.Values)
+	setEntries(document, "vars:secret", f.Vars.Secret)
+	setEntries(document, "vars:pre-request", f.Vars.PreRequest)
+	setEntries(document, "vars:post-response", f.Vars.PostResponse)
+	setEntries(document, "assert", f.Assertions)
+	setSection(document, Section{Name: "script:pre-request", Text: f.Scripts.PreRequest})
+	setSection(document, Section{Name: "script:post-response", Text: f.Scripts.PostResponse})
+	setSection(document, Section{Name: "tests", Text: f.Scripts.Tests})
+	setSection(document, Section{Name: "docs", Text: f.Docs})
+	setEntries(document, "settings", f.Settings)
+	for _, section := range f.Other {
+		setSection(document, section)
+	}
+	return document.Bytes()
+}
+
+// request returns the request of the file, creating it for the params sections that precede the request section
+func (f *File) request() *Request {
+	if f.Request == nil {
+		f.Request = &Request{}
+	}
+	return f.Request
+}
+
+func parseMeta(section bruparser.Section) (*Meta, error) {
+	meta := &Meta{Name: section.Value("name"), Type: section.Value("type")}
+	if seq := section.Value("seq"); seq != "" {
+		var err error
+		meta.Seq, err = strconv.Atoi(seq)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid seq '%s'", ErrInvalidFile, seq)
+		}
+	}
+	return meta, nil
+}
+
+func setEntries(document *bruparser.Document, name string, entries []KeyValue) {
+	setSection(document, Section{Name: name, Entries: entries})
+}
+
+// setSection adds the section to the document unless it is empty
+func setSection(document *bruparser.Document, section Section) {
+	if len(section.Entries) == 0 && section.Text == "" {
+		return
+	}
+	document.SetSection(bruparser.Section{Name: section.Name, Entries: toKeyValues(section.Entries), Text: section.Text})
+}
+
+func fromKeyValues(entries []bruparser.KeyValue) []KeyValue {
+	if len(entries) == 0 {
+		return nil
+	}
+	keyValues := make([]KeyValue, 0, len(entries))
+	for _, entry := range entries {
+		keyValues = append(keyValues, KeyValue{Key: entry.Key, Value: entry.Value, Disabled: !entry.Enabled})
+	}
+	return keyValues
+}
+
+func toKeyValues(keyValues []KeyValue) []bruparser.KeyValue {
+	entries := make([]bruparser.KeyValue, 0, len(keyValues))
+	for _, kv := range keyValues {
+		entries = append(entries, bruparser.KeyValue{Key: kv.Key, Value: kv.Value, Enabled: !kv.Disabled})
+	}
+	return entries
+}
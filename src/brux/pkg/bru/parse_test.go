@@ -0,0 +1,188 @@
+package bru
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const _testRequest = `meta {
+  name: Create user
+  type: http
+  seq: 2
+}
+
+post {
+  url: {{baseUrl}}/users/:team
+  body: json
+  auth: bearer
+}
+
+params:query {
+  notify: true
+  ~dryRun: true
+}
+
+params:path {
+  team: admins
+}
+
+headers {
+  Content-Type: application/json
+  ~X-Debug: 1
+}
+
+auth:bearer {
+  token: {{token}}
+}
+
+body:json {
+  {
+    "name": "Ada"
+  }
+}
+
+body:text {
+  Ada
+}
+
+vars:pre-request {
+  name: Ada
+}
+
+vars:post-response {
+  userId: res.body.id
+}
+
+assert {
+  res.status: eq 201
+}
+
+script:pre-request {
+  req.setHeader("X-Request-Id", "1");
+}
+
+tests {
+  test("created", function() {});
+}
+
+docs {
+  Creates a user.
+}
+
+settings {
+  timeout: 5000
+}
+
+mock {
+  status: 201
+}
+`
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	file, err := Parse(strings.NewReader(_testRequest))
+	require.NoError(t, err)
+
+	require.Equal(t, &Meta{Name: "Create user", Type: "http", Seq: 2}, file.Meta)
+	require.Equal(t, &Request{
+		Method:   "POST",
+		URL:      "{{baseUrl}}/users/:team",
+		BodyMode: "json",
+		AuthMode: "bearer",
+		QueryParams: []KeyValue{
+			{Key: "notify", Value: "true"},
+			{Key: "dryRun", Value: "true", Disabled: true},
+		},
+		PathParams: []KeyValue{{Key: "team", Value: "admins"}},
+	}, file.Request)
+	require.Equal(t, "application/json", file.Headers.Get("content-type"))
+	require.Empty(t, file.Headers.Get("X-Debug"))
+
+	body, ok := file.Body()
+	require.True(t, ok)
+	require.Equal(t, Body{Type: "json", Text: "{\n  \"name\": \"Ada\"\n}"}, body)
+	require.Len(t, file.Bodies, 2)
+	auth, ok := file.Auth()
+	require.True(t, ok)
+	require.Equal(t, "{{token}}", auth.Value("token"))
+
+	require.Equal(t, Vars{
+		PreRequest:   []KeyValue{{Key: "name", Value: "Ada"}},
+		PostResponse: []KeyValue{{Key: "userId", Value: "res.body.id"}},
+	}, file.Vars)
+	require.Equal(t, Assertions{{Key: "res.status", Value: "eq 201"}}, file.Assertions)
+	require.Equal(t, Scripts{
+		PreRequest: `req.setHeader("X-Request-Id", "1");`,
+		Tests:      `test("created", function() {});`,
+	}, file.Scripts)
+	require.Equal(t, "Creates a user.", file.Docs)
+	require.Equal(t, []KeyValue{{Key: "timeout", Value: "5000"}}, file.Settings)
+	require.Equal(t, []Section{{Name: "mock", Entries: []KeyValue{{Key: "status", Value: "201"}}}}, file.Other)
+
+	require.Equal(t, _testRequest, string(file.Bytes()))
+}
+
+func TestParse_Environment(t *testing.T) {
+	t.Parallel()
+	environment := `vars {
+  baseUrl: https://example.com
+}
+
+vars:secret [
+  token,
+  ~password
+]
+`
+	file, err := Parse(strings.NewReader(environment))
+	require.NoError(t, err)
+	require.Nil(t, file.Meta)
+	require.Nil(t, file.Request)
+	require.Equal(t, []KeyValue{{Key: "baseUrl", Value: "https://example.com"}}, file.Vars.Values)
+	require.Equal(t, []KeyValue{{Key: "token"}, {Key: "password", Disabled: true}}, file.Vars.Secret)
+	require.Equal(t, environment, string(file.Bytes()))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+	for name, content := range map[string]string{
+		"unterminated": "get {\n  url: http://example.com\n",
+		"invalid seq":  "meta {\n  name: a\n  seq: first\n}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse(strings.NewReader(content))
+			require.ErrorIs(t, err, ErrInvalidFile)
+		})
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	t.Parallel()
+	file := NewRequest("Get user", "get", "{{baseUrl}}/users/1")
+	file.Assertions = Assertions{{Key: "res.status", Value: "eq 200"}}
+	filePath := filepath.Join(t.TempDir(), "get-user.bru")
+	require.NoError(t, file.WriteFile(filePath))
+
+	parsedFile, err := ParseFile(filePath)
+	require.NoError(t, err)
+	require.Equal(t, file, parsedFile)
+	require.Equal(t, `meta {
+  name: Get user
+  type: http
+  seq: 1
+}
+
+get {
+  url: {{baseUrl}}/users/1
+  body: none
+  auth: none
+}
+
+assert {
+  res.status: eq 200
+}
+`, string(parsedFile.Bytes()))
+}
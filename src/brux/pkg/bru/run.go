@@ -0,0 +1,186 @@
+package bru
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ashishb/brux/src/brux/internal/bruexpr"
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+var (
+	// ErrParse is the error of a Result if the Bru file, its environment or its variables could not be parsed, or if
+	// its post-response variables could not be evaluated
+	ErrParse = brurunner.ErrParse
+	// ErrTransport is the error of a Result if the request could not be sent or the response could not be read
+	ErrTransport = brurunner.ErrTransport
+	// ErrUnexpectedStatus is the error of a Result for non-2xx responses if enabled via WithFailOnErrorStatus
+	ErrUnexpectedStatus = brurunner.ErrUnexpectedStatus
+	// ErrAssertionFailed is the error of a Result if any of its assertions failed
+	ErrAssertionFailed = brurunner.ErrAssertionFailed
+)
+
+// RunOption configures Run
+type RunOption func(*_RunOptions)
+
+type _RunOptions struct {
+	envName           string
+	variables         map[string]string
+	handler           http.Handler
+	transport         http.RoundTripper
+	timeout           time.Duration
+	parallel          int
+	failOnErrorStatus bool
+}
+
+// WithEnv selects the environment, the name of a file under the "environments" directory of the collection
+func WithEnv(envName string) RunOption {
+	return func(options *_RunOptions) {
+		options.envName = envName
+	}
+}
+
+// WithVariables sets variables overriding the ones of the environment, e.g. "baseUrl"
+func WithVariables(variables map[string]string) RunOption {
+	return func(options *_RunOptions) {
+		options.variables = variables
+	}
+}
+
+// WithTransport sends the requests via the transport instead of the network, unless a handler is set via
+// WithHandler
+func WithTransport(transport http.RoundTripper) RunOption {
+	return func(options *_RunOptions) {
+		options.transport = transport
+	}
+}
+
+// WithHandler serves the requests by the handler in-process instead of sending them over the network
+func WithHandler(handler http.Handler) RunOption {
+	return func(options *_RunOptions) {
+		options.handler = handler
+	}
+}
+
+// WithTimeout sets the timeout of each request unless overridden via the "settings" section
+func WithTimeout(timeout time.Duration) RunOption {
+	return func(options *_RunOptions) {
+		options.timeout = timeout
+	}
+}
+
+// WithParallel sets the maximum number of concurrent requests when running a collection
+func WithParallel(parallel int) RunOption {
+	return func(options *_RunOptions) {
+		options.parallel = parallel
+	}
+}
+
+// WithFailOnErrorStatus fails the runs of non-2xx responses with ErrUnexpectedStatus
+func WithFailOnErrorStatus(failOnErrorStatus bool) RunOption {
+	return func(options *_RunOptions) {
+		options.failOnErrorStatus = failOnErrorStatus
+	}
+}
+
+// Result is the outcome of running a single Bru file
+type Result struct {
+	FilePath string
+	// Method and URL of the request, with the variables replaced
+	Method string
+	URL    string
+	// Zero if no response was received
+	StatusCode int
+	// nil if no response was received
+	Headers  http.Header
+	Body     []byte
+	Duration time.Duration
+	// Results of the "assert" section and the schema checks
+	Assertions []AssertionResult
+	// nil if the run succeeded, wraps one of ErrParse, ErrTransport, ErrUnexpectedStatus or ErrAssertionFailed
+	// otherwise
+	Err error
+}
+
+// AssertionResult is the outcome of an assertion, e.g. expression "res.status", operator "eq" and expected "200"
+type AssertionResult struct {
+	Expression string
+	Operator   string
+	// Expected value as written in the Bru file
+	Expected string
+	Actual   any
+	Passed   bool
+	// Set if the assertion could not be evaluated
+	Err error
+}
+
+// String returns the assertion as written in the Bru file along with the reason it failed, if it did
+func (r AssertionResult) String() string {
+	// Formatted the same way as the assertions printed by brux run
+	return bruexpr.AssertionResult(r).String()
+}
+
+// Passed returns true if the run succeeded
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Run runs the Bru file, or the Bru files of the folder or collection in the order of their "seq", and returns a
+// result per Bru file. The results report the failures of the Bru files, requests and assertions, the error is only
+// returned if the run could not be configured.
+func Run(ctx context.Context, path string, opts ...RunOption) ([]Result, error) {
+	options := &_RunOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	runnerOpts := []brurunner.Option{
+		brurunner.WithVariables(options.variables),
+		brurunner.WithTimeout(options.timeout),
+		brurunner.WithParallel(options.parallel),
+		brurunner.WithFailOnErrorStatus(options.failOnErrorStatus),
+	}
+	switch {
+	case options.handler != nil:
+		runnerOpts = append(runnerOpts, brurunner.WithHandler(options.handler))
+	case options.transport != nil:
+		runnerOpts = append(runnerOpts, brurunner.WithTransport(options.transport))
+	}
+	cfg, err := brurunner.NewConfig(path, false, "", options.envName, false, nil, runnerOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0)
+	for _, result := range brurunner.RunIterations(ctx, *cfg) {
+		results = append(results, newResult(result))
+	}
+	return results, nil
+}
+
+func newResult(result *brurunner.Result) Result {
+	r := Result{
+		FilePath: result.FilePath,
+		Method:   result.Request.Method,
+		URL:      result.Request.URL,
+		Duration: result.Timings.Total,
+		Err:      result.Err,
+	}
+	if result.Response != nil {
+		r.StatusCode = result.Response.StatusCode
+		r.Headers = result.Response.Headers
+		r.Body = result.Response.Body
+	}
+	for _, assertion := range result.Assertions {
+		r.Assertions = append(r.Assertions, AssertionResult{
+			Expression: assertion.Expression,
+			Operator:   assertion.Operator,
+			Expected:   assertion.Expected,
+			Actual:     assertion.Actual,
+			Passed:     assertion.Passed,
+			Err:        assertion.Err,
+		})
+	}
+	return r
+}
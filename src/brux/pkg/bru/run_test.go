@@ -0,0 +1,65 @@
+package bru
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	getUser := NewRequest("Get user", "GET", "{{baseUrl}}/users/1")
+	getUser.Assertions = Assertions{{Key: "res.body.name", Value: "eq Ada"}}
+	require.NoError(t, getUser.WriteFile(filepath.Join(dir, "get-user.bru")))
+	getMissingUser := NewRequest("Get missing user", "GET", "{{baseUrl}}/users/2")
+	getMissingUser.Meta.Seq = 2
+	getMissingUser.Assertions = Assertions{{Key: "res.status", Value: "eq 200"}}
+	require.NoError(t, getMissingUser.WriteFile(filepath.Join(dir, "get-missing-user.bru")))
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 1, "name": "Ada"})
+	})
+	results, err := Run(t.Context(), dir, WithHandler(handler), WithVariables(map[string]string{"baseUrl": "http://api.test"}))
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	require.True(t, results[0].Passed())
+	require.Equal(t, filepath.Join(dir, "get-user.bru"), results[0].FilePath)
+	require.Equal(t, "GET", results[0].Method)
+	require.Equal(t, "http://api.test/users/1", results[0].URL)
+	require.Equal(t, http.StatusOK, results[0].StatusCode)
+	require.JSONEq(t, `{"id": 1, "name": "Ada"}`, string(results[0].Body))
+	require.Equal(t, []string{"res.body.name: eq Ada"}, assertionStrings(results[0]))
+
+	require.ErrorIs(t, results[1].Err, ErrAssertionFailed)
+	require.Equal(t, http.StatusNotFound, results[1].StatusCode)
+	require.Equal(t, []string{"res.status: eq 200 (actual: 404)"}, assertionStrings(results[1]))
+}
+
+func TestRun_InvalidFile(t *testing.T) {
+	t.Parallel()
+	bruFilePath := filepath.Join(t.TempDir(), "get.bru")
+	require.NoError(t, os.WriteFile(bruFilePath, []byte("get {\n  url: http://localhost\n"), 0o600))
+	results, err := Run(t.Context(), bruFilePath)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.ErrorIs(t, results[0].Err, ErrParse)
+}
+
+func assertionStrings(result Result) []string {
+	assertions := make([]string, 0, len(result.Assertions))
+	for _, assertion := range result.Assertions {
+		assertions = append(assertions, assertion.String())
+	}
+	return assertions
+}
@@ -0,0 +1,113 @@
+// Package bruxtest runs Bru files in Go tests, against an http.Handler in-process or a custom transport, and
+// reports every request and assertion as a subtest.
+//
+//	func TestAPI(t *testing.T) {
+//		bruxtest.RunCollection(t, "testdata/collection", api.NewHandler(),
+//			bruxtest.WithVariables(map[string]string{"baseUrl": "http://api.test"}))
+//	}
+package bruxtest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+// Option configures RunCollection
+type Option func(*_Options)
+
+type _Options struct {
+	envName   string
+	variables map[string]string
+	transport http.RoundTripper
+}
+
+// WithEnv selects the environment, the name of a file under the "environments" directory of the collection
+func WithEnv(envName string) Option {
+	return func(options *_Options) {
+		options.envName = envName
+	}
+}
+
+// WithVariables sets variables overriding the ones of the environment, e.g. "baseUrl"
+func WithVariables(variables map[string]string) Option {
+	return func(options *_Options) {
+		options.variables = variables
+	}
+}
+
+// WithTransport sends the requests via the transport if no handler is passed to RunCollection, e.g. a recording
+// or stubbing transport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(options *_Options) {
+		options.transport = transport
+	}
+}
+
+// RunCollection runs the Bru file, or the Bru files of the folder or collection, and reports every request as a
+// subtest named after its path, e.g. "users/get-user", with a nested subtest per assertion.
+// The requests are served by the handler in-process, or sent via the transport of WithTransport or the network if
+// the handler is nil.
+func RunCollection(t *testing.T, path string, handler http.Handler, opts ...Option) {
+	t.Helper()
+	options := &_Options{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	runnerOpts := []brurunner.Option{brurunner.WithVariables(options.variables)}
+	switch {
+	case handler != nil:
+		runnerOpts = append(runnerOpts, brurunner.WithHandler(handler))
+	case options.transport != nil:
+		runnerOpts = append(runnerOpts, brurunner.WithTransport(options.transport))
+	}
+	cfg, err := brurunner.NewConfig(path, false, "", options.envName, false, nil, runnerOpts...)
+	if err != nil {
+		t.Fatalf("could not configure run of '%s': %v", path, err)
+	}
+
+	for _, result := range brurunner.RunIterations(t.Context(), *cfg) {
+		t.Run(testName(path, result), func(t *testing.T) {
+			reportResult(t, result)
+		})
+	}
+}
+
+// testName returns the path of the Bru file relative to the collection without the extension, and the iteration
+func testName(path string, result *brurunner.Result) string {
+	name := filepath.Base(result.FilePath)
+	if relativePath, err := filepath.Rel(path, result.FilePath); err == nil && !strings.HasPrefix(relativePath, "..") &&
+		relativePath != "." {
+		name = relativePath
+	}
+	name = strings.TrimSuffix(filepath.ToSlash(name), filepath.Ext(name))
+	if result.Iteration > 0 {
+		name += fmt.Sprintf(" (iteration %d)", result.Iteration)
+	}
+	return name
+}
+
+func reportResult(t *testing.T, result *brurunner.Result) {
+	t.Helper()
+	if result.Response != nil {
+		t.Logf("%s %s: %s", result.Request.Method, result.Request.URL, result.Response.Status)
+	}
+	for _, assertion := range result.Assertions {
+		name := strings.TrimSpace(assertion.Expression + " " + assertion.Operator + " " + assertion.Expected)
+		t.Run(name, func(t *testing.T) {
+			if !assertion.Passed {
+				t.Error(assertion.String())
+			}
+		})
+	}
+	// Failed assertions are reported by their subtests
+	if result.Err != nil && (!errors.Is(result.Err, brurunner.ErrAssertionFailed) || len(result.FailedAssertions()) == 0) {
+		t.Error(result.Err)
+	}
+}
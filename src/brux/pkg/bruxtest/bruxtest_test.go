@@ -0,0 +1,102 @@
+package bruxtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ashishb/brux/src/brux/internal/brurunner"
+)
+
+var _testCollectionFiles = map[string]string{
+	"bruno.json": `{"version": "1", "name": "Users API", "type": "collection"}`,
+	"users/create-user.bru": `meta {
+  name: Create user
+  type: http
+  seq: 1
+}
+
+post {
+  url: {{baseUrl}}/users
+  body: json
+}
+
+body:json {
+  {"name": "Ada"}
+}
+
+vars:post-response {
+  userId: res.body.id
+}
+
+assert {
+  res.status: eq 201
+  res.body.name: eq Ada
+}
+`,
+	"users/get-user.bru": `meta {
+  name: Get user
+  type: http
+  seq: 2
+}
+
+get {
+  url: {{baseUrl}}/users/{{userId}}
+}
+
+assert {
+  res.status: eq 200
+  res.headers.content-type: contains json
+  res.body.id: eq 42
+}
+`,
+}
+
+func newTestHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		var user map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		user["id"] = 42
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(user)
+	})
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != "42" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 42, "name": "Ada"}`))
+	})
+	return mux
+}
+
+func TestRunCollection(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	for name, content := range _testCollectionFiles {
+		filePath := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o750))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0o600))
+	}
+
+	RunCollection(t, dir, newTestHandler(), WithVariables(map[string]string{"baseUrl": "http://api.test"}))
+	RunCollection(t, filepath.Join(dir, "users", "create-user.bru"), newTestHandler(),
+		WithVariables(map[string]string{"baseUrl": "http://api.test"}))
+}
+
+func TestTestName(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "users/get-user", testName("collection", &brurunner.Result{FilePath: "collection/users/get-user.bru"}))
+	require.Equal(t, "get-user (iteration 2)", testName("collection/users/get-user.bru",
+		&brurunner.Result{FilePath: "collection/users/get-user.bru", Iteration: 2}))
+}